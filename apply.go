@@ -131,6 +131,7 @@ func applyDataFrame(ctx context.Context, df *DataFrame, fn ApplyDataFrameFn, opt
 
 		// Create a new dataframe
 		ndf = NewDataFrame(seriess...)
+		df.inheritIndex(ndf)
 	}
 
 	iterator := df.ValuesIterator(ValuesOptions{InitialRow: 0, Step: 1, DontReadLock: true})
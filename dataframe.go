@@ -13,9 +13,11 @@ import (
 // DataFrame allows you to handle numerous
 //series of data conveniently.
 type DataFrame struct {
-	lock   sync.RWMutex
-	Series []Series
-	n      int // Number of rows
+	lock     sync.RWMutex
+	Series   []Series
+	n        int      // Number of rows
+	index    []Series // Series designated as the index (see SetIndex)
+	levelSep string   // Separator of the levels of the Series names (see SetColumnLevels)
 }
 
 // NewDataFrame creates a new dataframe.
@@ -449,6 +451,13 @@ func (df *DataFrame) RemoveSeries(seriesName string, opts ...Options) error {
 		return errors.New(err.Error() + ": " + seriesName)
 	}
 
+	for i, s := range df.index {
+		if s == df.Series[idx] {
+			df.index = append(df.index[:i:i], df.index[i+1:]...)
+			break
+		}
+	}
+
 	df.Series = append(df.Series[:idx], df.Series[idx+1:]...)
 	return nil
 }
@@ -532,6 +541,8 @@ func (df *DataFrame) Copy(r ...Range) *DataFrame {
 		newDF.n = seriess[0].NRows(dontLock)
 	}
 
+	df.inheritIndex(newDF)
+
 	return newDF
 }
 
@@ -548,7 +559,11 @@ var errNotEqual = errors.New("not equal")
 
 // IsEqual returns true if df2's values are equal to df.
 func (df *DataFrame) IsEqual(ctx context.Context, df2 *DataFrame, opts ...IsEqualOptions) (bool, error) {
-	if len(opts) == 0 || !opts[0].DontLock {
+	if len(opts) == 0 {
+		opts = append(opts, IsEqualOptions{})
+	}
+
+	if !opts[0].DontLock {
 		df.lock.RLock()
 		defer df.lock.RUnlock()
 	}
@@ -558,13 +573,32 @@ func (df *DataFrame) IsEqual(ctx context.Context, df2 *DataFrame, opts ...IsEqua
 		return false, nil
 	}
 
+	// Match the Series of df2 with the Series of df
+	seriess2 := df2.Series
+	if opts[0].IgnoreSeriesOrder {
+		seriess2 = make([]Series, len(df.Series))
+		for i, s := range df.Series {
+			col, err := df2.NameToColumn(s.Name(dontLock), dontLock)
+			if err != nil {
+				return false, nil
+			}
+			seriess2[i] = df2.Series[col]
+		}
+	}
+
+	// Rows must be compared across all Series
+	if opts[0].IgnoreRowOrder && len(df.Series) > 1 {
+		return isEqualRows(ctx, df.Series, seriess2, opts[0])
+	}
+
 	// Check values
 	g, newCtx := errgroup.WithContext(ctx)
 
 	for i := range df.Series {
+		i := i
 		g.Go(func() error {
 
-			eq, err := df.Series[i].IsEqual(newCtx, df2.Series[i], opts...)
+			eq, err := df.Series[i].IsEqual(newCtx, seriess2[i], opts...)
 			if err != nil {
 				return err
 			}
@@ -52,17 +52,21 @@ func (df *DataFrame) Table(opts ...TableOptions) string {
 
 	data := [][]string{}
 
-	headers := []string{""} // row header is blank
-	footers := []string{fmt.Sprintf("%dx%d", df.n, len(df.Series))}
+	headers, footers, labels, isIndex := df.rowLabels()
+	names := []string{}
 	for idx, aSeries := range df.Series {
+		if isIndex[idx] {
+			continue
+		}
+
 		if len(columns) == 0 {
-			headers = append(headers, aSeries.Name())
+			names = append(names, aSeries.Name())
 			footers = append(footers, aSeries.Type())
 		} else {
 			// Check idx
 			_, exists := columns[idx]
 			if exists {
-				headers = append(headers, aSeries.Name())
+				names = append(names, aSeries.Name())
 				footers = append(footers, aSeries.Type())
 				continue
 			}
@@ -70,12 +74,13 @@ func (df *DataFrame) Table(opts ...TableOptions) string {
 			// Check series name
 			_, exists = columns[aSeries.Name()]
 			if exists {
-				headers = append(headers, aSeries.Name())
+				names = append(names, aSeries.Name())
 				footers = append(footers, aSeries.Type())
 				continue
 			}
 		}
 	}
+	headers = append(headers, df.levelHeaders(names)...)
 
 	if df.n > 0 {
 		s, e, err := opts[0].R.Limits(df.n)
@@ -85,9 +90,13 @@ func (df *DataFrame) Table(opts ...TableOptions) string {
 
 		for row := s; row <= e; row++ {
 
-			sVals := []string{fmt.Sprintf("%d:", row)}
+			sVals := labels(row)
 
 			for idx, aSeries := range df.Series {
+				if isIndex[idx] {
+					continue
+				}
+
 				if len(columns) == 0 {
 					sVals = append(sVals, aSeries.ValueString(row))
 				} else {
@@ -126,6 +135,42 @@ func (df *DataFrame) Table(opts ...TableOptions) string {
 	return buf.String()
 }
 
+// rowLabels returns the headers and footers of the columns that label the rows, and a function that
+// returns the labels of a row. The rows are labelled by the index Series, or by the row numbers if
+// the DataFrame has no index. isIndex reports which Series are part of the index.
+func (df *DataFrame) rowLabels() (headers, footers []string, labels func(row int) []string, isIndex map[int]bool) {
+
+	footers = []string{fmt.Sprintf("%dx%d", df.n, len(df.Series))}
+	isIndex = map[int]bool{}
+
+	cols := df.indexColumns()
+	if len(cols) == 0 {
+		headers = []string{""} // row header is blank
+		labels = func(row int) []string {
+			return []string{fmt.Sprintf("%d:", row)}
+		}
+		return
+	}
+
+	for i, col := range cols {
+		isIndex[col] = true
+		headers = append(headers, df.Series[col].Name(dontLock))
+		if i > 0 {
+			footers = append(footers, " ")
+		}
+	}
+
+	labels = func(row int) []string {
+		vals := []string{}
+		for _, col := range cols {
+			vals = append(vals, df.Series[col].ValueString(row, dontLock))
+		}
+		return vals
+	}
+
+	return
+}
+
 // String implements the fmt.Stringer interface. It does not lock the DataFrame.
 func (df *DataFrame) String() string {
 
@@ -137,28 +182,35 @@ func (df *DataFrame) String() string {
 
 	data := [][]string{}
 
-	headers := []string{""} // row header is blank
-	footers := []string{fmt.Sprintf("%dx%d", df.n, len(df.Series))}
-	for _, aSeries := range df.Series {
-		headers = append(headers, aSeries.Name())
+	headers, footers, labels, isIndex := df.rowLabels()
+	names := []string{}
+	for idx, aSeries := range df.Series {
+		if isIndex[idx] {
+			continue
+		}
+		names = append(names, aSeries.Name())
 		footers = append(footers, aSeries.Type())
 	}
+	headers = append(headers, df.levelHeaders(names)...)
 
 	for j, row := range idx {
 
 		if j == 3 {
-			sVals := []string{"⋮"}
+			sVals := []string{}
 
-			for range df.Series {
+			for range headers {
 				sVals = append(sVals, "⋮")
 			}
 
 			data = append(data, sVals)
 		}
 
-		sVals := []string{fmt.Sprintf("%d:", row)}
+		sVals := labels(row)
 
-		for _, aSeries := range df.Series {
+		for idx, aSeries := range df.Series {
+			if isIndex[idx] {
+				continue
+			}
 			sVals = append(sVals, aSeries.ValueString(row))
 		}
 
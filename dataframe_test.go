@@ -4,6 +4,10 @@ package dataframe
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -224,3 +228,808 @@ func TestDfIsEqual(t *testing.T) {
 		t.Errorf("Df1: [%T] %s is not equal to Df2: [%T] %s\n", df1, df1.String(), df2, df2.String())
 	}
 }
+
+func TestDfIsEqualOptions(t *testing.T) {
+	ctx := context.Background()
+
+	df1 := NewDataFrame(
+		NewSeriesInt64("day", nil, 1, 2, 3),
+		NewSeriesFloat64("sales", nil, 50.3, 23.4, nil),
+	)
+
+	tests := []struct {
+		df2      *DataFrame
+		opts     IsEqualOptions
+		expected bool
+	}{
+		{
+			NewDataFrame(NewSeriesFloat64("sales", nil, 50.3, 23.4, nil), NewSeriesInt64("day", nil, 1, 2, 3)),
+			IsEqualOptions{CheckName: true},
+			false,
+		},
+		{
+			NewDataFrame(NewSeriesFloat64("sales", nil, 50.3, 23.4, nil), NewSeriesInt64("day", nil, 1, 2, 3)),
+			IsEqualOptions{CheckName: true, IgnoreSeriesOrder: true},
+			true,
+		},
+		{
+			NewDataFrame(NewSeriesFloat64("day", nil, 1.0, 2.0, 3.0), NewSeriesFloat64("sales", nil, 50.3000001, 23.4, nil)),
+			IsEqualOptions{IgnoreSeriesType: true, Tolerance: 1e-6},
+			true,
+		},
+		{
+			NewDataFrame(NewSeriesInt64("day", nil, 3, 1, 2), NewSeriesFloat64("sales", nil, nil, 50.3, 23.4)),
+			IsEqualOptions{IgnoreRowOrder: true},
+			true,
+		},
+		{
+			// Each Series matches independently, but the rows don't
+			NewDataFrame(NewSeriesInt64("day", nil, 3, 2, 1), NewSeriesFloat64("sales", nil, nil, 50.3, 23.4)),
+			IsEqualOptions{IgnoreRowOrder: true},
+			false,
+		},
+		{
+			NewDataFrame(NewSeriesInt64("day", nil, 1, 2, 3), NewSeriesFloat64("amount", nil, 50.3, 23.4, nil)),
+			IsEqualOptions{IgnoreSeriesOrder: true},
+			false,
+		},
+	}
+
+	for i, tc := range tests {
+		eq, err := df1.IsEqual(ctx, tc.df2, tc.opts)
+		if err != nil {
+			t.Errorf("%d: error encountered: %s\n", i, err)
+		}
+
+		if eq != tc.expected {
+			t.Errorf("%d: expected: %v actual: %v\n", i, tc.expected, eq)
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	ctx := context.Background()
+
+	df := NewDataFrame(
+		NewSeriesString("region", nil, "AU", "NZ", "AU", "NZ"),
+		NewSeriesInt64("day", nil, 1, 1, 2, 2),
+		NewSeriesFloat64("sales", nil, 50.3, 23.4, nil, 56.2),
+	)
+
+	if _, err := df.Loc("AU"); err != ErrNoIndex {
+		t.Errorf("expected: %v actual: %v", ErrNoIndex, err)
+	}
+
+	if err := df.SetIndex([]string{"unknown"}); err == nil {
+		t.Errorf("expected error for unknown series")
+	}
+
+	if err := df.SetIndex([]string{"region", "day"}); err != nil {
+		t.Fatalf("error encountered: %s\n", err)
+	}
+
+	rows, err := df.Loc([]interface{}{"NZ", 2})
+	if err != nil {
+		t.Fatalf("error encountered: %s\n", err)
+	}
+	if !cmp.Equal(rows, []int{3}) {
+		t.Errorf("wrong rows: %v", rows)
+	}
+
+	if _, err := df.Loc([]interface{}{"NZ", 2, 50.3}); err == nil {
+		t.Errorf("expected error for wrong key length")
+	}
+
+	// The index survives Sort, Filter and Copy
+	df.Sort(ctx, []SortKey{{Key: "sales", Desc: true}})
+
+	rows, _ = df.Loc([]interface{}{"NZ", 2})
+	if !cmp.Equal(rows, []int{0}) {
+		t.Errorf("wrong rows after sort: %v", rows)
+	}
+
+	fdf, err := Filter(ctx, df, FilterDataFrameFn(func(vals map[interface{}]interface{}, row, nRows int) (FilterAction, error) {
+		if vals["sales"] == nil {
+			return DROP, nil
+		}
+		return KEEP, nil
+	}))
+	if err != nil {
+		t.Fatalf("error encountered: %s\n", err)
+	}
+
+	for _, d := range []*DataFrame{fdf.(*DataFrame), df.Copy()} {
+		if !cmp.Equal(d.Index(), []string{"region", "day"}) {
+			t.Errorf("index not preserved: %v", d.Index())
+		}
+	}
+
+	expected := `+--------+-----+---------+
+| REGION | DAY |  SALES  |
++--------+-----+---------+
+|   NZ   |  2  |  56.2   |
+|   AU   |  1  |  50.3   |
+|   NZ   |  1  |  23.4   |
++--------+-----+---------+
+|  3X3   |     | FLOAT64 |
++--------+-----+---------+
+`
+	if table := fdf.(*DataFrame).Table(); table != expected {
+		t.Errorf("wrong table:\n%s", table)
+	}
+
+	// Row number index
+	if err := df.AddRowNumberIndex("sales"); err == nil {
+		t.Errorf("expected error for existing series")
+	}
+
+	if err := df.AddRowNumberIndex("row"); err != nil {
+		t.Fatalf("error encountered: %s\n", err)
+	}
+	df.Sort(ctx, []SortKey{{Key: "sales"}})
+
+	rows, _ = df.Loc(0)
+	if !cmp.Equal(rows, []int{3}) {
+		t.Errorf("wrong rows: %v", rows)
+	}
+
+	// Diff aligns the rows using the index
+	df2 := df.Copy()
+	df2.Sort(ctx, []SortKey{{Key: "row"}})
+
+	report, err := Diff(ctx, df, df2)
+	if err != nil || !report.IsEqual() {
+		t.Errorf("expected no differences: %v", err)
+	}
+
+	// Numeric keys are converted to the data type of the index Series
+	fidx := NewDataFrame(NewSeriesFloat64("x", nil, 1.5, 2, nil), NewSeriesInt64("y", nil, 1, 2, 3))
+	fidx.SetIndex([]string{"x"})
+	if rows, _ := fidx.Loc(2); !cmp.Equal(rows, []int{1}) {
+		t.Errorf("wrong rows for float64 index: %v", rows)
+	}
+	fidx.SetIndex([]string{"y"})
+	if rows, _ := fidx.Loc(2.0); !cmp.Equal(rows, []int{1}) {
+		t.Errorf("wrong rows for int64 index: %v", rows)
+	}
+	if rows, _ := fidx.Loc(2.5); len(rows) != 0 {
+		t.Errorf("wrong rows for int64 index: %v", rows)
+	}
+
+	// Diff aligns the rows by position when the index is not unique
+	da := NewDataFrame(NewSeriesString("k", nil, "a", "a"), NewSeriesInt64("v", nil, 1, 2))
+	db := NewDataFrame(NewSeriesString("k", nil, "a", "a"), NewSeriesInt64("v", nil, 1, 3))
+	da.SetIndex([]string{"k"})
+	db.SetIndex([]string{"k"})
+
+	report, err = Diff(ctx, da, db)
+	if err != nil {
+		t.Fatalf("error encountered: %s\n", err)
+	}
+	if report.IsEqual() || len(report.Rows) != 1 || report.Rows[0].RowA != 1 {
+		t.Errorf("wrong report: %v", report.Rows)
+	}
+
+	// Removing the index Series removes it from the index
+	df.RemoveSeries("row")
+	if len(df.Index()) != 0 {
+		t.Errorf("expected no index: %v", df.Index())
+	}
+}
+
+func TestMultiIndex(t *testing.T) {
+	ctx := context.Background()
+
+	df := NewDataFrame(
+		NewSeriesInt64("day", nil, 1, 1, 2, 2, 3),
+		NewSeriesString("region", nil, "AU", "NZ", "AU", "NZ", "AU"),
+		NewSeriesFloat64("sales", nil, 50.3, 23.4, nil, 56.2, 10),
+		NewSeriesInt64("cost", nil, 5, 6, 7, 8, 9),
+	)
+	df.SetIndex([]string{"day", "region"})
+
+	// Partial key
+	rows, err := df.Loc([]interface{}{2})
+	if err != nil {
+		t.Fatalf("error encountered: %s\n", err)
+	}
+	if !cmp.Equal(rows, []int{2, 3}) {
+		t.Errorf("wrong rows: %v", rows)
+	}
+
+	if _, err := df.Stack(ctx, "metric"); err == nil {
+		t.Errorf("expected error for dataframe without column levels")
+	}
+
+	udf, err := df.Unstack(ctx, "region")
+	if err != nil {
+		t.Fatalf("error encountered: %s\n", err)
+	}
+
+	expected := `+-----+---------+---------+-------+-------+
+| DAY |  SALES  |         | COST  |       |
+|     |   AU    |   NZ    |  AU   |  NZ   |
++-----+---------+---------+-------+-------+
+|  1  |  50.3   |  23.4   |   5   |   6   |
+|  2  |   NaN   |  56.2   |   7   |   8   |
+|  3  |   10    |   NaN   |   9   |  NaN  |
++-----+---------+---------+-------+-------+
+| 3X5 | FLOAT64 | FLOAT64 | INT64 | INT64 |
++-----+---------+---------+-------+-------+
+`
+	if table := udf.Table(); table != expected {
+		t.Errorf("wrong table:\n%s", table)
+	}
+
+	if levels := udf.ColumnLevels(); !cmp.Equal(levels, [][]string{{"sales", "AU"}, {"sales", "NZ"}, {"cost", "AU"}, {"cost", "NZ"}}) {
+		t.Errorf("wrong levels: %v", levels)
+	}
+
+	sales, err := udf.SelectLevels([]string{"sales"})
+	if err != nil {
+		t.Fatalf("error encountered: %s\n", err)
+	}
+	if names := sales.Names(); !cmp.Equal(names, []string{"day", "AU", "NZ"}) {
+		t.Errorf("wrong names: %v", names)
+	}
+
+	if _, err := udf.SelectLevels([]string{"profit"}); err == nil {
+		t.Errorf("expected error for unknown key")
+	}
+
+	sdf, err := udf.Stack(ctx, "region")
+	if err != nil {
+		t.Fatalf("error encountered: %s\n", err)
+	}
+
+	expectedDf := NewDataFrame(
+		NewSeriesInt64("day", nil, 1, 1, 2, 2, 3, 3),
+		NewSeriesString("region", nil, "AU", "NZ", "AU", "NZ", "AU", "NZ"),
+		NewSeriesFloat64("sales", nil, 50.3, 23.4, nil, 56.2, 10, nil),
+		NewSeriesInt64("cost", nil, 5, 6, 7, 8, 9, nil),
+	)
+
+	if eq, _ := sdf.IsEqual(ctx, expectedDf, IsEqualOptions{CheckName: true}); !eq {
+		t.Errorf("wrong stacked dataframe:\n%s", sdf.Table())
+	}
+	if !cmp.Equal(sdf.Index(), []string{"day", "region"}) {
+		t.Errorf("wrong index: %v", sdf.Index())
+	}
+
+	// Series names must not collide with the index
+	cdf := NewDataFrame(
+		NewSeriesInt64("day", nil, 1, 2),
+		NewSeriesInt64("day|x", nil, 3, 4),
+		NewSeriesInt64("m|x", nil, 5, 6),
+	)
+	cdf.SetIndex([]string{"day"})
+	cdf.SetColumnLevels("|")
+
+	for _, name := range []string{"s", "m"} {
+		if _, err := cdf.Stack(ctx, name); err == nil {
+			t.Errorf("expected error for stacking into: %s", name)
+		}
+	}
+	if _, err := cdf.SelectLevels([]string{"m"}); err != nil {
+		t.Errorf("error encountered: %s\n", err)
+	}
+
+	cdf = NewDataFrame(NewSeriesString("x", nil, "a", "b"), NewSeriesInt64("a|x", nil, 1, 2))
+	cdf.SetIndex([]string{"x"})
+	cdf.SetColumnLevels("|")
+	if _, err := cdf.SelectLevels([]string{"a"}); err == nil || err.Error() != "series already exists: x" {
+		t.Errorf("expected error for renamed series: %v", err)
+	}
+
+	// Duplicate index entries can't be unstacked
+	df.Append(nil, 3, "AU", 1.0, 1)
+	if _, err := df.Unstack(ctx, "region"); err == nil {
+		t.Errorf("expected error for duplicate index entry")
+	}
+}
+
+func TestSelect(t *testing.T) {
+
+	df := NewDataFrame(
+		NewSeriesInt64("id", nil, 1, 2, 3),
+		NewSeriesFloat64("sales_1", nil, 50.3, 23.4, nil),
+		NewSeriesFloat64("sales_2", nil, 1.0, 2.0, 3.0),
+		NewSeriesString("code", nil, "AU", "NZ", "US"),
+		NewSeriesFloat64("cost", nil, 5.0, 6.0, 7.0),
+	)
+	df.SetIndex([]string{"id"})
+
+	tests := []struct {
+		cols     []interface{}
+		expected []string
+	}{
+		{[]interface{}{"code", 0}, []string{"code", "id"}},
+		{[]interface{}{regexp.MustCompile(`^sales_\d$`)}, []string{"sales_1", "sales_2"}},
+		{[]interface{}{Glob("s*_2"), Glob("*s*")}, []string{"sales_2", "sales_1", "cost"}},
+		{[]interface{}{OfType("float64"), "sales_1"}, []string{"sales_1", "sales_2", "cost"}},
+	}
+
+	for i, tc := range tests {
+		sdf, err := df.Select(tc.cols...)
+		if err != nil {
+			t.Errorf("%d: error encountered: %s\n", i, err)
+			continue
+		}
+
+		if names := sdf.Names(); !cmp.Equal(names, tc.expected) {
+			t.Errorf("%d: expected: %v actual: %v\n", i, tc.expected, names)
+		}
+	}
+
+	for _, cols := range [][]interface{}{{"unknown"}, {5}, {Glob("[")}, {1.5}} {
+		if _, err := df.Select(cols...); err == nil {
+			t.Errorf("expected error for %v", cols)
+		}
+	}
+
+	// Index is preserved and Series are shared by default
+	sdf, _ := df.Select("id", "sales_1")
+	if !cmp.Equal(sdf.Index(), []string{"id"}) {
+		t.Errorf("wrong index: %v", sdf.Index())
+	}
+	if sdf.Series[1] != df.Series[1] {
+		t.Errorf("expected Series to be shared")
+	}
+
+	cdf, _ := df.Select("sales_1", SelectOptions{Copy: true})
+	if cdf.Series[0] == df.Series[1] {
+		t.Errorf("expected Series to be copied")
+	}
+
+	ddf, err := df.Drop(OfType("float64"))
+	if err != nil {
+		t.Fatalf("error encountered: %s\n", err)
+	}
+	if names := ddf.Names(); !cmp.Equal(names, []string{"id", "code"}) {
+		t.Errorf("wrong names: %v", names)
+	}
+
+	// Rename
+	if err := df.RenameColumns(map[string]string{"cost": "code"}); err == nil {
+		t.Errorf("expected error for duplicate name")
+	}
+	if err := df.RenameColumns(map[string]string{"unknown": "x"}); err == nil {
+		t.Errorf("expected error for unknown series")
+	}
+	if err := df.RenameColumns(map[string]string{"code": "cost", "cost": "code"}); err != nil {
+		t.Fatalf("error encountered: %s\n", err)
+	}
+	if names := df.Names(); !cmp.Equal(names, []string{"id", "sales_1", "sales_2", "cost", "code"}) {
+		t.Errorf("wrong names: %v", names)
+	}
+}
+
+func TestExpr(t *testing.T) {
+	ctx := context.Background()
+
+	newDf := func() *DataFrame {
+		return NewDataFrame(
+			NewSeriesString("country", nil, "AU", "NZ", "AU", "AU", nil),
+			NewSeriesFloat64("price", nil, 10.5, 20.0, nil, 50.0, 100.0),
+			NewSeriesInt64("qty", nil, 20, 10, 3, 1, 2),
+		)
+	}
+
+	// Filter
+	df := newDf()
+	fdf, err := Filter(ctx, df, "price * qty > 100 && country == 'AU'")
+	if err != nil {
+		t.Fatalf("error encountered: %s\n", err)
+	}
+
+	expected := NewDataFrame(
+		NewSeriesString("country", nil, "AU"),
+		NewSeriesFloat64("price", nil, 10.5),
+		NewSeriesInt64("qty", nil, 20),
+	)
+	if eq, _ := fdf.(*DataFrame).IsEqual(ctx, expected); !eq {
+		t.Errorf("wrong filtered dataframe:\n%s", fdf.(*DataFrame).Table())
+	}
+
+	fdf, _ = Filter(ctx, df, "price == nil || !(country != nil)")
+	if n := fdf.(*DataFrame).NRows(); n != 2 {
+		t.Errorf("expected 2 rows with nil values: %d", n)
+	}
+
+	// Eval
+	if err := df.Eval(ctx, "total = price * qty"); err != nil {
+		t.Fatalf("error encountered: %s\n", err)
+	}
+	if err := df.Eval(ctx, "qty = qty % 3 + 1"); err != nil {
+		t.Fatalf("error encountered: %s\n", err)
+	}
+	if err := df.Eval(ctx, "`is big` = total >= 200 || country == 'NZ'"); err != nil {
+		t.Fatalf("error encountered: %s\n", err)
+	}
+
+	expected = NewDataFrame(
+		NewSeriesString("country", nil, "AU", "NZ", "AU", "AU", nil),
+		NewSeriesFloat64("price", nil, 10.5, 20.0, nil, 50.0, 100.0),
+		NewSeriesInt64("qty", nil, 3, 2, 1, 2, 3),
+		NewSeriesFloat64("total", nil, 210.0, 200.0, nil, 50.0, 200.0),
+		NewSeriesGeneric("is big", false, nil, true, true, nil, false, true),
+	)
+	if eq, _ := df.IsEqual(ctx, expected, IsEqualOptions{CheckName: true}); !eq {
+		t.Errorf("wrong evaluated dataframe:\n%s", df.Table())
+	}
+
+	// Sort
+	df = newDf()
+	df.Sort(ctx, []SortKey{{Expr: "price * qty", Desc: true}, {Key: "qty"}})
+
+	expected = NewDataFrame(
+		NewSeriesString("country", nil, "AU", nil, "NZ", "AU", "AU"),
+		NewSeriesFloat64("price", nil, 10.5, 100.0, 20.0, 50.0, nil),
+		NewSeriesInt64("qty", nil, 20, 2, 10, 1, 3),
+	)
+	if eq, _ := df.IsEqual(ctx, expected); !eq {
+		t.Errorf("wrong sorted dataframe:\n%s", df.Table())
+	}
+
+	// x % 0 is nil, so it doesn't stop Sort
+	df = newDf()
+	if completed, err := df.TrySort(ctx, []SortKey{{Expr: "qty % (qty - 3)"}}); !completed || err != nil {
+		t.Errorf("expected sort to complete: %v", err)
+	}
+	if v := df.Series[2].Value(0); v != int64(3) {
+		t.Errorf("expected nil key to be sorted first: %v", df.Table())
+	}
+
+	// A bool Series created by Eval can be used in other expressions
+	df = newDf()
+	if err := df.Eval(ctx, "flag = price > 15"); err != nil {
+		t.Fatalf("error encountered: %s\n", err)
+	}
+	fdf, err = Filter(ctx, df, "flag && qty > 1")
+	if err != nil {
+		t.Fatalf("error encountered: %s\n", err)
+	}
+	if n := fdf.(*DataFrame).NRows(); n != 2 {
+		t.Errorf("expected 2 rows: %d", n)
+	}
+	if _, err := df.TrySort(ctx, []SortKey{{Expr: "!flag"}, {Key: "qty"}}); err != nil {
+		t.Fatalf("error encountered: %s\n", err)
+	}
+	// nil < false < true
+	if eq, _ := df.Series[2].IsEqual(ctx, NewSeriesInt64("qty", nil, 3, 1, 2, 10, 20)); !eq {
+		t.Errorf("wrong sorted dataframe:\n%s", df.Table())
+	}
+
+	// TrySort returns an error instead of panicking
+	for _, keys := range [][]SortKey{{{Expr: "price *"}}, {{Key: "unknown"}}, {{Key: 3}}, {{Key: 1.5}}} {
+		df = newDf()
+		if _, err := df.TrySort(ctx, keys); err == nil {
+			t.Errorf("expected error for key: %v", keys[0])
+		}
+		if eq, _ := df.IsEqual(ctx, newDf()); !eq {
+			t.Errorf("dataframe modified for key: %v", keys[0])
+		}
+	}
+
+	// Compilation errors point at the offending token
+	errTests := []struct {
+		expr  string
+		pos   int
+		token string
+	}{
+		{"pric * qty", 0, "pric"},
+		{"price * 'x'", 6, "*"},
+		{"price > 10 && qty", 11, "&&"},
+		{"(price > 10", 11, ""},
+		{"country = 'AU'", 8, "="},
+		{"qty % 1.5", 4, "%"},
+		{"country == 'AU", 11, "'AU"},
+		{"price # 2", 6, "#"},
+	}
+
+	for _, tc := range errTests {
+		_, err := CompileExpr(df, tc.expr)
+
+		var ee *ExprError
+		if !errors.As(err, &ee) {
+			t.Errorf("%s: expected ExprError: %v", tc.expr, err)
+			continue
+		}
+		if ee.Pos != tc.pos || ee.Token != tc.token {
+			t.Errorf("%s: expected position %d token %q actual: %v", tc.expr, tc.pos, tc.token, err)
+		}
+	}
+
+	err = df.Eval(ctx, "total = price *")
+	if ee, ok := err.(*ExprError); !ok || ee.Pos != 15 {
+		t.Errorf("expected error at end of expression: %v", err)
+	}
+
+	if _, err := Filter(ctx, df, "price * qty"); err == nil {
+		t.Errorf("expected error for non bool filter expression")
+	}
+}
+
+func TestSchema(t *testing.T) {
+	ctx := context.Background()
+
+	s1 := NewSeriesInt64("id", nil, 1, 2, 2)
+	s2 := NewSeriesFloat64("price", nil, 50.3, nil, -1)
+	s3 := NewSeriesString("code", nil, "AU", "NZ", "usa")
+	df := NewDataFrame(s1, s2, s3)
+
+	schema := df.Schema()
+
+	expected := Schema{Fields: []SchemaField{
+		{Name: "id", Type: "int64"},
+		{Name: "price", Type: "float64", Nullable: true},
+		{Name: "code", Type: "string"},
+	}}
+	if !cmp.Equal(schema, expected) {
+		t.Errorf("wrong schema: %s", cmp.Diff(expected, schema))
+	}
+
+	if err := schema.Validate(ctx, df); err != nil {
+		t.Errorf("expected valid: %v", err)
+	}
+
+	// JSON round trip
+	b, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Schema
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(decoded, schema) {
+		t.Errorf("wrong decoded schema: %s", cmp.Diff(schema, decoded))
+	}
+
+	// NewDataFrame
+	empty, err := schema.NewDataFrame(&SeriesInit{Size: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty.NRows() != 2 || strings.Join(empty.Names(), ",") != "id,price,code" {
+		t.Errorf("wrong DataFrame: %v", empty)
+	}
+
+	// Constraints
+	var constrained Schema
+	err = json.Unmarshal([]byte(`{"fields": [
+		{"name": "id", "type": "int64", "constraints": {"unique": true}},
+		{"name": "price", "type": "float64", "constraints": {"min": 0}},
+		{"name": "code", "type": "string", "constraints": {"pattern": "^[A-Z]+$", "enum": ["AU", "USA"]}},
+		{"name": "missing", "type": "time"}
+	]}`), &constrained)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = constrained.Validate(ctx, df)
+	ec, ok := err.(*ErrorCollection)
+	if !ok {
+		t.Fatalf("expected ErrorCollection actual %v", err)
+	}
+
+	expectedErrs := []string{
+		"row: 2: series id: value 2 is not unique (see row 1)",
+		"row: 1: series price: nil value",
+		"row: 2: series price: value -1 is less than 0",
+		"row: 1: series code: value NZ is not allowed",
+		"row: 2: series code: value usa does not match pattern ^[A-Z]+$",
+		"row: 2: series code: value usa is not allowed",
+		"series not found: missing",
+	}
+
+	actualErrs := []string{}
+	for _, err := range ec.Errors() {
+		actualErrs = append(actualErrs, err.Error())
+	}
+	if !cmp.Equal(actualErrs, expectedErrs) {
+		t.Errorf("wrong errors: %s", cmp.Diff(expectedErrs, actualErrs))
+	}
+
+	// Constraints are checked using the same Rules as Validate
+	var shared Schema
+	err = json.Unmarshal([]byte(`{"fields": [
+		{"name": "id", "type": "int64", "constraints": {"enum": ["1", "2"], "max": 1}},
+		{"name": "price", "type": "float64", "nullable": true},
+		{"name": "code", "type": "string", "constraints": {"minLength": 3, "pattern": "["}}
+	]}`), &shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = shared.Validate(ctx, df)
+	if err == nil || err.Error() != "row: 1: series id: value 2 is greater than 1\nrow: 2: series id: value 2 is greater than 1\nseries code: invalid pattern: error parsing regexp: missing closing ]: `[`\n" {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	// Structure
+	wrong := Schema{Fields: []SchemaField{{Name: "code", Type: "string"}, {Name: "id", Type: "float64"}}}
+	err = wrong.Validate(ctx, df)
+	if err == nil || err.Error() != "unexpected series: price\nseries code: expected position 0 actual 2\nseries id: expected position 1 actual 0\nseries id: expected type float64 actual int64\n" {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	if _, err := (Schema{Fields: []SchemaField{{Name: "x", Type: "unknown"}}}).NewDataFrame(nil); err == nil {
+		t.Errorf("expected error for unknown type")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	ctx := context.Background()
+
+	s1 := NewSeriesInt64("id", nil, 1, 2, 2, nil)
+	s2 := NewSeriesFloat64("score", nil, 50.5, 101, 0, 20)
+	s3 := NewSeriesString("code", nil, "AU", "nz", "NZ", "US")
+	df := NewDataFrame(s1, s2, s3)
+
+	rules := map[string][]Rule{
+		"id":    {NotNil, Unique, Monotonic},
+		"score": {Between(0, 100), Monotonic},
+		"code":  {Matches(`^[A-Z]{2}$`), In("AU", "NZ")},
+	}
+
+	report, err := Validate(ctx, df, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"row: 1: series score: value 101 is not between 0 and 100",
+		"row: 1: series code: value nz does not match pattern ^[A-Z]{2}$",
+		"row: 1: series code: value nz is not allowed",
+		"row: 2: series id: value 2 is not unique (see row 1)",
+		"row: 2: series score: value 0 is less than the previous value 101 (see row 1)",
+		"row: 3: series id: nil value",
+		"row: 3: series code: value US is not allowed",
+	}
+
+	actual := []string{}
+	for _, re := range report.Errors {
+		actual = append(actual, re.Error())
+	}
+	if !cmp.Equal(actual, expected) {
+		t.Errorf("wrong errors: %s", cmp.Diff(expected, actual))
+	}
+
+	if report.IsValid() || !cmp.Equal(report.Rows(), []int{1, 2, 3}) {
+		t.Errorf("wrong rows: %v", report.Rows())
+	}
+
+	if !errors.Is(report.Errors[5], ErrNilValue) {
+		t.Errorf("expected ErrNilValue")
+	}
+
+	// Drop invalid rows
+	_, err = Validate(ctx, df, rules, ValidateOptions{DropInvalid: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if df.NRows() != 1 || df.Series[2].Value(0) != "AU" {
+		t.Errorf("wrong DataFrame: %v", df)
+	}
+
+	report, err = Validate(ctx, df, rules)
+	if err != nil || !report.IsValid() {
+		t.Errorf("expected valid: %v %v", err, report.Errors)
+	}
+
+	// Errors
+	if _, err := Validate(ctx, df, map[string][]Rule{"unknown": {NotNil}}); err == nil {
+		t.Errorf("expected error for unknown series")
+	}
+	if _, err := Validate(ctx, df, map[string][]Rule{"code": {Between(0, 1)}}); err == nil {
+		t.Errorf("expected error for invalid series type")
+	}
+	if _, err := Validate(ctx, df, map[string][]Rule{"code": {Matches(`[`)}}); err == nil || !strings.HasPrefix(err.Error(), "series code: invalid pattern: ") {
+		t.Errorf("expected error for invalid pattern: %v", err)
+	}
+
+	// Length and OneOf compare the values as strings
+	df = NewDataFrame(NewSeriesInt64("n", nil, 1, 22, 333, nil))
+
+	report, err = Validate(ctx, df, map[string][]Rule{"n": {Length(2, 2), OneOf("1", "22"), Between(math.Inf(-1), 100)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = []string{
+		"row: 0: series n: value 1 is shorter than 2 characters",
+		"row: 2: series n: value 333 is longer than 2 characters",
+		"row: 2: series n: value 333 is not allowed",
+		"row: 2: series n: value 333 is greater than 100",
+	}
+
+	actual = []string{}
+	for _, re := range report.Errors {
+		actual = append(actual, re.Error())
+	}
+	if !cmp.Equal(actual, expected) {
+		t.Errorf("wrong errors: %s", cmp.Diff(expected, actual))
+	}
+}
+
+func TestDiff(t *testing.T) {
+	ctx := context.Background()
+
+	a := NewDataFrame(
+		NewSeriesInt64("id", nil, 1, 2, 3),
+		NewSeriesFloat64("price", nil, 1.5, 2.5, nil),
+		NewSeriesString("name", nil, "a", "b", "c"),
+	)
+
+	b := NewDataFrame(
+		NewSeriesInt64("id", nil, 2, 1, 4),
+		NewSeriesFloat64("price", nil, 2.5000001, 1.75, 4),
+		NewSeriesString("name", nil, "b", "a", "d"),
+	)
+
+	// Positional
+	report, err := Diff(ctx, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.IsEqual() || report.Count(DiffChanged) != 3 || report.Count(DiffAdded) != 0 {
+		t.Errorf("wrong report: %v", report.Rows)
+	}
+
+	// Keys with tolerance
+	report, err = Diff(ctx, a, b, DiffOptions{Keys: []string{"id"}, Tolerance: 1e-6})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []DiffRow{
+		{Type: DiffChanged, RowA: 0, RowB: 1, Key: []string{"1"}, Cells: []DiffCell{{Series: "price", Old: 1.5, New: 1.75, OldString: "1.5", NewString: "1.75"}}},
+		{Type: DiffRemoved, RowA: 2, RowB: -1, Key: []string{"3"}, Cells: []DiffCell{
+			{Series: "id", Old: int64(3), OldString: "3"},
+			{Series: "price", OldString: "NaN"},
+			{Series: "name", Old: "c", OldString: "c"},
+		}},
+		{Type: DiffAdded, RowA: -1, RowB: 2, Key: []string{"4"}, Cells: []DiffCell{
+			{Series: "id", New: int64(4), NewString: "4"},
+			{Series: "price", New: 4.0, NewString: "4"},
+			{Series: "name", New: "d", NewString: "d"},
+		}},
+	}
+	if !cmp.Equal(report.Rows, expected) {
+		t.Errorf("wrong rows: %s", cmp.Diff(expected, report.Rows))
+	}
+
+	table := report.Table()
+	for _, s := range []string{"1.5 → 1.75", "0→1:", "+1 -1 ~1"} {
+		if !strings.Contains(table, s) {
+			t.Errorf("expected table to contain %s:\n%s", s, table)
+		}
+	}
+
+	// Equal
+	report, err = Diff(ctx, a, a.Copy())
+	if err != nil || !report.IsEqual() {
+		t.Errorf("expected equal: %v %v", err, report.Rows)
+	}
+
+	// Added and removed series
+	c := NewDataFrame(NewSeriesInt64("id", nil, 1, 2, 3), NewSeriesInt64("qty", nil, 1, 1, 1))
+	report, err = Diff(ctx, a, c, DiffOptions{Keys: []string{"id"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(report.AddedSeries, []string{"qty"}) || !cmp.Equal(report.RemovedSeries, []string{"price", "name"}) || len(report.Rows) != 0 {
+		t.Errorf("wrong report: %v %v %v", report.AddedSeries, report.RemovedSeries, report.Rows)
+	}
+
+	// Errors
+	if _, err := Diff(ctx, a, c, DiffOptions{Keys: []string{"name"}}); err == nil {
+		t.Errorf("expected error for missing key")
+	}
+	d := NewDataFrame(NewSeriesInt64("id", nil, 1, 1))
+	if _, err := Diff(ctx, a, d, DiffOptions{Keys: []string{"id"}}); err == nil {
+		t.Errorf("expected error for duplicate key")
+	}
+}
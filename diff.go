@@ -0,0 +1,448 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package dataframe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// DiffOptions modifies the behaviour of the Diff function.
+type DiffOptions struct {
+
+	// Keys is the names of the Series used to align the rows of the DataFrames.
+	// Each combination of key values (as returned by ValueString) must be unique within each DataFrame.
+	// When not set, the rows are aligned by the index if both DataFrames have the same index (see SetIndex)
+	// and its labels are unique within each DataFrame. Otherwise, the rows are aligned by position.
+	Keys []string
+
+	// Tolerance is the maximum absolute difference between two numeric (float64 or int64)
+	// values for them to be considered equal.
+	Tolerance float64
+
+	// RelativeTolerance is the maximum difference between two numeric (float64 or int64) values,
+	// relative to the larger magnitude of the two values, for them to be considered equal.
+	// eg. 0.01 means 1%.
+	RelativeTolerance float64
+
+	// DontLock can be set to true if the DataFrames should not be locked.
+	DontLock bool
+}
+
+// DiffType signifies how a row differs.
+type DiffType int
+
+const (
+	// DiffAdded means that the row only exists in the second DataFrame.
+	DiffAdded DiffType = 0
+
+	// DiffRemoved means that the row only exists in the first DataFrame.
+	DiffRemoved DiffType = 1
+
+	// DiffChanged means that the row exists in both DataFrames but the values are different.
+	DiffChanged DiffType = 2
+)
+
+// DiffCell contains the values of a Series for a row that differs.
+type DiffCell struct {
+
+	// Series is the name of the Series.
+	Series string
+
+	// Old is the value in the first DataFrame. It is nil for an added row.
+	Old interface{}
+
+	// New is the value in the second DataFrame. It is nil for a removed row.
+	New interface{}
+
+	// OldString and NewString are the values as returned by ValueString.
+	OldString, NewString string
+}
+
+// DiffRow is a row that differs between the DataFrames.
+type DiffRow struct {
+
+	// Type signifies how the row differs.
+	Type DiffType
+
+	// RowA is the row in the first DataFrame. It is -1 for an added row.
+	RowA int
+
+	// RowB is the row in the second DataFrame. It is -1 for a removed row.
+	RowB int
+
+	// Key contains the values (as returned by ValueString) of the Keys option.
+	Key []string
+
+	// Cells contains the values that are different for a changed row.
+	// For an added or removed row, it contains every value of the row.
+	Cells []DiffCell
+}
+
+// DiffReport contains the differences found by Diff.
+type DiffReport struct {
+
+	// Rows contains the rows that differ. Rows that exist in the first DataFrame are ordered by RowA.
+	// They are followed by the added rows, ordered by RowB.
+	Rows []DiffRow
+
+	// AddedSeries contains the names of the Series that only exist in the second DataFrame.
+	AddedSeries []string
+
+	// RemovedSeries contains the names of the Series that only exist in the first DataFrame.
+	RemovedSeries []string
+
+	keys    []string
+	columns []string // names of all the Series in the order they are displayed
+}
+
+// IsEqual returns true if no differences were found.
+func (r *DiffReport) IsEqual() bool {
+	return len(r.Rows) == 0 && len(r.AddedSeries) == 0 && len(r.RemovedSeries) == 0
+}
+
+// Count returns the number of rows of a particular DiffType.
+func (r *DiffReport) Count(typ DiffType) int {
+	var count int
+	for _, row := range r.Rows {
+		if row.Type == typ {
+			count++
+		}
+	}
+	return count
+}
+
+// Diff compares the rows of a and b. The Series are matched by name.
+//
+// The values are compared using the IsEqualFunc of a's Series, unless a tolerance is set for numeric values.
+// When the Series of a and b have different types, their values are compared using ValueString.
+//
+// Example:
+//
+//  report, err := dataframe.Diff(ctx, expected, actual, dataframe.DiffOptions{Keys: []string{"id"}, Tolerance: 1e-9})
+//  if err != nil {
+//     return err
+//  }
+//  if !report.IsEqual() {
+//     fmt.Print(report.Table())
+//  }
+//
+func Diff(ctx context.Context, a, b *DataFrame, opts ...DiffOptions) (*DiffReport, error) {
+
+	if len(opts) == 0 {
+		opts = append(opts, DiffOptions{})
+	}
+
+	if !opts[0].DontLock {
+		a.lock.RLock()
+		defer a.lock.RUnlock()
+		if b != a {
+			b.lock.RLock()
+			defer b.lock.RUnlock()
+		}
+	}
+
+	if len(opts[0].Keys) == 0 {
+		ia, ib := a.Index(dontLock), b.Index(dontLock)
+		if len(ia) > 0 && reflect.DeepEqual(ia, ib) && a.isIndexUnique() && b.isIndexUnique() {
+			o := opts[0]
+			o.Keys = ia
+			opts = []DiffOptions{o}
+		}
+	}
+
+	r := &DiffReport{
+		Rows:          []DiffRow{},
+		AddedSeries:   []string{},
+		RemovedSeries: []string{},
+		keys:          opts[0].Keys,
+	}
+
+	// Match the Series by name
+	type pair struct {
+		name   string
+		sa, sb Series
+	}
+
+	keys := map[string]struct{}{}
+	for _, k := range opts[0].Keys {
+		keys[k] = struct{}{}
+	}
+
+	pairs := []pair{}
+	keySeries := map[string]pair{}
+
+	for _, sa := range a.Series {
+		name := sa.Name(dontLock)
+		r.columns = append(r.columns, name)
+
+		col, err := b.NameToColumn(name, dontLock)
+		if err != nil {
+			r.RemovedSeries = append(r.RemovedSeries, name)
+			continue
+		}
+
+		p := pair{name: name, sa: sa, sb: b.Series[col]}
+		if _, exists := keys[name]; exists {
+			keySeries[name] = p
+			continue
+		}
+		pairs = append(pairs, p)
+	}
+
+	for _, sb := range b.Series {
+		name := sb.Name(dontLock)
+		if _, err := a.NameToColumn(name, dontLock); err != nil {
+			r.AddedSeries = append(r.AddedSeries, name)
+			r.columns = append(r.columns, name)
+		}
+	}
+
+	for _, k := range opts[0].Keys {
+		if _, exists := keySeries[k]; !exists {
+			return nil, fmt.Errorf("key not found in both DataFrames: %s", k)
+		}
+	}
+
+	// Align the rows
+	nRowsA, nRowsB := a.n, b.n
+
+	keyValues := func(df *DataFrame, row int) []string {
+		if len(opts[0].Keys) == 0 {
+			return nil
+		}
+
+		vals := []string{}
+		for _, k := range opts[0].Keys {
+			p := keySeries[k]
+			s := p.sa
+			if df == b {
+				s = p.sb
+			}
+			vals = append(vals, s.ValueString(row, dontLock))
+		}
+		return vals
+	}
+
+	matches := [][2]int{} // rows of a and b that are aligned
+	var added []int       // rows of b that are not aligned
+
+	if len(opts[0].Keys) == 0 {
+		for row := 0; row < nRowsA || row < nRowsB; row++ {
+			switch {
+			case row >= nRowsB:
+				matches = append(matches, [2]int{row, -1})
+			case row >= nRowsA:
+				added = append(added, row)
+			default:
+				matches = append(matches, [2]int{row, row})
+			}
+		}
+	} else {
+		rowsB := map[string]int{}
+		for row := 0; row < nRowsB; row++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			key := strings.Join(keyValues(b, row), "\x00")
+			if _, exists := rowsB[key]; exists {
+				return nil, fmt.Errorf("duplicate key: %v row: %d", keyValues(b, row), row)
+			}
+			rowsB[key] = row
+		}
+
+		rowsA := map[string]struct{}{}
+		for row := 0; row < nRowsA; row++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			key := strings.Join(keyValues(a, row), "\x00")
+			if _, exists := rowsA[key]; exists {
+				return nil, fmt.Errorf("duplicate key: %v row: %d", keyValues(a, row), row)
+			}
+			rowsA[key] = struct{}{}
+
+			rowB, exists := rowsB[key]
+			if !exists {
+				rowB = -1
+			}
+			matches = append(matches, [2]int{row, rowB})
+		}
+
+		for row := 0; row < nRowsB; row++ {
+			if _, exists := rowsA[strings.Join(keyValues(b, row), "\x00")]; !exists {
+				added = append(added, row)
+			}
+		}
+	}
+
+	// Compare the rows
+	for _, m := range matches {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		rowA, rowB := m[0], m[1]
+
+		if rowB == -1 {
+			dr := DiffRow{Type: DiffRemoved, RowA: rowA, RowB: -1, Key: keyValues(a, rowA), Cells: []DiffCell{}}
+			for _, s := range a.Series {
+				val := s.Value(rowA, dontLock)
+				dr.Cells = append(dr.Cells, DiffCell{Series: s.Name(dontLock), Old: val, OldString: s.ValueString(rowA, dontLock)})
+			}
+			r.Rows = append(r.Rows, dr)
+			continue
+		}
+
+		dr := DiffRow{Type: DiffChanged, RowA: rowA, RowB: rowB, Key: keyValues(a, rowA), Cells: []DiffCell{}}
+		for _, p := range pairs {
+			if !diffIsEqual(p.sa, p.sb, rowA, rowB, opts[0]) {
+				dr.Cells = append(dr.Cells, DiffCell{
+					Series:    p.name,
+					Old:       p.sa.Value(rowA, dontLock),
+					New:       p.sb.Value(rowB, dontLock),
+					OldString: p.sa.ValueString(rowA, dontLock),
+					NewString: p.sb.ValueString(rowB, dontLock),
+				})
+			}
+		}
+		if len(dr.Cells) > 0 {
+			r.Rows = append(r.Rows, dr)
+		}
+	}
+
+	for _, rowB := range added {
+		dr := DiffRow{Type: DiffAdded, RowA: -1, RowB: rowB, Key: keyValues(b, rowB), Cells: []DiffCell{}}
+		for _, s := range b.Series {
+			val := s.Value(rowB, dontLock)
+			dr.Cells = append(dr.Cells, DiffCell{Series: s.Name(dontLock), New: val, NewString: s.ValueString(rowB, dontLock)})
+		}
+		r.Rows = append(r.Rows, dr)
+	}
+
+	return r, nil
+}
+
+// diffIsEqual compares the value of sa at rowA with the value of sb at rowB.
+func diffIsEqual(sa, sb Series, rowA, rowB int, opts DiffOptions) bool {
+
+	va := sa.Value(rowA, dontLock)
+	vb := sb.Value(rowB, dontLock)
+
+	if va == nil || vb == nil {
+		return va == nil && vb == nil
+	}
+
+	if opts.Tolerance != 0 || opts.RelativeTolerance != 0 {
+		fa, okA := diffFloat(va)
+		fb, okB := diffFloat(vb)
+		if okA && okB {
+			diff := math.Abs(fa - fb)
+			return diff <= opts.Tolerance || diff <= opts.RelativeTolerance*math.Max(math.Abs(fa), math.Abs(fb))
+		}
+	}
+
+	if sa.Type() != sb.Type() {
+		return sa.ValueString(rowA, dontLock) == sb.ValueString(rowB, dontLock)
+	}
+
+	return sa.IsEqualFunc(va, vb)
+}
+
+func diffFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// Table will produce the differences in a table.
+// Added rows are marked with "+", removed rows with "-" and changed rows with "~".
+// Changed values are displayed as "old → new". For changed rows, only the Keys and the changed values are displayed.
+func (r *DiffReport) Table() string {
+
+	data := [][]string{}
+
+	headers := []string{"", "row"}
+	headers = append(headers, r.columns...)
+
+	columns := map[string]int{}
+	for idx, name := range r.columns {
+		columns[name] = idx
+	}
+
+	for _, dr := range r.Rows {
+
+		vals := make([]string, len(r.columns))
+
+		var sVals []string
+		switch dr.Type {
+		case DiffAdded:
+			sVals = []string{"+", fmt.Sprintf("%d:", dr.RowB)}
+			for _, c := range dr.Cells {
+				vals[columns[c.Series]] = c.NewString
+			}
+		case DiffRemoved:
+			sVals = []string{"-", fmt.Sprintf("%d:", dr.RowA)}
+			for _, c := range dr.Cells {
+				vals[columns[c.Series]] = c.OldString
+			}
+		case DiffChanged:
+			if dr.RowA == dr.RowB {
+				sVals = []string{"~", fmt.Sprintf("%d:", dr.RowA)}
+			} else {
+				sVals = []string{"~", fmt.Sprintf("%d→%d:", dr.RowA, dr.RowB)}
+			}
+			for i, k := range r.keys {
+				vals[columns[k]] = dr.Key[i]
+			}
+			for _, c := range dr.Cells {
+				vals[columns[c.Series]] = c.OldString + " → " + c.NewString
+			}
+		}
+
+		data = append(data, append(sVals, vals...))
+	}
+
+	footers := []string{" ", ""}
+	for _, name := range r.columns {
+		footer := " "
+		for _, s := range r.AddedSeries {
+			if s == name {
+				footer = "added"
+			}
+		}
+		for _, s := range r.RemovedSeries {
+			if s == name {
+				footer = "removed"
+			}
+		}
+		footers = append(footers, footer)
+	}
+	footers[1] = fmt.Sprintf("+%d -%d ~%d", r.Count(DiffAdded), r.Count(DiffRemoved), r.Count(DiffChanged))
+
+	var buf bytes.Buffer
+
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader(headers)
+	for _, v := range data {
+		table.Append(v)
+	}
+	table.SetFooter(footers)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+
+	table.Render()
+
+	return buf.String()
+}
@@ -0,0 +1,234 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package dataframe
+
+import (
+	"context"
+	"math"
+	"math/cmplx"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Relaxed returns true if an option is set that relaxes the default (exact) comparison.
+// A Series' IsEqual implementation should then defer to IsEqualSeries.
+func (o IsEqualOptions) Relaxed() bool {
+	return o.Tolerance != 0 || o.RelativeTolerance != 0 || o.NaNNotEqual || o.IgnoreSeriesType || o.IgnoreRowOrder
+}
+
+// IsEqualSeries is a generic implementation of IsEqual that supports all the options of IsEqualOptions.
+// It accesses the values of s1 and s2 using Value and ValueString, so it can be used by custom Series.
+// s2 is not locked.
+//
+// Example:
+//
+//  func (s *SeriesCustom) IsEqual(ctx context.Context, s2 dataframe.Series, opts ...dataframe.IsEqualOptions) (bool, error) {
+//     if len(opts) > 0 && opts[0].Relaxed() {
+//        return dataframe.IsEqualSeries(ctx, s, s2, opts...)
+//     }
+//     ...
+//  }
+//
+func IsEqualSeries(ctx context.Context, s1, s2 Series, opts ...IsEqualOptions) (bool, error) {
+	if len(opts) == 0 {
+		opts = append(opts, IsEqualOptions{})
+	}
+
+	if !opts[0].DontLock {
+		s1.Lock()
+		defer s1.Unlock()
+	}
+
+	return isEqualRows(ctx, []Series{s1}, []Series{s2}, opts[0])
+}
+
+// isEqualRows compares the corresponding Series of seriess1 and seriess2.
+// The Series of each slice must have the same number of rows. Neither slice is locked.
+func isEqualRows(ctx context.Context, seriess1, seriess2 []Series, o IsEqualOptions) (bool, error) {
+
+	if len(seriess1) != len(seriess2) {
+		return false, nil
+	}
+
+	if len(seriess1) == 0 {
+		return true, nil
+	}
+
+	for i := range seriess1 {
+		s1, s2 := seriess1[i], seriess2[i]
+
+		// Check type
+		if !o.IgnoreSeriesType && s1.Type() != s2.Type() {
+			return false, nil
+		}
+
+		// Check number of values
+		if s1.NRows(dontLock) != s2.NRows(dontLock) {
+			return false, nil
+		}
+
+		// Check name
+		if o.CheckName && s1.Name(dontLock) != s2.Name(dontLock) {
+			return false, nil
+		}
+	}
+
+	nRows := seriess1[0].NRows(dontLock)
+
+	rows1, err := equalRowOrder(ctx, seriess1, nRows, o.IgnoreRowOrder)
+	if err != nil {
+		return false, err
+	}
+	rows2, err := equalRowOrder(ctx, seriess2, nRows, o.IgnoreRowOrder)
+	if err != nil {
+		return false, err
+	}
+
+	// Check values
+	for i := 0; i < nRows; i++ {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+
+		for j := range seriess1 {
+			if !isEqualValue(seriess1[j], seriess2[j], rows1[i], rows2[i], o) {
+				return false, nil
+			}
+		}
+	}
+
+	return true, nil
+}
+
+// isEqualValue compares row1 of s1 with row2 of s2.
+func isEqualValue(s1, s2 Series, row1, row2 int, o IsEqualOptions) bool {
+
+	v1 := s1.Value(row1, dontLock)
+	v2 := s2.Value(row2, dontLock)
+
+	if v1 == nil || v2 == nil {
+		return v1 == nil && v2 == nil && !o.NaNNotEqual
+	}
+
+	// Numeric values are compared using the tolerances
+	if n1, ok := numericValue(v1); ok {
+		if n2, ok := numericValue(v2); ok && (o.IgnoreSeriesType || s1.Type() == s2.Type()) {
+			i1, ok1 := v1.(int64)
+			i2, ok2 := v2.(int64)
+			if ok1 && ok2 && o.Tolerance == 0 && o.RelativeTolerance == 0 {
+				// Avoid losing precision for large integers
+				return i1 == i2
+			}
+
+			if n1 == n2 {
+				return true
+			}
+			diff := cmplx.Abs(n1 - n2)
+			return diff <= o.Tolerance || diff <= o.RelativeTolerance*math.Max(cmplx.Abs(n1), cmplx.Abs(n2))
+		}
+	}
+
+	if s1.Type() == s2.Type() {
+		return s1.IsEqualFunc(v1, v2)
+	}
+
+	return s1.ValueString(row1, dontLock) == s2.ValueString(row2, dontLock)
+}
+
+// numericValue converts float64, int64 and complex128 values to complex128.
+func numericValue(v interface{}) (complex128, bool) {
+	switch n := v.(type) {
+	case float64:
+		return complex(n, 0), true
+	case int64:
+		return complex(float64(n), 0), true
+	case complex128:
+		return n, true
+	}
+	return 0, false
+}
+
+// equalRowOrder returns the order in which the rows are compared. If sortRows is set, the
+// rows are sorted so that DataFrames with the same rows in a different order can be compared.
+func equalRowOrder(ctx context.Context, seriess []Series, nRows int, sortRows bool) ([]int, error) {
+
+	rows := make([]int, nRows)
+	for i := range rows {
+		rows[i] = i
+	}
+
+	if !sortRows {
+		return rows, nil
+	}
+
+	var err error
+	sort.SliceStable(rows, func(i, j int) bool {
+		if err != nil {
+			return false
+		}
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+
+		for _, s := range seriess {
+			if c := compareValues(s, rows[i], rows[j]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// compareValues returns -1, 0 or 1 depending on whether row1 of s is less than, equal to
+// or greater than row2. nil values are ordered first. Numeric values are ordered by value
+// irrespective of their type, so that the order is the same for a SeriesInt64 and a SeriesFloat64.
+func compareValues(s Series, row1, row2 int) int {
+
+	v1 := s.Value(row1, dontLock)
+	v2 := s.Value(row2, dontLock)
+
+	switch {
+	case v1 == nil && v2 == nil:
+		return 0
+	case v1 == nil:
+		return -1
+	case v2 == nil:
+		return 1
+	}
+
+	n1, ok1 := numericValue(v1)
+	n2, ok2 := numericValue(v2)
+	if ok1 && ok2 {
+		switch {
+		case real(n1) < real(n2):
+			return -1
+		case real(n1) > real(n2):
+			return 1
+		case imag(n1) < imag(n2):
+			return -1
+		case imag(n1) > imag(n2):
+			return 1
+		}
+		return 0
+	}
+
+	t1, ok1 := v1.(time.Time)
+	t2, ok2 := v2.(time.Time)
+	if ok1 && ok2 {
+		switch {
+		case t1.Before(t2):
+			return -1
+		case t1.After(t2):
+			return 1
+		}
+		return 0
+	}
+
+	return strings.Compare(s.ValueString(row1, dontLock), s.ValueString(row2, dontLock))
+}
@@ -40,6 +40,17 @@ func (ec *ErrorCollection) IsNil(lock ...bool) bool {
 	return len(ec.errors) == 0
 }
 
+// Errors returns the errors contained in the ErrorCollection.
+func (ec *ErrorCollection) Errors(lock ...bool) []error {
+	if len(lock) == 0 || lock[0] == true {
+		// default
+		ec.Lock()
+		defer ec.Unlock()
+	}
+
+	return append([]error{}, ec.errors...)
+}
+
 // Error implements the error interface.
 func (ec *ErrorCollection) Error() string {
 
@@ -0,0 +1,114 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package exports
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Compression is the name of a compression format.
+type Compression string
+
+const (
+	// NoCompression writes the data unchanged.
+	NoCompression Compression = ""
+
+	// Gzip compresses the data using gzip.
+	Gzip Compression = "gzip"
+
+	// Zlib compresses the data using zlib.
+	Zlib Compression = "zlib"
+
+	// Zstd compresses the data using zstd. A Compressor must be registered using RegisterCompressor.
+	Zstd Compression = "zstd"
+
+	// Snappy compresses the data using snappy. A Compressor must be registered using RegisterCompressor.
+	Snappy Compression = "snappy"
+)
+
+// Compressor returns a writer that compresses the data written to it and writes it to w.
+// Close must flush any buffered data but must not close w.
+type Compressor func(w io.Writer) (io.WriteCloser, error)
+
+var (
+	compressorsMu sync.RWMutex
+	compressors   = map[Compression]Compressor{
+		Gzip: func(w io.Writer) (io.WriteCloser, error) { return gzip.NewWriter(w), nil },
+		Zlib: func(w io.Writer) (io.WriteCloser, error) { return zlib.NewWriter(w), nil },
+	}
+)
+
+// extensions maps file extensions to compression formats.
+var extensions = map[string]Compression{
+	".gz":     Gzip,
+	".gzip":   Gzip,
+	".zz":     Zlib,
+	".zlib":   Zlib,
+	".zst":    Zstd,
+	".sz":     Snappy,
+	".snappy": Snappy,
+}
+
+// RegisterCompressor registers a Compressor for a compression format.
+// It is used to add compression formats that are not supported by the standard library.
+//
+// Example (zstd):
+//
+//  import "github.com/klauspost/compress/zstd"
+//
+//  exports.RegisterCompressor(exports.Zstd, func(w io.Writer) (io.WriteCloser, error) {
+//     return zstd.NewWriter(w)
+//  })
+//
+func RegisterCompressor(format Compression, c Compressor) {
+	compressorsMu.Lock()
+	defer compressorsMu.Unlock()
+	compressors[format] = c
+}
+
+// Compress returns a writer that compresses the data written to it using format and writes it to w.
+// Close must be called to flush the compressed data. It does not close w.
+//
+// Example:
+//
+//  f, _ := os.Create("data.csv.gz")
+//  defer f.Close()
+//
+//  w, _ := exports.Compress(f, exports.CompressionFromFilename(f.Name()))
+//  exports.ExportToCSV(ctx, w, df)
+//  w.Close()
+//
+func Compress(w io.Writer, format Compression) (io.WriteCloser, error) {
+
+	if format == NoCompression {
+		return nopWriteCloser{w}, nil
+	}
+
+	compressorsMu.RLock()
+	c, exists := compressors[format]
+	compressorsMu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("compression format not registered: %s", format)
+	}
+
+	return c(w)
+}
+
+// CompressionFromFilename returns the compression format implied by the extension of a file name.
+// eg. "data.csv.gz" returns Gzip. NoCompression is returned if the extension is not recognized.
+func CompressionFromFilename(name string) Compression {
+	return extensions[strings.ToLower(filepath.Ext(name))]
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,121 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package exports
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+	"github.com/rocketlaunchr/dataframe-go/imports"
+)
+
+// upperWriter is a trivial compression format used to test RegisterCompressor.
+type upperWriter struct {
+	w io.Writer
+}
+
+func (u upperWriter) Write(p []byte) (int, error) {
+	return u.w.Write(bytes.ToUpper(p))
+}
+
+func (upperWriter) Close() error { return nil }
+
+func TestCompress(t *testing.T) {
+	ctx := context.Background()
+
+	df := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("a", nil, "x", nil),
+		dataframe.NewSeriesString("b", nil, "y", "z"),
+	)
+
+	var plain bytes.Buffer
+	if err := ExportToCSV(ctx, &plain, df); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []Compression{NoCompression, Gzip, Zlib} {
+		var buf bytes.Buffer
+
+		w, err := Compress(&buf, format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if err := ExportToCSV(ctx, w, df); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+
+		if format != NoCompression && bytes.Equal(buf.Bytes(), plain.Bytes()) {
+			t.Errorf("%s: data not compressed", format)
+		}
+
+		// The format is detected automatically
+		r, err := imports.Decompress(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		out, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+
+		if string(out) != plain.String() {
+			t.Errorf("%s: expected: %q actual: %q", format, plain.String(), string(out))
+		}
+	}
+
+	// Not registered
+	for _, format := range []Compression{Zstd, Snappy, "unknown"} {
+		if _, err := Compress(ioutil.Discard, format); err == nil {
+			t.Errorf("%s: expected error", format)
+		}
+	}
+}
+
+func TestRegisterCompressor(t *testing.T) {
+
+	format := Compression("upper")
+	RegisterCompressor(format, func(w io.Writer) (io.WriteCloser, error) { return upperWriter{w}, nil })
+
+	var buf bytes.Buffer
+	w, err := Compress(&buf, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(w, "a,b\n")
+	w.Close()
+
+	if buf.String() != "A,B\n" {
+		t.Errorf("expected: %q actual: %q", "A,B\n", buf.String())
+	}
+}
+
+func TestCompressionFromFilename(t *testing.T) {
+
+	tests := map[string]Compression{
+		"data.csv.gz":      Gzip,
+		"DATA.CSV.GZ":      Gzip,
+		"data.gzip":        Gzip,
+		"data.jsonl.zz":    Zlib,
+		"data.zlib":        Zlib,
+		"data.csv.zst":     Zstd,
+		"data.sz":          Snappy,
+		"data.snappy":      Snappy,
+		"data.csv":         NoCompression,
+		"data":             NoCompression,
+		"dir.gz/data.json": NoCompression,
+	}
+
+	for name, expected := range tests {
+		if actual := CompressionFromFilename(name); actual != expected {
+			t.Errorf("%s: expected: %q actual: %q", name, expected, actual)
+		}
+	}
+}
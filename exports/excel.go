@@ -4,6 +4,10 @@ package exports
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
 
 	dataframe "github.com/rocketlaunchr/dataframe-go"
 	"github.com/tealeg/xlsx"
@@ -21,40 +25,113 @@ type ExcelExportOptions struct {
 
 	// WriteSheet is used to specify a sheet name.
 	// When not set, it defaults to "sheet1"
+	//
+	// NOTE: This option is ignored by ExportSheetsToExcel.
 	WriteSheet *string
+
+	// StringCells will write every value as a string cell using the Series' ValueString function.
+	// By default, numeric, time.Time and bool values are written as native Excel cells.
+	StringCells bool
+
+	// Formats is used to set the Excel number format of a Series. The key of the map is the series name.
+	// It applies to numeric and time.Time values. eg. "0.00", "#,##0", "0%", "yyyy-mm-dd", "dd/mm/yyyy hh:mm"
+	Formats map[string]string
+
+	// ColumnWidths is used to set the width of the column for a Series. The key of the map is the series name.
+	ColumnWidths map[string]float64
+
+	// FreezeHeader will freeze the header row so that it remains visible when scrolling.
+	FreezeHeader bool
+
+	// AutoFilter will add an autofilter to the header row.
+	AutoFilter bool
 }
 
-// ExportToExcel exports a Dataframe to a excel file.
-func ExportToExcel(ctx context.Context, outputFilePath string, df *dataframe.DataFrame, options ...ExcelExportOptions) error {
+// ExcelSheet represents a sheet in a workbook created by ExportSheetsToExcel.
+type ExcelSheet struct {
 
-	df.Lock()
-	defer df.Unlock()
+	// Name is the name of the sheet.
+	Name string
 
-	var (
-		sheetRow *xlsx.Row
-		file     *xlsx.File
-		cell     *xlsx.Cell
-	)
+	// DataFrame contains the data written to the sheet.
+	DataFrame *dataframe.DataFrame
+
+	// Options configures how the DataFrame is written to the sheet.
+	Options *ExcelExportOptions
+}
+
+// ExportToExcel exports a Dataframe to a excel file.
+// out can be the file path (string) or an io.Writer.
+func ExportToExcel(ctx context.Context, out interface{}, df *dataframe.DataFrame, options ...ExcelExportOptions) error {
 
-	nullString := "NaN"    // Default value
 	writeSheet := "sheet1" // Write to default sheet 1 if a different one is not set
 
-	var r dataframe.Range
+	sheet := ExcelSheet{DataFrame: df}
 
 	if len(options) > 0 {
-		r = options[0].Range
+		if options[0].WriteSheet != nil {
+			writeSheet = *options[0].WriteSheet
+		}
+		sheet.Options = &options[0]
+	}
+	sheet.Name = writeSheet
+
+	return ExportSheetsToExcel(ctx, out, sheet)
+}
+
+// ExportSheetsToExcel exports multiple Dataframes to a single excel file.
+// Each Dataframe is written to its own sheet, in the order provided.
+// out can be the file path (string) or an io.Writer.
+func ExportSheetsToExcel(ctx context.Context, out interface{}, sheets ...ExcelSheet) error {
+
+	if len(sheets) == 0 {
+		return errors.New("no sheets provided")
+	}
 
-		if options[0].NullString != nil {
-			nullString = *options[0].NullString
+	switch out.(type) {
+	case string, io.Writer:
+	default:
+		return fmt.Errorf("out must be a file path (string) or an io.Writer: %T", out)
+	}
+
+	file := xlsx.NewFile()
+
+	for _, s := range sheets {
+		var opts ExcelExportOptions
+		if s.Options != nil {
+			opts = *s.Options
 		}
 
-		if options[0].WriteSheet != nil {
-			writeSheet = *options[0].WriteSheet
+		if err := writeExcelSheet(ctx, file, s.Name, s.DataFrame, opts); err != nil {
+			return err
 		}
 	}
 
-	file = xlsx.NewFile()
-	sheet, err := file.AddSheet(writeSheet)
+	switch out := out.(type) {
+	case string:
+		// Save file
+		return file.Save(out)
+	default:
+		return file.Write(out.(io.Writer))
+	}
+}
+
+func writeExcelSheet(ctx context.Context, file *xlsx.File, sheetName string, df *dataframe.DataFrame, opts ExcelExportOptions) error {
+
+	df.Lock()
+	defer df.Unlock()
+
+	var (
+		sheetRow *xlsx.Row
+		cell     *xlsx.Cell
+	)
+
+	nullString := "NaN" // Default value
+	if opts.NullString != nil {
+		nullString = *opts.NullString
+	}
+
+	sheet, err := file.AddSheet(sheetName)
 	if err != nil {
 		return err
 	}
@@ -68,10 +145,11 @@ func ExportToExcel(ctx context.Context, outputFilePath string, df *dataframe.Dat
 	}
 
 	nRows := df.NRows(dataframe.DontLock)
+	exported := 0
 
 	if nRows > 0 {
 
-		s, e, err := r.Limits(nRows)
+		s, e, err := opts.Range.Limits(nRows)
 		if err != nil {
 			return err
 		}
@@ -93,18 +171,74 @@ func ExportToExcel(ctx context.Context, outputFilePath string, df *dataframe.Dat
 				cell = sheetRow.AddCell()
 				if val == nil {
 					cell.Value = nullString
-				} else {
+				} else if opts.StringCells {
 					cell.Value = aSeries.ValueString(row)
+				} else {
+					setExcelCell(cell, aSeries, row, val, opts.Formats[aSeries.Name()])
 				}
 			}
+			exported++
 		}
 	}
 
-	// Save file
-	err = file.Save(outputFilePath)
-	if err != nil {
-		return err
+	nCols := len(df.Series)
+
+	for col, aSeries := range df.Series {
+		if width, exists := opts.ColumnWidths[aSeries.Name()]; exists {
+			if err := sheet.SetColWidth(col, col, width); err != nil {
+				return err
+			}
+		}
+	}
+
+	if opts.FreezeHeader {
+		sheet.SheetViews = []xlsx.SheetView{
+			{
+				Pane: &xlsx.Pane{
+					YSplit:      1,
+					TopLeftCell: "A2",
+					ActivePane:  "bottomLeft",
+					State:       "frozen",
+				},
+			},
+		}
+	}
+
+	if opts.AutoFilter && nCols > 0 {
+		sheet.AutoFilter = &xlsx.AutoFilter{
+			TopLeftCell:     xlsx.GetCellIDStringFromCoords(0, 0),
+			BottomRightCell: xlsx.GetCellIDStringFromCoords(nCols-1, exported),
+		}
 	}
 
 	return nil
 }
+
+// setExcelCell writes a non-nil value to a cell using a native Excel cell type
+// where possible.
+func setExcelCell(cell *xlsx.Cell, s dataframe.Series, row int, val interface{}, format string) {
+
+	switch v := val.(type) {
+	case float64:
+		if format == "" {
+			cell.SetFloat(v)
+		} else {
+			cell.SetFloatWithFormat(v, format)
+		}
+	case int64:
+		cell.SetInt64(v)
+		if format != "" {
+			cell.SetFormat(format)
+		}
+	case bool:
+		cell.SetBool(v)
+	case time.Time:
+		if format == "" {
+			cell.SetDateTime(v)
+		} else {
+			cell.SetDateWithOptions(v, xlsx.DateTimeOptions{Location: time.UTC, ExcelTimeFormat: format})
+		}
+	default:
+		cell.SetString(s.ValueString(row))
+	}
+}
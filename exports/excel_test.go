@@ -0,0 +1,240 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package exports
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+	"github.com/rocketlaunchr/dataframe-go/imports"
+	"github.com/tealeg/xlsx"
+)
+
+func excelTestDataFrame() *dataframe.DataFrame {
+	when := time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	return dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("id", nil, 1, 2, nil),
+		dataframe.NewSeriesFloat64("price", nil, 1.5, nil, -2.25),
+		dataframe.NewSeriesTime("when", nil, when, nil, when.AddDate(0, 1, 0)),
+		dataframe.NewSeriesString("note", nil, "a", "NULL", nil),
+	)
+}
+
+func TestExportToExcelRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	df := excelTestDataFrame()
+	empty := ""
+
+	tests := []struct {
+		options  ExcelExportOptions
+		expected []string // series types after loading
+	}{
+		{ExcelExportOptions{NullString: &empty}, []string{"float64", "float64", "time", "string"}},
+		{ExcelExportOptions{NullString: &empty, StringCells: true}, []string{"string", "string", "string", "string"}},
+		{ExcelExportOptions{NullString: &empty, Formats: map[string]string{"price": "0.00", "when": "yyyy-mm-dd hh:mm:ss"}}, []string{"float64", "float64", "time", "string"}},
+	}
+
+	for i, tc := range tests {
+		var buf bytes.Buffer
+		if err := ExportToExcel(ctx, &buf, df, tc.options); err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+
+		ldf, err := imports.LoadFromExcel(ctx, &buf)
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+
+		if ldf.NRows() != df.NRows() || len(ldf.Series) != len(df.Series) {
+			t.Errorf("%d: wrong shape. expected: %dx%d actual: %dx%d", i, df.NRows(), len(df.Series), ldf.NRows(), len(ldf.Series))
+			continue
+		}
+
+		for col, s := range ldf.Series {
+			es := df.Series[col]
+			if s.Name() != es.Name() || s.Type() != tc.expected[col] {
+				t.Errorf("%d: wrong series. expected: %s %s actual: %s %s", i, es.Name(), tc.expected[col], s.Name(), s.Type())
+				continue
+			}
+
+			for row := 0; row < s.NRows(); row++ {
+				ev, v := es.Value(row), s.Value(row)
+				if ev == nil || v == nil {
+					if ev != v {
+						t.Errorf("%d: %s row %d: expected: %v actual: %v", i, s.Name(), row, ev, v)
+					}
+					continue
+				}
+
+				switch ev := ev.(type) {
+				case int64:
+					if s.Type() == "float64" && v != float64(ev) {
+						t.Errorf("%d: %s row %d: expected: %v actual: %v", i, s.Name(), row, ev, v)
+					}
+				case time.Time:
+					if at, ok := v.(time.Time); ok && at.Sub(ev).Round(time.Second) != 0 {
+						t.Errorf("%d: %s row %d: expected: %v actual: %v", i, s.Name(), row, ev, v)
+					}
+				default:
+					if s.Type() == es.Type() && v != ev {
+						t.Errorf("%d: %s row %d: expected: %v actual: %v", i, s.Name(), row, ev, v)
+					}
+				}
+			}
+		}
+	}
+
+	// Default NullString
+	var buf bytes.Buffer
+	if err := ExportToExcel(ctx, &buf, df); err != nil {
+		t.Fatal(err)
+	}
+	ldf, err := imports.LoadFromExcel(ctx, &buf, imports.ExcelLoadOptions{Sheet: "sheet1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := ldf.Series[0].Value(2); v != "NaN" {
+		t.Errorf("wrong null string. expected: NaN actual: %v", v)
+	}
+}
+
+func TestExportSheetsToExcel(t *testing.T) {
+	ctx := context.Background()
+
+	df := excelTestDataFrame()
+	other := dataframe.NewDataFrame(dataframe.NewSeriesString("x", nil, "p", "q"))
+
+	var buf bytes.Buffer
+	err := ExportSheetsToExcel(ctx, &buf,
+		ExcelSheet{Name: "first", DataFrame: df, Options: &ExcelExportOptions{Range: dataframe.RangeFinite(0, 0)}},
+		ExcelSheet{Name: "second", DataFrame: other},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dfs, err := imports.LoadSheetsFromExcel(ctx, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dfs) != 2 {
+		t.Fatalf("wrong number of sheets. expected: 2 actual: %d", len(dfs))
+	}
+	if ldf := dfs["first"]; ldf == nil || ldf.NRows() != 1 || len(ldf.Series) != 4 {
+		t.Errorf("wrong sheet: first")
+	}
+	if ldf := dfs["second"]; ldf == nil || ldf.NRows() != 2 || ldf.Series[0].Value(1) != "q" {
+		t.Errorf("wrong sheet: second")
+	}
+
+	// Duplicate sheet names
+	err = ExportSheetsToExcel(ctx, ioutil.Discard, ExcelSheet{Name: "a", DataFrame: df}, ExcelSheet{Name: "a", DataFrame: df})
+	if err == nil {
+		t.Errorf("expected error for duplicate sheet names")
+	}
+
+	if err := ExportSheetsToExcel(ctx, ioutil.Discard); err == nil {
+		t.Errorf("expected error for no sheets")
+	}
+}
+
+func TestExportToExcelOutput(t *testing.T) {
+	ctx := context.Background()
+
+	df := excelTestDataFrame()
+
+	// File path
+	dir, err := ioutil.TempDir("", "dataframe-excel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "out.xlsx")
+	if err := ExportToExcel(ctx, path, df); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := xlsx.OpenFile(path); err != nil {
+		t.Errorf("invalid file: %v", err)
+	}
+
+	// Unsupported output
+	if err := ExportToExcel(ctx, 5, df); err == nil {
+		t.Errorf("expected error for unsupported output")
+	}
+}
+
+func TestExportToExcelLayout(t *testing.T) {
+	ctx := context.Background()
+
+	df := excelTestDataFrame()
+
+	opts := ExcelExportOptions{
+		Formats:      map[string]string{"price": "0.00"},
+		ColumnWidths: map[string]float64{"note": 30},
+		FreezeHeader: true,
+		AutoFilter:   true,
+	}
+
+	var buf bytes.Buffer
+	if err := ExportToExcel(ctx, &buf, df, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := xlsx.OpenBinary(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet := file.Sheets[0]
+
+	if f := sheet.Rows[1].Cells[1].NumFmt; f != "0.00" {
+		t.Errorf("wrong format. expected: 0.00 actual: %s", f)
+	}
+
+	var width float64
+	for _, col := range sheet.Cols {
+		if col != nil && col.Min <= 4 && 4 <= col.Max {
+			width = col.Width
+		}
+	}
+	if width != 30 {
+		t.Errorf("wrong column width. expected: 30 actual: %v", width)
+	}
+
+	// FreezeHeader and AutoFilter are checked in the sheet's xml
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sheetXML string
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, _ := ioutil.ReadAll(rc)
+			rc.Close()
+			sheetXML = string(b)
+		}
+	}
+
+	if !strings.Contains(sheetXML, `state="frozen"`) {
+		t.Errorf("expected frozen header")
+	}
+	if !strings.Contains(sheetXML, `<autoFilter ref="A1:D4">`) {
+		t.Errorf("expected autofilter: %s", sheetXML)
+	}
+}
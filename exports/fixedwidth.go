@@ -0,0 +1,250 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package exports
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+// Alignment sets how a value is aligned within a field.
+type Alignment int
+
+const (
+	// AlignDefault right aligns numeric values and left aligns everything else.
+	AlignDefault Alignment = 0
+
+	// AlignLeft left aligns the value.
+	AlignLeft Alignment = 1
+
+	// AlignRight right aligns the value.
+	AlignRight Alignment = 2
+)
+
+// FixedWidthColumn describes how a Series is written to a fixed-width file.
+type FixedWidthColumn struct {
+
+	// Name is the name of the Series.
+	Name string
+
+	// Width is the number of characters in the field.
+	// When not set, it is the length of the longest value (or heading) and the field
+	// is separated from the next field by a space.
+	Width int
+
+	// Align sets how values are aligned within the field.
+	Align Alignment
+
+	// Padding is the character used to fill the field. The default is a space. Nil values are always padded with spaces.
+	// When Padding is '0', the sign of a right aligned number is written before the zeros.
+	Padding rune
+
+	// ImpliedDecimals is the number of digits after an implied decimal point.
+	// The decimal point is not written. eg. With 2, 123.45 is written as 12345.
+	ImpliedDecimals int
+}
+
+// FixedWidthExportOptions contains options for ExportToFixedWidth function.
+type FixedWidthExportOptions struct {
+
+	// NullString is used to set what nil values should be encoded to.
+	// The default is a blank field.
+	NullString *string
+
+	// Range is used to export a subset of rows from the Dataframe.
+	Range dataframe.Range
+
+	// Columns describes which Series are exported and how. The fields are written in the order provided.
+	// When not set, every Series is exported using the default settings.
+	Columns []FixedWidthColumn
+
+	// Header will write the Series names as the first line.
+	Header bool
+
+	// Truncate will truncate values that are longer than the width of the field.
+	// When not set, an error is returned.
+	Truncate bool
+
+	// UseCRLF determines the line terminator.
+	// When true, it is set to \r\n.
+	UseCRLF bool
+}
+
+// ExportToFixedWidth exports a Dataframe to a fixed-width text file.
+// Widths are measured in characters (runes).
+func ExportToFixedWidth(ctx context.Context, w io.Writer, df *dataframe.DataFrame, options ...FixedWidthExportOptions) error {
+
+	df.Lock()
+	defer df.Unlock()
+
+	var opts FixedWidthExportOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	nullString := ""
+	if opts.NullString != nil {
+		nullString = *opts.NullString
+	}
+
+	lineTerminator := "\n"
+	if opts.UseCRLF {
+		lineTerminator = "\r\n"
+	}
+
+	cols := opts.Columns
+	if cols == nil {
+		for _, s := range df.Series {
+			cols = append(cols, FixedWidthColumn{Name: s.Name()})
+		}
+	}
+
+	// Find the Series for each field
+	seriess := []dataframe.Series{}
+	for _, col := range cols {
+		idx, err := df.NameToColumn(col.Name, dataframe.DontLock)
+		if err != nil {
+			return err
+		}
+		seriess = append(seriess, df.Series[idx])
+	}
+
+	var s, e int
+	nRows := df.NRows(dataframe.DontLock)
+	if nRows > 0 {
+		var err error
+		s, e, err = opts.Range.Limits(nRows)
+		if err != nil {
+			return err
+		}
+	} else {
+		s, e = 0, -1
+	}
+
+	// Determine the width of fields that are not set
+	widths := make([]int, len(cols))
+	gaps := make([]string, len(cols))
+	for idx, col := range cols {
+		if col.Width > 0 {
+			widths[idx] = col.Width
+			continue
+		}
+
+		max := 0
+		if opts.Header {
+			max = utf8.RuneCountInString(col.Name)
+		}
+		for row := s; row <= e; row++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			v, _ := fixedWidthValue(seriess[idx], row, col, nullString)
+			if n := utf8.RuneCountInString(v); n > max {
+				max = n
+			}
+		}
+		widths[idx] = max
+		if idx < len(cols)-1 {
+			gaps[idx] = " "
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+
+	if opts.Header {
+		for idx, col := range cols {
+			h := col
+			h.Padding = ' '
+			field, err := fixedWidthField(col.Name, widths[idx], h, false, true)
+			if err != nil {
+				return err
+			}
+			bw.WriteString(field)
+			bw.WriteString(gaps[idx])
+		}
+		bw.WriteString(lineTerminator)
+	}
+
+	for row := s; row <= e; row++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		for idx, col := range cols {
+			if seriess[idx].Value(row) == nil {
+				// Nil values are not padded with zeros
+				col.Padding = ' '
+			}
+
+			v, numeric := fixedWidthValue(seriess[idx], row, col, nullString)
+			field, err := fixedWidthField(v, widths[idx], col, numeric, opts.Truncate)
+			if err != nil {
+				return fmt.Errorf("%s. row: %d field: %s", err.Error(), row, col.Name)
+			}
+			bw.WriteString(field)
+			bw.WriteString(gaps[idx])
+		}
+		bw.WriteString(lineTerminator)
+	}
+
+	return bw.Flush()
+}
+
+// fixedWidthValue returns the string representation of a value and whether it is numeric.
+func fixedWidthValue(s dataframe.Series, row int, col FixedWidthColumn, nullString string) (string, bool) {
+
+	val := s.Value(row)
+
+	switch v := val.(type) {
+	case nil:
+		return nullString, false
+	case float64:
+		if col.ImpliedDecimals > 0 {
+			return strings.Replace(strconv.FormatFloat(v, 'f', col.ImpliedDecimals, 64), ".", "", 1), true
+		}
+		return s.ValueString(row), true
+	case int64:
+		if col.ImpliedDecimals > 0 {
+			return strconv.FormatInt(v, 10) + strings.Repeat("0", col.ImpliedDecimals), true
+		}
+		return strconv.FormatInt(v, 10), true
+	default:
+		return s.ValueString(row), false
+	}
+}
+
+// fixedWidthField aligns and pads v to width characters.
+func fixedWidthField(v string, width int, col FixedWidthColumn, numeric bool, truncate bool) (string, error) {
+
+	n := utf8.RuneCountInString(v)
+	if n > width {
+		if !truncate {
+			return "", fmt.Errorf("value: %s is longer than width: %d", v, width)
+		}
+		return string([]rune(v)[:width]), nil
+	}
+
+	padding := col.Padding
+	if padding == 0 {
+		padding = ' '
+	}
+	pad := strings.Repeat(string(padding), width-n)
+
+	right := col.Align == AlignRight || (col.Align == AlignDefault && numeric)
+	if !right {
+		return v + pad, nil
+	}
+
+	if padding == '0' && numeric && (strings.HasPrefix(v, "-") || strings.HasPrefix(v, "+")) {
+		return v[:1] + pad + v[1:], nil
+	}
+	return pad + v, nil
+}
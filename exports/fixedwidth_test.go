@@ -0,0 +1,135 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package exports
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+	"github.com/rocketlaunchr/dataframe-go/imports"
+)
+
+func TestExportToFixedWidth(t *testing.T) {
+	ctx := context.Background()
+
+	df := dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("id", nil, 1, 22, nil),
+		dataframe.NewSeriesString("first name", nil, "héllo", nil, "y"),
+		dataframe.NewSeriesFloat64("amt", nil, 1.5, -22.25, nil),
+	)
+
+	null := "-"
+
+	tests := []struct {
+		options  FixedWidthExportOptions
+		expected string
+		err      string
+	}{
+		{
+			FixedWidthExportOptions{Header: true},
+			"id first name amt   \n 1 héllo         1.5\n22            -22.25\n   y                \n",
+			"",
+		},
+		{
+			FixedWidthExportOptions{NullString: &null, UseCRLF: true, Range: dataframe.RangeFinite(1, 2)},
+			"22 - -22.25\r\n-  y -     \r\n",
+			"",
+		},
+		{
+			FixedWidthExportOptions{Columns: []FixedWidthColumn{
+				{Name: "amt", Width: 8, Padding: '0', ImpliedDecimals: 2},
+				{Name: "first name", Width: 3, Align: AlignRight},
+				{Name: "id", Width: 4, Align: AlignLeft, Padding: '*'},
+			}, Truncate: true},
+			"00000150hél1***\n-0002225   22**\n          y    \n",
+			"",
+		},
+		{
+			FixedWidthExportOptions{Columns: []FixedWidthColumn{{Name: "first name", Width: 3}}},
+			"",
+			"value: héllo is longer than width: 3. row: 0 field: first name",
+		},
+		{
+			FixedWidthExportOptions{Columns: []FixedWidthColumn{{Name: "missing"}}},
+			"",
+			"no series contains name",
+		},
+	}
+
+	for i, tc := range tests {
+		var buf bytes.Buffer
+		err := ExportToFixedWidth(ctx, &buf, df, tc.options)
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("%d: wrong error. expected: %s actual: %v", i, tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %v", i, err)
+			continue
+		}
+
+		if buf.String() != tc.expected {
+			t.Errorf("%d: wrong output. expected: %q actual: %q", i, tc.expected, buf.String())
+		}
+	}
+}
+
+func TestExportToFixedWidthRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	df := dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("id", nil, 1, 22, nil),
+		dataframe.NewSeriesString("first name", nil, "héllo", nil, "y"),
+		dataframe.NewSeriesFloat64("amt", nil, 1.5, -22.25, nil),
+		dataframe.NewSeriesString("note", nil, nil, nil, nil),
+	)
+
+	var buf bytes.Buffer
+	if err := ExportToFixedWidth(ctx, &buf, df, FixedWidthExportOptions{Header: true, Columns: []FixedWidthColumn{
+		{Name: "id"}, {Name: "first name"}, {Name: "amt"}, {Name: "note", Width: 6},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := imports.FixedWidthLoadOptions{
+		Columns: []imports.FixedWidthColumn{
+			{Name: "id", Start: 0, Width: 2, Type: int64(0)},
+			{Name: "first name", Start: 3, Width: 10},
+			{Name: "amt", Start: 14, Width: 6, Type: float64(0)},
+			{Name: "note", Start: 21, Width: 6},
+		},
+		Header: true,
+	}
+
+	ldf, err := imports.LoadFromFixedWidth(ctx, &buf, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eq, err := df.IsEqual(ctx, ldf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !eq {
+		t.Errorf("round trip not equal:\n%s\n%s", df.Table(), ldf.Table())
+	}
+
+	// ImpliedDecimals
+	buf.Reset()
+	if err := ExportToFixedWidth(ctx, &buf, df, FixedWidthExportOptions{Columns: []FixedWidthColumn{{Name: "amt", Width: 7, ImpliedDecimals: 2}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	ldf, err = imports.LoadFromFixedWidth(ctx, &buf, imports.FixedWidthLoadOptions{Columns: []imports.FixedWidthColumn{{Name: "amt", Width: 7, ImpliedDecimals: 2}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if eq, _ := df.Series[2].IsEqual(ctx, ldf.Series[0]); !eq {
+		t.Errorf("round trip not equal:\n%s", ldf.Table())
+	}
+}
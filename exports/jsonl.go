@@ -3,13 +3,42 @@
 package exports
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 
 	dataframe "github.com/rocketlaunchr/dataframe-go"
 )
 
+// JSONOrient sets the layout of the exported JSON.
+type JSONOrient int
+
+const (
+	// OrientLines exports each row as an object on a separate line (jsonl).
+	OrientLines JSONOrient = 0
+
+	// OrientRecords exports a JSON array of row objects.
+	// eg. [{"day":1,"sales":50.3},{"day":2,"sales":23.4}]
+	OrientRecords JSONOrient = 1
+
+	// OrientColumns exports an object mapping each series name to an array of values.
+	// eg. {"day":[1,2],"sales":[50.3,23.4]}
+	OrientColumns JSONOrient = 2
+
+	// OrientSplit exports the series names and the row values separately.
+	// eg. {"columns":["day","sales"],"data":[[1,50.3],[2,23.4]]}
+	OrientSplit JSONOrient = 3
+
+	// OrientTable exports a schema containing the type of each Series, followed by the row objects.
+	// It is the only layout that allows imports.LoadFromJSON to restore the Series types.
+	// With the other layouts, each field is loaded as a string unless its data type is dictated.
+	// eg. {"schema":{"fields":[{"name":"day","type":"int64"},{"name":"sales","type":"float64"}]},"data":[{"day":1,"sales":50.3}]}
+	OrientTable JSONOrient = 4
+)
+
 // JSONExportOptions contains options for ExportToJSON function.
 type JSONExportOptions struct {
 
@@ -24,10 +53,14 @@ type JSONExportOptions struct {
 	// SetEscapeHTML specifies whether problematic HTML characters should be escaped inside JSON quoted strings.
 	// See: https://golang.org/pkg/encoding/json/#Encoder.SetEscapeHTML
 	SetEscapeHTML bool
+
+	// Orient sets the layout of the exported JSON. The default is OrientLines (jsonl).
+	Orient JSONOrient
 }
 
 // ExportToJSON exports a Dataframe in the jsonl format.
 // Each line represents a row from the Dataframe.
+// Alternative layouts can be set using the Orient option.
 //
 // See: http://jsonlines.org/ for more information.
 func ExportToJSON(ctx context.Context, w io.Writer, df *dataframe.DataFrame, options ...JSONExportOptions) error {
@@ -49,6 +82,10 @@ func ExportToJSON(ctx context.Context, w io.Writer, df *dataframe.DataFrame, opt
 		if options[0].NullString != nil {
 			null = options[0].NullString
 		}
+
+		if options[0].Orient != OrientLines {
+			return exportJSONOrient(ctx, w, df, options[0])
+		}
 	}
 
 	nRows := df.NRows(dataframe.DontLock)
@@ -89,3 +126,160 @@ func ExportToJSON(ctx context.Context, w io.Writer, df *dataframe.DataFrame, opt
 
 	return nil
 }
+
+func exportJSONOrient(ctx context.Context, w io.Writer, df *dataframe.DataFrame, opts JSONExportOptions) error {
+
+	bw := bufio.NewWriter(w)
+
+	// value encodes a single value
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(opts.SetEscapeHTML)
+
+	value := func(val interface{}) error {
+		if val == nil && opts.NullString != nil {
+			val = *opts.NullString
+		}
+
+		buf.Reset()
+		if err := enc.Encode(val); err != nil {
+			return err
+		}
+		bw.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+		return nil
+	}
+
+	// object encodes a row as an object, maintaining the order of the Series
+	object := func(row int) error {
+		bw.WriteByte('{')
+		for i, aSeries := range df.Series {
+			if i > 0 {
+				bw.WriteByte(',')
+			}
+			if err := value(aSeries.Name()); err != nil {
+				return err
+			}
+			bw.WriteByte(':')
+			if err := value(aSeries.Value(row)); err != nil {
+				return err
+			}
+		}
+		bw.WriteByte('}')
+		return nil
+	}
+
+	var s, e int = 0, -1 // no rows
+
+	nRows := df.NRows(dataframe.DontLock)
+	if nRows > 0 {
+		var err error
+		s, e, err = opts.Range.Limits(nRows)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch opts.Orient {
+	case OrientRecords:
+		bw.WriteByte('[')
+		for row := s; row <= e; row++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if row > s {
+				bw.WriteByte(',')
+			}
+			if err := object(row); err != nil {
+				return err
+			}
+		}
+		bw.WriteByte(']')
+	case OrientColumns:
+		bw.WriteByte('{')
+		for i, aSeries := range df.Series {
+			if i > 0 {
+				bw.WriteByte(',')
+			}
+			if err := value(aSeries.Name()); err != nil {
+				return err
+			}
+			bw.WriteString(":[")
+			for row := s; row <= e; row++ {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+
+				if row > s {
+					bw.WriteByte(',')
+				}
+				if err := value(aSeries.Value(row)); err != nil {
+					return err
+				}
+			}
+			bw.WriteByte(']')
+		}
+		bw.WriteByte('}')
+	case OrientSplit:
+		bw.WriteString(`{"columns":`)
+		if err := value(df.Names(dataframe.DontLock)); err != nil {
+			return err
+		}
+		bw.WriteString(`,"data":[`)
+		for row := s; row <= e; row++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if row > s {
+				bw.WriteByte(',')
+			}
+			bw.WriteByte('[')
+			for i, aSeries := range df.Series {
+				if i > 0 {
+					bw.WriteByte(',')
+				}
+				if err := value(aSeries.Value(row)); err != nil {
+					return err
+				}
+			}
+			bw.WriteByte(']')
+		}
+		bw.WriteString("]}")
+	case OrientTable:
+		type field struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+		}
+
+		fields := []field{}
+		for _, aSeries := range df.Series {
+			fields = append(fields, field{Name: aSeries.Name(), Type: aSeries.Type()})
+		}
+
+		bw.WriteString(`{"schema":{"fields":`)
+		if err := value(fields); err != nil {
+			return err
+		}
+		bw.WriteString(`},"data":[`)
+		for row := s; row <= e; row++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if row > s {
+				bw.WriteByte(',')
+			}
+			if err := object(row); err != nil {
+				return err
+			}
+		}
+		bw.WriteString("]}")
+	default:
+		return errors.New("invalid Orient")
+	}
+
+	bw.WriteByte('\n')
+
+	return bw.Flush()
+}
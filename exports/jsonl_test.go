@@ -0,0 +1,104 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package exports
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+	"github.com/rocketlaunchr/dataframe-go/imports"
+)
+
+func TestExportToJSONOrients(t *testing.T) {
+	ctx := context.Background()
+
+	when := time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	df := dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("id", nil, 1, 2, nil),
+		dataframe.NewSeriesFloat64("price", nil, 1.5, nil, -2.25),
+		dataframe.NewSeriesTime("when", nil, when, nil, when.AddDate(0, 1, 0)),
+		dataframe.NewSeriesString("note", nil, "a", "NULL", nil),
+	)
+
+	// Without a dictated data type, the Series are loaded as strings
+	strs := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("id", nil, "1", "2", nil),
+		dataframe.NewSeriesString("price", nil, "1.5", nil, "-2.25"),
+		dataframe.NewSeriesString("when", nil, "2020-03-04T05:06:07Z", nil, "2020-04-04T05:06:07Z"),
+		dataframe.NewSeriesString("note", nil, "a", "NULL", nil),
+	)
+
+	dictate := map[string]interface{}{
+		"id":    int64(0),
+		"price": float64(0),
+		"when":  time.Time{},
+	}
+
+	tests := []struct {
+		exportOrient JSONOrient
+		importOrient imports.JSONOrient
+		typed        bool // Series types are restored without DictateDataType
+	}{
+		{OrientLines, imports.OrientLines, false},
+		{OrientRecords, imports.OrientRecords, false},
+		{OrientColumns, imports.OrientColumns, false},
+		{OrientSplit, imports.OrientSplit, false},
+		{OrientTable, imports.OrientTable, true},
+	}
+
+	for _, tc := range tests {
+		var buf bytes.Buffer
+		if err := ExportToJSON(ctx, &buf, df, JSONExportOptions{Orient: tc.exportOrient}); err != nil {
+			t.Fatalf("%d: %v", tc.exportOrient, err)
+		}
+		data := buf.Bytes()
+
+		for _, dictated := range []bool{false, true} {
+			opts := imports.JSONLoadOptions{Orient: tc.importOrient}
+			if dictated {
+				opts.DictateDataType = dictate
+			}
+
+			ldf, err := imports.LoadFromJSON(ctx, bytes.NewReader(data), opts)
+			if err != nil {
+				t.Errorf("%d (dictated: %v): %v", tc.exportOrient, dictated, err)
+				continue
+			}
+
+			expected := strs
+			if tc.typed || dictated {
+				expected = df
+			}
+
+			// OrientLines and OrientRecords don't maintain the order of the Series
+			eq, err := expected.IsEqual(ctx, ldf, dataframe.IsEqualOptions{IgnoreSeriesOrder: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !eq {
+				t.Errorf("%d (dictated: %v): round trip not equal:\n%s\n%s", tc.exportOrient, dictated, expected.Table(), ldf.Table())
+			}
+		}
+	}
+
+	// NullString and Range
+	null := "NA"
+	var buf bytes.Buffer
+	err := ExportToJSON(ctx, &buf, df, JSONExportOptions{Orient: OrientSplit, NullString: &null, Range: dataframe.RangeFinite(1, 2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"columns":["id","price","when","note"],"data":[[2,"NA","NA","NULL"],["NA",-2.25,"2020-04-04T05:06:07Z","NA"]]}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("wrong output. expected: %s actual: %s", expected, buf.String())
+	}
+
+	if err := ExportToJSON(ctx, &buf, df, JSONExportOptions{Orient: 10}); err == nil {
+		t.Errorf("expected error for invalid Orient")
+	}
+}
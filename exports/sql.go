@@ -15,6 +15,7 @@ import (
 
 // Database is used to set the Database.
 // Different databases have different syntax for placeholders etc.
+// Other databases can be supported using RegisterDialect.
 type Database int
 
 const (
@@ -22,6 +23,12 @@ const (
 	PostgreSQL Database = 0
 	// MySQL database
 	MySQL Database = 1
+	// SQLite database
+	SQLite Database = 2
+	// MSSQL (Microsoft SQL Server) database
+	MSSQL Database = 3
+	// Oracle database
+	Oracle Database = 4
 )
 
 type execContexter interface {
@@ -45,6 +52,9 @@ type SQLExportOptions struct {
 	// It is recommended a transaction is used so if 1 batch-insert fails, then all
 	// successfully inserted data can be rolled back.
 	// If set, it must not be 0.
+	//
+	// NOTE: Batches are automatically reduced so that each statement is within the limits of the Database's Dialect.
+	// eg. SQLite allows 999 placeholders and MSSQL allows 2100 parameters and 1000 rows.
 	BatchSize *uint
 
 	// SeriesToColumn is used to map the series name to the table's column name.
@@ -55,6 +65,30 @@ type SQLExportOptions struct {
 
 	// Database is used to set the Database.
 	Database Database
+
+	// WriteMode determines what happens when a row conflicts with an existing row.
+	// The default is Insert.
+	//
+	// NOTE: A Dialect registered with RegisterDialect must implement Upserter to support other write modes.
+	WriteMode WriteMode
+
+	// ConflictColumns are the column names that identify a row (i.e. they have a primary key or unique constraint).
+	// They are required for Upsert and Replace, and for InsertIgnore with MSSQL and Oracle.
+	// If not set, the PrimaryKey column is used.
+	//
+	// NOTE: MySQL ignores ConflictColumns and uses every primary key and unique constraint of the table.
+	ConflictColumns []string
+
+	// UpdateColumns are the column names that are updated when a row conflicts with an existing row in Upsert mode.
+	// If not set, every column that is not a conflict column is updated.
+	// An error is returned if there are no columns to update.
+	UpdateColumns []string
+
+	// BulkLoad is used to stream the rows using COPY FROM STDIN (PostgreSQL) or LOAD DATA LOCAL INFILE (MySQL)
+	// instead of INSERT statements. It is significantly faster for large Dataframes.
+	// BatchSize is ignored. For MySQL, only the Insert, InsertIgnore and Replace write modes are supported.
+	// For PostgreSQL, only Insert is supported.
+	BulkLoad *BulkLoad
 }
 
 // PrimaryKey is used to generate custom values for the primary key
@@ -71,7 +105,8 @@ type PrimaryKey struct {
 
 // ExportToSQL exports a Dataframe to a SQL Database.
 // It is assumed to be a PostgreSQL database (for placeholder purposes), unless
-// otherwise set to MySQL using the Options.
+// otherwise set to MySQL, SQLite, MSSQL, Oracle or a Database registered with RegisterDialect
+// using the Options.
 //
 // Example (gist):
 //
@@ -127,11 +162,15 @@ func ExportToSQL(ctx context.Context, db execContexter, df *dataframe.DataFrame,
 	defer df.Unlock()
 
 	var (
-		null      *string
-		r         dataframe.Range
-		pk        *PrimaryKey
-		batchSize *uint
-		database  Database
+		null            *string
+		r               dataframe.Range
+		pk              *PrimaryKey
+		batchSize       *uint
+		database        Database
+		mode            WriteMode
+		conflictColumns []string
+		updateColumns   []string
+		bulk            *BulkLoad
 	)
 
 	if tableName == "" {
@@ -155,9 +194,22 @@ func ExportToSQL(ctx context.Context, db execContexter, df *dataframe.DataFrame,
 			seriesToColumn = options[0].SeriesToColumn
 		}
 		database = options[0].Database
-		if database != PostgreSQL && database != MySQL {
-			return errors.New("invalid database")
+		mode = options[0].WriteMode
+		if mode < Insert || mode > Replace {
+			return errors.New("invalid WriteMode")
 		}
+		conflictColumns = options[0].ConflictColumns
+		updateColumns = options[0].UpdateColumns
+		bulk = options[0].BulkLoad
+	}
+
+	dialect, err := dialectFor(database)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := dialect.(Upserter); !ok && mode != Insert {
+		return errors.New("WriteMode not supported by database")
 	}
 
 	nRows := df.NRows(dataframe.DontLock)
@@ -194,6 +246,102 @@ func ExportToSQL(ctx context.Context, db execContexter, df *dataframe.DataFrame,
 		}
 	}
 
+	if bulk != nil {
+		fields := []sqlField{}
+		if pk != nil {
+			if pk.Value == nil {
+				// Let the database generate the primary key
+				columnNames = columnNames[1:]
+			} else {
+				fields = append(fields, sqlField{value: func(row int) *string { return pk.Value(row, nRows) }})
+			}
+		}
+
+		for _, series := range df.Series {
+			colName, exists := seriesToColumn[series.Name()]
+			if exists && colName == nil {
+				// Ignore column
+				continue
+			}
+
+			series := series
+			fields = append(fields, sqlField{series: series, value: func(row int) *string { return sqlValue(series, row, null) }})
+		}
+
+		return sqlBulk(ctx, db, bulk, database, dialect, mode, tableName, columnNames, fields, start, end)
+	}
+
+	// Determine the columns used to resolve conflicts
+	if mode != Insert {
+		exists := map[string]bool{}
+		for _, col := range columnNames {
+			exists[col] = true
+		}
+
+		if conflictColumns == nil && pk != nil {
+			conflictColumns = []string{pk.PrimaryKey}
+		}
+
+		conflicts := map[string]bool{}
+		for _, col := range conflictColumns {
+			if !exists[col] {
+				return fmt.Errorf("conflict column not found: %s", col)
+			}
+			conflicts[col] = true
+		}
+
+		if updateColumns == nil || mode == Replace {
+			updateColumns = []string{}
+			for _, col := range columnNames {
+				if !conflicts[col] {
+					updateColumns = append(updateColumns, col)
+				}
+			}
+		} else {
+			for _, col := range updateColumns {
+				if !exists[col] {
+					return fmt.Errorf("update column not found: %s", col)
+				}
+			}
+		}
+
+		if len(updateColumns) == 0 {
+			if mode == Upsert {
+				return errors.New("Upsert requires at least one column to update")
+			}
+			// Every column identifies the row, so replacing it changes nothing
+			mode = InsertIgnore
+		}
+	}
+
+	stmt := sqlStmt{
+		dialect:         dialect,
+		mode:            mode,
+		tableName:       dialect.EscapeName(tableName),
+		columnNames:     escapeNames(dialect, columnNames),
+		conflictColumns: escapeNames(dialect, conflictColumns),
+		updateColumns:   escapeNames(dialect, updateColumns),
+	}
+
+	// Check the statement can be generated before anything is written
+	if _, err := stmt.build(1); err != nil {
+		return err
+	}
+
+	// Limit the batch size to the maximum number of placeholders and rows allowed
+	maxRows := dialect.MaxRows()
+	if max := dialect.MaxVariables(); max > 0 {
+		if len(columnNames) > max {
+			return fmt.Errorf("too many columns: %d", len(columnNames))
+		}
+		if maxRows == 0 || max/len(columnNames) < maxRows {
+			maxRows = max / len(columnNames)
+		}
+	}
+	if maxRows > 0 && (batchSize == nil || *batchSize > uint(maxRows)) {
+		batchSize = &[]uint{uint(maxRows)}[0]
+	}
+
 	var (
 		batchData  []interface{}
 		batchCount uint
@@ -219,34 +367,18 @@ func ExportToSQL(ctx context.Context, db execContexter, df *dataframe.DataFrame,
 		}
 
 		for _, series := range df.Series {
-			val := series.Value(row, dataframe.DontLock)
-
 			colName, exists := seriesToColumn[series.Name()]
 			if exists && colName == nil {
 				// Ignore column
 				continue
 			}
 
-			var ival *string
-			if val == nil {
-				if null != nil {
-					ival = null
-				}
-			} else {
-				switch v := val.(type) {
-				case time.Time:
-					ival = &[]string{v.Format("2006-01-02 15:04:05")}[0]
-				default:
-					ival = &[]string{series.ValueString(row, dataframe.DontLock)}[0]
-				}
-			}
-
-			batchData = append(batchData, ival)
+			batchData = append(batchData, sqlValue(series, row, null))
 		}
 
 		if batchSize != nil && batchCount == *batchSize {
 			// Now insert data to table
-			err := sqlInsert(ctx, db, database, tableName, columnNames, batchData)
+			err := sqlInsert(ctx, db, stmt, batchData)
 			if err != nil {
 				return err
 			}
@@ -259,7 +391,7 @@ func ExportToSQL(ctx context.Context, db execContexter, df *dataframe.DataFrame,
 
 	// Insert the remaining data into table
 	if len(batchData) > 0 {
-		err := sqlInsert(ctx, db, database, tableName, columnNames, batchData)
+		err := sqlInsert(ctx, db, stmt, batchData)
 		if err != nil {
 			return err
 		}
@@ -268,59 +400,72 @@ func ExportToSQL(ctx context.Context, db execContexter, df *dataframe.DataFrame,
 	return nil
 }
 
-func sqlInsert(ctx context.Context, db execContexter, database Database, tableName string, columnNames []string, batchData []interface{}) error {
+// sqlValue returns the value that is inserted for a given row.
+func sqlValue(series dataframe.Series, row int, null *string) *string {
+	val := series.Value(row, dataframe.DontLock)
+	if val == nil {
+		return null
+	}
 
-	tableName = strings.Join(escapeNames(database, []string{tableName}), ",")
-	columns := strings.Join(escapeNames(database, columnNames), ",")
-	placeholders := placeholders(database, columnNames, len(batchData)/len(columnNames))
+	switch v := val.(type) {
+	case time.Time:
+		return &[]string{v.Format("2006-01-02 15:04:05")}[0]
+	default:
+		return &[]string{series.ValueString(row, dataframe.DontLock)}[0]
+	}
+}
 
-	stmt := "INSERT INTO " + tableName + " (" + columns + ") VALUES " + placeholders
+// sqlStmt generates the statement that writes a batch of rows.
+// The names are escaped.
+type sqlStmt struct {
+	dialect         Dialect
+	mode            WriteMode
+	tableName       string
+	columnNames     []string
+	conflictColumns []string
+	updateColumns   []string
+}
 
-	_, err := db.ExecContext(ctx, stmt, batchData...)
-	if err != nil {
-		return err
+func (s sqlStmt) build(rows int) (string, error) {
+
+	if s.mode != Insert {
+		return s.dialect.(Upserter).UpsertStmt(s.mode, s.tableName, s.columnNames, rows, s.conflictColumns, s.updateColumns)
 	}
 
-	return nil
+	if ins, ok := s.dialect.(Inserter); ok {
+		return ins.InsertStmt(s.tableName, s.columnNames, rows), nil
+	}
+
+	return "INSERT INTO " + s.tableName + " (" + strings.Join(s.columnNames, ",") + ") VALUES " + placeholders(s.dialect, s.columnNames, rows), nil
 }
 
-func placeholders(dbtype Database, fields []string, rows int) string {
+func sqlInsert(ctx context.Context, db execContexter, s sqlStmt, batchData []interface{}) error {
 
-	if dbtype == MySQL {
-		inner := "( " + strings.TrimSuffix(strings.Repeat("?,", len(fields)), ",") + " ),"
-		return strings.TrimSuffix(strings.Repeat(inner, rows), ",")
+	stmt, err := s.build(len(batchData) / len(s.columnNames))
+	if err != nil {
+		return err
 	}
 
-	var singleValuesStr string
-
-	varCount := 1
-	for i := 1; i <= rows; i++ {
-		singleValuesStr = singleValuesStr + "("
-		for j := 1; j <= len(fields); j++ {
-			singleValuesStr = singleValuesStr + fmt.Sprintf("$%d,", varCount)
-			varCount++
-		}
-		singleValuesStr = strings.TrimSuffix(singleValuesStr, ",") + "),"
+	_, err = db.ExecContext(ctx, stmt, batchData...)
+	if err != nil {
+		return err
 	}
 
-	return strings.TrimSuffix(singleValuesStr, ",")
+	return nil
 }
 
-func escapeNames(database Database, names []string) []string {
+func placeholders(dialect Dialect, fields []string, rows int) string {
 	out := []string{}
-
-	switch database {
-	case MySQL:
-		for _, v := range names {
-			out = append(out, fmt.Sprintf("`%s`", v))
-		}
-	case PostgreSQL:
-		for _, v := range names {
-			out = append(out, fmt.Sprintf("\"%s\"", v))
-		}
-	default:
-		out = names
+	for i := 0; i < rows; i++ {
+		out = append(out, valuesList(dialect, len(fields), i*len(fields)))
 	}
+	return strings.Join(out, ",")
+}
 
+func escapeNames(dialect Dialect, names []string) []string {
+	out := []string{}
+	for _, v := range names {
+		out = append(out, dialect.EscapeName(v))
+	}
 	return out
 }
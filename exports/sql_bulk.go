@@ -0,0 +1,260 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package exports
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+// BulkLoad is used to stream the rows using the database's bulk loading mechanism instead of
+// INSERT statements. It is supported by PostgreSQL (COPY FROM STDIN) and MySQL (LOAD DATA LOCAL INFILE).
+//
+// The rows are encoded as csv with every non-nil value quoted, so that a string such as "NULL" is never
+// loaded as NULL. A nil value is loaded as NULL unless NullString is set (as per INSERT statements). A PrimaryKey column without a Value function is omitted
+// so that the database generates the value.
+type BulkLoad struct {
+
+	// CopyFrom is required for PostgreSQL. It must execute stmt (a COPY ... FROM STDIN statement)
+	// using r as the data.
+	//
+	// Example (pgx):
+	//
+	//  CopyFrom: func(ctx context.Context, stmt string, r io.Reader) error {
+	//     _, err := conn.PgConn().CopyFrom(ctx, r, stmt)
+	//     return err
+	//  }
+	//
+	CopyFrom func(ctx context.Context, stmt string, r io.Reader) error
+
+	// Binary will use PostgreSQL's binary format instead of csv.
+	// The table's columns must be BIGINT for SeriesInt64, FLOAT8 for SeriesFloat64 and
+	// TIMESTAMP for SeriesTime (see CreateSQLTable). Other Series must be stored in text columns.
+	//
+	// NOTE: NullString is ignored.
+	Binary bool
+
+	// RegisterReaderHandler is required for MySQL. It must be set to RegisterReaderHandler
+	// from github.com/go-sql-driver/mysql.
+	RegisterReaderHandler func(name string, handler func() io.Reader)
+
+	// DeregisterReaderHandler is required for MySQL. It must be set to DeregisterReaderHandler
+	// from github.com/go-sql-driver/mysql.
+	DeregisterReaderHandler func(name string)
+}
+
+// readerHandlerCount is used to generate unique names for MySQL reader handlers.
+var readerHandlerCount uint64
+
+// sqlField is a column that is bulk loaded.
+type sqlField struct {
+	series dataframe.Series // nil for the PrimaryKey column
+	value  func(row int) *string
+}
+
+func sqlBulk(ctx context.Context, db execContexter, bulk *BulkLoad, database Database, dialect Dialect, mode WriteMode, tableName string, columnNames []string, fields []sqlField, s, e int) error {
+
+	if len(columnNames) == 0 {
+		return errors.New("no series found")
+	}
+
+	var load func(r io.Reader) error
+
+	tableName = dialect.EscapeName(tableName)
+	columns := strings.Join(escapeNames(dialect, columnNames), ",")
+
+	switch database {
+	case PostgreSQL:
+		if bulk.CopyFrom == nil {
+			return errors.New("CopyFrom must be set")
+		}
+		if mode != Insert {
+			return errors.New("WriteMode not supported by BulkLoad")
+		}
+
+		stmt := "COPY " + tableName + " (" + columns + ") FROM STDIN WITH (FORMAT csv, NULL '\\N')"
+		if bulk.Binary {
+			stmt = "COPY " + tableName + " (" + columns + ") FROM STDIN WITH (FORMAT binary)"
+		}
+
+		load = func(r io.Reader) error {
+			return bulk.CopyFrom(ctx, stmt, r)
+		}
+	case MySQL:
+		if bulk.RegisterReaderHandler == nil || bulk.DeregisterReaderHandler == nil {
+			return errors.New("RegisterReaderHandler and DeregisterReaderHandler must be set")
+		}
+		if bulk.Binary {
+			return errors.New("Binary not supported by database")
+		}
+
+		var modifier string
+		switch mode {
+		case InsertIgnore:
+			modifier = " IGNORE"
+		case Replace:
+			modifier = " REPLACE"
+		case Upsert:
+			return errors.New("WriteMode not supported by BulkLoad")
+		}
+
+		name := "dataframe-go-" + strconv.FormatUint(atomic.AddUint64(&readerHandlerCount, 1), 10)
+		stmt := "LOAD DATA LOCAL INFILE 'Reader::" + name + "'" + modifier + " INTO TABLE " + tableName +
+			" CHARACTER SET utf8mb4 FIELDS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '\"' ESCAPED BY '' LINES TERMINATED BY '\\n' (" + columns + ")"
+
+		load = func(r io.Reader) error {
+			bulk.RegisterReaderHandler(name, func() io.Reader { return r })
+			defer bulk.DeregisterReaderHandler(name)
+
+			_, err := db.ExecContext(ctx, stmt)
+			return err
+		}
+	default:
+		return errors.New("BulkLoad not supported by database")
+	}
+
+	// Stream the data
+	pr, pw := io.Pipe()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		var err error
+		if bulk.Binary {
+			err = writePGBinary(ctx, pw, fields, s, e)
+		} else {
+			// NULL is written as \N for PostgreSQL (see COPY statement) and NULL for MySQL.
+			nullString := "NULL"
+			if database == PostgreSQL {
+				nullString = `\N`
+			}
+			err = writeBulkCSV(ctx, pw, fields, nullString, s, e)
+		}
+		pw.CloseWithError(err)
+	}()
+
+	err := load(pr)
+	pr.CloseWithError(errors.New("bulk load finished")) // Unblock the writer if not all the data was read
+	<-done
+
+	return err
+}
+
+// writeBulkCSV writes rows s to e in csv format. nil values are written as the unquoted nullString.
+// Every other value is quoted so that it is never mistaken for NULL (eg. the string "NULL").
+func writeBulkCSV(ctx context.Context, w io.Writer, fields []sqlField, nullString string, s, e int) error {
+
+	bw := bufio.NewWriter(w)
+
+	for row := s; row <= e; row++ {
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		for i, f := range fields {
+			if i > 0 {
+				bw.WriteByte(',')
+			}
+
+			val := f.value(row)
+			if val == nil {
+				bw.WriteString(nullString)
+				continue
+			}
+
+			bw.WriteByte('"')
+			bw.WriteString(strings.Replace(*val, `"`, `""`, -1))
+			bw.WriteByte('"')
+		}
+
+		if err := bw.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// pgEpoch is the epoch (as a unix timestamp) used by PostgreSQL's binary timestamp format.
+const pgEpoch = 946684800 // 2000-01-01 00:00:00 UTC
+
+// writePGBinary writes rows s to e using PostgreSQL's binary COPY format.
+//
+// See: https://www.postgresql.org/docs/current/sql-copy.html#id-1.9.3.55.9.4
+func writePGBinary(ctx context.Context, w io.Writer, fields []sqlField, s, e int) error {
+
+	bw := bufio.NewWriter(w)
+
+	// Header: signature, flags and header extension length
+	bw.WriteString("PGCOPY\n\377\r\n\x00")
+	binary.Write(bw, binary.BigEndian, int32(0))
+	binary.Write(bw, binary.BigEndian, int32(0))
+
+	buf := make([]byte, 8)
+
+	for row := s; row <= e; row++ {
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		binary.Write(bw, binary.BigEndian, int16(len(fields)))
+
+		for _, f := range fields {
+
+			var val interface{}
+			switch f.series.(type) {
+			case *dataframe.SeriesInt64, *dataframe.SeriesFloat64, *dataframe.SeriesTime:
+				val = f.series.Value(row, dataframe.DontLock)
+			default:
+				// Text
+				if f.series == nil {
+					if v := f.value(row); v != nil {
+						val = *v
+					}
+				} else if f.series.Value(row, dataframe.DontLock) != nil {
+					val = f.series.ValueString(row, dataframe.DontLock)
+				}
+			}
+
+			switch v := val.(type) {
+			case nil:
+				binary.Write(bw, binary.BigEndian, int32(-1))
+				continue
+			case string:
+				binary.Write(bw, binary.BigEndian, int32(len(v)))
+				bw.WriteString(v)
+				continue
+			case int64:
+				binary.BigEndian.PutUint64(buf, uint64(v))
+			case float64:
+				binary.BigEndian.PutUint64(buf, math.Float64bits(v))
+			case time.Time:
+				// TIMESTAMP (without time zone) stores the wall clock time
+				wall := time.Date(v.Year(), v.Month(), v.Day(), v.Hour(), v.Minute(), v.Second(), v.Nanosecond(), time.UTC)
+				micro := (wall.Unix()-pgEpoch)*1000000 + int64(wall.Nanosecond()/1000)
+				binary.BigEndian.PutUint64(buf, uint64(micro))
+			}
+
+			binary.Write(bw, binary.BigEndian, int32(8))
+			bw.Write(buf)
+		}
+	}
+
+	// Trailer
+	binary.Write(bw, binary.BigEndian, int16(-1))
+
+	return bw.Flush()
+}
@@ -0,0 +1,373 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package exports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+// SQLType is the generic data type of a column.
+type SQLType int
+
+const (
+	// SQLString is used for SeriesString and any Series that is not listed below.
+	SQLString SQLType = 0
+
+	// SQLInt64 is used for SeriesInt64.
+	SQLInt64 SQLType = 1
+
+	// SQLFloat64 is used for SeriesFloat64.
+	SQLFloat64 SQLType = 2
+
+	// SQLTime is used for SeriesTime.
+	SQLTime SQLType = 3
+
+	// SQLAutoIncrement is used for a PrimaryKey column that does not exist in the Dataframe
+	// and has no Value function.
+	SQLAutoIncrement SQLType = 4
+)
+
+// TableCreator can optionally be implemented by a Dialect to support CreateSQLTable.
+type TableCreator interface {
+
+	// TypeName returns the column type for typ.
+	// For SQLString, size is the maximum length (in characters) of the values.
+	TypeName(typ SQLType, size int) string
+
+	// CreateTableStmt returns the statement that creates tableName.
+	// definitions contains the column definitions followed by the table constraints.
+	// tableName is already escaped.
+	CreateTableStmt(tableName string, definitions []string, ifNotExists bool) string
+}
+
+// SQLTableOptions contains options for CreateSQLTable and CreateSQLTableStmt functions.
+type SQLTableOptions struct {
+
+	// PrimaryKey is used to set the primary key of the table.
+	// If the column does not exist in the Dataframe, it is added (as per ExportToSQL). When Value
+	// is not set, the column is auto-incrementing.
+	PrimaryKey *PrimaryKey
+
+	// SeriesToColumn is used to map the series name to the table's column name.
+	// It follows the same conventions as SQLExportOptions.
+	SeriesToColumn map[string]*string
+
+	// ColumnTypes is used to override the type of a column.
+	// The key of the map is the column name. eg. "price": "NUMERIC(10,2)".
+	ColumnTypes map[string]string
+
+	// IfNotExists will only create the table if it does not already exist.
+	//
+	// NOTE: For Oracle, version 23ai or above is required.
+	IfNotExists bool
+
+	// Database is used to set the Database.
+	Database Database
+}
+
+// CreateSQLTable creates a table that can store the Dataframe.
+// See CreateSQLTableStmt for how the statement is generated.
+//
+// Example:
+//
+//  opts := exports.SQLTableOptions{Database: exports.MySQL, IfNotExists: true}
+//
+//  err := exports.CreateSQLTable(ctx, tx, df, "test", opts)
+//  if err != nil {
+//  	return err
+//  }
+//
+//  err = exports.ExportToSQL(ctx, tx, df, "test", exports.SQLExportOptions{Database: exports.MySQL})
+//
+func CreateSQLTable(ctx context.Context, db execContexter, df *dataframe.DataFrame, tableName string, options ...SQLTableOptions) error {
+
+	stmt, err := CreateSQLTableStmt(ctx, df, tableName, options...)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, stmt)
+	return err
+}
+
+// CreateSQLTableStmt returns a CREATE TABLE statement for a table that can store the Dataframe.
+// The type of each column is determined by the Series type. For strings, the length of the longest
+// value is used to select the type. A column is NOT NULL if the Series contains values and
+// none of them are nil.
+func CreateSQLTableStmt(ctx context.Context, df *dataframe.DataFrame, tableName string, options ...SQLTableOptions) (string, error) {
+
+	df.Lock()
+	defer df.Unlock()
+
+	var opts SQLTableOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	if tableName == "" {
+		return "", errors.New("invalid tableName")
+	}
+
+	pk := opts.PrimaryKey
+	if pk != nil && pk.PrimaryKey == "" {
+		return "", errors.New("invalid primary key name")
+	}
+
+	dialect, err := dialectFor(opts.Database)
+	if err != nil {
+		return "", err
+	}
+
+	tc, ok := dialect.(TableCreator)
+	if !ok {
+		return "", errors.New("CreateSQLTable not supported by database")
+	}
+
+	nRows := df.NRows(dataframe.DontLock)
+
+	definitions := []string{}
+	columnNames := []string{}
+	pkExists := false
+
+	for _, series := range df.Series {
+
+		colName, exists := opts.SeriesToColumn[series.Name()]
+		if exists && colName == nil {
+			// Ignore column
+			continue
+		}
+
+		name := series.Name()
+		if exists {
+			name = *colName
+		}
+		columnNames = append(columnNames, name)
+
+		if pk != nil && name == pk.PrimaryKey {
+			pkExists = true
+		}
+
+		typ, found := opts.ColumnTypes[name]
+		if !found {
+			typ, err = seriesTypeName(ctx, tc, series)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		def := dialect.EscapeName(name) + " " + typ
+
+		if nRows > 0 {
+			nils, err := series.NilCount(dataframe.NilCountOptions{Ctx: ctx, DontLock: true, StopAtOneNil: true})
+			if err != nil {
+				return "", err
+			}
+			if nils == 0 {
+				def = def + " NOT NULL"
+			}
+		}
+
+		definitions = append(definitions, def)
+	}
+
+	if pk != nil {
+		if !pkExists {
+			// Add primary key column
+			typ, found := opts.ColumnTypes[pk.PrimaryKey]
+			if !found {
+				if pk.Value == nil {
+					typ = tc.TypeName(SQLAutoIncrement, 0)
+				} else {
+					typ = tc.TypeName(SQLString, 255)
+				}
+			}
+			definitions = append([]string{dialect.EscapeName(pk.PrimaryKey) + " " + typ}, definitions...)
+		}
+		definitions = append(definitions, "PRIMARY KEY ("+dialect.EscapeName(pk.PrimaryKey)+")")
+	}
+
+	if len(definitions) == 0 {
+		return "", errors.New("no series found")
+	}
+
+	// Check for overrides that don't match a column
+	for name := range opts.ColumnTypes {
+		found := pk != nil && name == pk.PrimaryKey
+		for _, col := range columnNames {
+			if col == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("column not found: %s", name)
+		}
+	}
+
+	return tc.CreateTableStmt(dialect.EscapeName(tableName), definitions, opts.IfNotExists), nil
+}
+
+// seriesTypeName returns the column type for a Series.
+func seriesTypeName(ctx context.Context, tc TableCreator, s dataframe.Series) (string, error) {
+
+	switch s.(type) {
+	case *dataframe.SeriesInt64:
+		return tc.TypeName(SQLInt64, 0), nil
+	case *dataframe.SeriesFloat64:
+		return tc.TypeName(SQLFloat64, 0), nil
+	case *dataframe.SeriesTime:
+		return tc.TypeName(SQLTime, 0), nil
+	}
+
+	// Determine the length of the longest value
+	max := 0
+	nRows := s.NRows(dataframe.DontLock)
+	for row := 0; row < nRows; row++ {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		if s.Value(row, dataframe.DontLock) == nil {
+			continue
+		}
+
+		if n := utf8.RuneCountInString(s.ValueString(row, dataframe.DontLock)); n > max {
+			max = n
+		}
+	}
+
+	return tc.TypeName(SQLString, max), nil
+}
+
+// createTable returns a standard CREATE TABLE statement.
+func createTable(tableName string, definitions []string, ifNotExists bool) string {
+	stmt := "CREATE TABLE "
+	if ifNotExists {
+		stmt = stmt + "IF NOT EXISTS "
+	}
+	return stmt + tableName + " (" + strings.Join(definitions, ", ") + ")"
+}
+
+func (postgreSQLDialect) TypeName(typ SQLType, size int) string {
+	switch typ {
+	case SQLInt64:
+		return "BIGINT"
+	case SQLFloat64:
+		return "FLOAT8"
+	case SQLTime:
+		return "TIMESTAMP"
+	case SQLAutoIncrement:
+		return "BIGSERIAL"
+	default:
+		if size == 0 {
+			return "TEXT"
+		}
+		return "VARCHAR(" + strconv.Itoa(size) + ")"
+	}
+}
+
+func (postgreSQLDialect) CreateTableStmt(tableName string, definitions []string, ifNotExists bool) string {
+	return createTable(tableName, definitions, ifNotExists)
+}
+
+func (mySQLDialect) TypeName(typ SQLType, size int) string {
+	switch typ {
+	case SQLInt64:
+		return "BIGINT"
+	case SQLFloat64:
+		return "DOUBLE"
+	case SQLTime:
+		return "DATETIME"
+	case SQLAutoIncrement:
+		return "BIGINT AUTO_INCREMENT"
+	default:
+		switch {
+		case size == 0:
+			return "TEXT"
+		case size <= 255:
+			return "VARCHAR(" + strconv.Itoa(size) + ")"
+		case size <= 16383:
+			// TEXT stores up to 65,535 bytes (4 bytes per character for utf8mb4)
+			return "TEXT"
+		default:
+			return "LONGTEXT"
+		}
+	}
+}
+
+func (mySQLDialect) CreateTableStmt(tableName string, definitions []string, ifNotExists bool) string {
+	return createTable(tableName, definitions, ifNotExists)
+}
+
+func (sqliteDialect) TypeName(typ SQLType, size int) string {
+	switch typ {
+	case SQLInt64, SQLAutoIncrement:
+		// An INTEGER PRIMARY KEY column is an alias for the ROWID
+		return "INTEGER"
+	case SQLFloat64:
+		return "REAL"
+	case SQLTime:
+		return "DATETIME"
+	default:
+		return "TEXT"
+	}
+}
+
+func (sqliteDialect) CreateTableStmt(tableName string, definitions []string, ifNotExists bool) string {
+	return createTable(tableName, definitions, ifNotExists)
+}
+
+func (mssqlDialect) TypeName(typ SQLType, size int) string {
+	switch typ {
+	case SQLInt64:
+		return "BIGINT"
+	case SQLFloat64:
+		return "FLOAT"
+	case SQLTime:
+		return "DATETIME2"
+	case SQLAutoIncrement:
+		return "BIGINT IDENTITY(1,1)"
+	default:
+		if size == 0 || size > 4000 {
+			return "NVARCHAR(MAX)"
+		}
+		return "NVARCHAR(" + strconv.Itoa(size) + ")"
+	}
+}
+
+func (mssqlDialect) CreateTableStmt(tableName string, definitions []string, ifNotExists bool) string {
+	stmt := createTable(tableName, definitions, false)
+	if ifNotExists {
+		return "IF OBJECT_ID(N'" + strings.Replace(tableName, "'", "''", -1) + "', N'U') IS NULL " + stmt
+	}
+	return stmt
+}
+
+func (oracleDialect) TypeName(typ SQLType, size int) string {
+	switch typ {
+	case SQLInt64:
+		return "NUMBER(19)"
+	case SQLFloat64:
+		return "BINARY_DOUBLE"
+	case SQLTime:
+		return "TIMESTAMP"
+	case SQLAutoIncrement:
+		return "NUMBER(19) GENERATED BY DEFAULT ON NULL AS IDENTITY"
+	default:
+		if size == 0 || size > 4000 {
+			return "CLOB"
+		}
+		return "VARCHAR2(" + strconv.Itoa(size) + " CHAR)"
+	}
+}
+
+func (oracleDialect) CreateTableStmt(tableName string, definitions []string, ifNotExists bool) string {
+	return createTable(tableName, definitions, ifNotExists)
+}
@@ -0,0 +1,149 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package exports
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Dialect describes the syntax of a database.
+// A Dialect can be registered for a custom Database using RegisterDialect.
+type Dialect interface {
+
+	// Placeholder returns the placeholder for the nth argument of a statement.
+	// The first argument is 1.
+	Placeholder(n int) string
+
+	// EscapeName quotes an identifier such as a table or column name.
+	EscapeName(name string) string
+
+	// MaxVariables returns the maximum number of placeholders allowed in a single statement.
+	// 0 means there is no limit.
+	MaxVariables() int
+
+	// MaxRows returns the maximum number of rows that can be inserted by a single statement.
+	// 0 means there is no limit.
+	MaxRows() int
+}
+
+// Inserter can optionally be implemented by a Dialect that does not support
+// multi-row "INSERT INTO ... VALUES (...), (...)" statements.
+type Inserter interface {
+
+	// InsertStmt returns the statement that inserts rows into tableName.
+	// tableName and columnNames are already escaped.
+	InsertStmt(tableName string, columnNames []string, rows int) string
+}
+
+var (
+	dialectsMu sync.RWMutex
+	dialects   = map[Database]Dialect{
+		PostgreSQL: postgreSQLDialect{},
+		MySQL:      mySQLDialect{},
+		SQLite:     sqliteDialect{},
+		MSSQL:      mssqlDialect{},
+		Oracle:     oracleDialect{},
+	}
+)
+
+// RegisterDialect registers a Dialect for a Database.
+// It is used to add databases that are not supported out of the box.
+// It can also be used to override a built-in Dialect.
+//
+// Example:
+//
+//  const Snowflake exports.Database = 100
+//
+//  exports.RegisterDialect(Snowflake, snowflakeDialect{})
+//
+//  exports.ExportToSQL(ctx, db, df, "test", exports.SQLExportOptions{Database: Snowflake})
+//
+func RegisterDialect(database Database, d Dialect) {
+	dialectsMu.Lock()
+	defer dialectsMu.Unlock()
+	dialects[database] = d
+}
+
+func dialectFor(database Database) (Dialect, error) {
+	dialectsMu.RLock()
+	d, exists := dialects[database]
+	dialectsMu.RUnlock()
+
+	if !exists {
+		return nil, errors.New("invalid database")
+	}
+	return d, nil
+}
+
+type postgreSQLDialect struct{}
+
+func (postgreSQLDialect) Placeholder(n int) string      { return "$" + strconv.Itoa(n) }
+func (postgreSQLDialect) EscapeName(name string) string { return `"` + name + `"` }
+func (postgreSQLDialect) MaxVariables() int             { return 0 }
+func (postgreSQLDialect) MaxRows() int                  { return 0 }
+
+type mySQLDialect struct{}
+
+func (mySQLDialect) Placeholder(n int) string      { return "?" }
+func (mySQLDialect) EscapeName(name string) string { return "`" + name + "`" }
+func (mySQLDialect) MaxVariables() int             { return 0 }
+func (mySQLDialect) MaxRows() int                  { return 0 }
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Placeholder(n int) string      { return "?" }
+func (sqliteDialect) EscapeName(name string) string { return `"` + name + `"` }
+func (sqliteDialect) MaxRows() int                  { return 0 }
+
+// MaxVariables returns SQLITE_MAX_VARIABLE_NUMBER, which defaults to 999 prior to SQLite 3.32.0.
+func (sqliteDialect) MaxVariables() int { return 999 }
+
+type mssqlDialect struct{}
+
+func (mssqlDialect) Placeholder(n int) string      { return "@p" + strconv.Itoa(n) }
+func (mssqlDialect) EscapeName(name string) string { return "[" + name + "]" }
+
+// MaxVariables returns the maximum number of parameters in a request.
+func (mssqlDialect) MaxVariables() int { return 2100 }
+
+// MaxRows returns the maximum number of row value expressions in an INSERT statement.
+func (mssqlDialect) MaxRows() int { return 1000 }
+
+type oracleDialect struct{}
+
+func (oracleDialect) Placeholder(n int) string      { return ":" + strconv.Itoa(n) }
+func (oracleDialect) EscapeName(name string) string { return `"` + name + `"` }
+func (oracleDialect) MaxVariables() int             { return 65535 }
+func (oracleDialect) MaxRows() int                  { return 0 }
+
+// InsertStmt returns an INSERT ALL statement since Oracle does not support multi-row VALUES.
+func (d oracleDialect) InsertStmt(tableName string, columnNames []string, rows int) string {
+	into := " INTO " + tableName + " (" + strings.Join(columnNames, ",") + ") VALUES "
+
+	var sb strings.Builder
+	sb.WriteString("INSERT ALL")
+	for i := 0; i < rows; i++ {
+		sb.WriteString(into)
+		sb.WriteString(valuesList(d, len(columnNames), i*len(columnNames)))
+	}
+	sb.WriteString(" SELECT 1 FROM DUAL")
+
+	return sb.String()
+}
+
+// valuesList returns the placeholders for a single row. offset is the number of preceding arguments.
+func valuesList(d Dialect, fields int, offset int) string {
+	var sb strings.Builder
+	sb.WriteString("(")
+	for j := 1; j <= fields; j++ {
+		if j > 1 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(d.Placeholder(offset + j))
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
@@ -0,0 +1,443 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package exports
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+// execRecorder records the statements executed by ExportToSQL.
+type execRecorder struct {
+	stmts []string
+	args  [][]interface{}
+}
+
+func (e *execRecorder) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	e.stmts = append(e.stmts, query)
+	e.args = append(e.args, args)
+	return nil, nil
+}
+
+type customDialect struct{}
+
+func (customDialect) Placeholder(n int) string      { return "%s" }
+func (customDialect) EscapeName(name string) string { return strings.ToUpper(name) }
+func (customDialect) MaxVariables() int             { return 5 }
+func (customDialect) MaxRows() int                  { return 0 }
+
+func TestExportToSQLDialects(t *testing.T) {
+	ctx := context.Background()
+
+	const custom Database = 100
+	RegisterDialect(custom, customDialect{})
+
+	df := dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("id", nil, 1, 2, 3),
+		dataframe.NewSeriesString("name", nil, "a", nil, "c"),
+	)
+
+	tests := []struct {
+		database Database
+		expected []string
+	}{
+		{PostgreSQL, []string{`INSERT INTO "test" ("id","name") VALUES ($1,$2),($3,$4),($5,$6)`}},
+		{MySQL, []string{"INSERT INTO `test` (`id`,`name`) VALUES (?,?),(?,?),(?,?)"}},
+		{SQLite, []string{`INSERT INTO "test" ("id","name") VALUES (?,?),(?,?),(?,?)`}},
+		{MSSQL, []string{`INSERT INTO [test] ([id],[name]) VALUES (@p1,@p2),(@p3,@p4),(@p5,@p6)`}},
+		{Oracle, []string{`INSERT ALL INTO "test" ("id","name") VALUES (:1,:2) INTO "test" ("id","name") VALUES (:3,:4) INTO "test" ("id","name") VALUES (:5,:6) SELECT 1 FROM DUAL`}},
+		{custom, []string{`INSERT INTO TEST (ID,NAME) VALUES (%s,%s),(%s,%s)`, `INSERT INTO TEST (ID,NAME) VALUES (%s,%s)`}},
+	}
+
+	for _, tc := range tests {
+		rec := &execRecorder{}
+
+		err := ExportToSQL(ctx, rec, df, "test", SQLExportOptions{Database: tc.database})
+		if err != nil {
+			t.Fatalf("%d: %v", tc.database, err)
+		}
+
+		if strings.Join(rec.stmts, "\n") != strings.Join(tc.expected, "\n") {
+			t.Errorf("%d: expected:\n%s\nactual:\n%s", tc.database, strings.Join(tc.expected, "\n"), strings.Join(rec.stmts, "\n"))
+		}
+	}
+
+	err := ExportToSQL(ctx, &execRecorder{}, df, "test", SQLExportOptions{Database: 99})
+	if err == nil {
+		t.Errorf("expected error for unregistered database")
+	}
+}
+
+func TestExportToSQLMSSQLLimits(t *testing.T) {
+	ctx := context.Background()
+
+	vals := make([]interface{}, 2500)
+	for i := range vals {
+		vals[i] = i
+	}
+
+	tests := []struct {
+		seriess  []dataframe.Series
+		expected []int // number of rows in each statement
+	}{
+		// 1000 rows per statement
+		{[]dataframe.Series{dataframe.NewSeriesInt64("a", nil, vals...)}, []int{1000, 1000, 500}},
+		// 2100 parameters per statement
+		{[]dataframe.Series{dataframe.NewSeriesInt64("a", nil, vals...), dataframe.NewSeriesInt64("b", nil, vals...), dataframe.NewSeriesInt64("c", nil, vals...)}, []int{700, 700, 700, 400}},
+	}
+
+	for i, tc := range tests {
+		rec := &execRecorder{}
+
+		err := ExportToSQL(ctx, rec, dataframe.NewDataFrame(tc.seriess...), "test", SQLExportOptions{Database: MSSQL})
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+
+		actual := []int{}
+		for _, args := range rec.args {
+			actual = append(actual, len(args)/len(tc.seriess))
+		}
+
+		if len(actual) != len(tc.expected) {
+			t.Fatalf("%d: expected %v actual %v", i, tc.expected, actual)
+		}
+		for j := range actual {
+			if actual[j] != tc.expected[j] {
+				t.Errorf("%d: expected %v actual %v", i, tc.expected, actual)
+				break
+			}
+		}
+	}
+}
+
+func TestExportToSQLWriteModes(t *testing.T) {
+	ctx := context.Background()
+
+	df := dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("id", nil, 1, 2),
+		dataframe.NewSeriesString("name", nil, "a", "b"),
+		dataframe.NewSeriesFloat64("price", nil, 1.5, 2.5),
+	)
+
+	tests := []struct {
+		database Database
+		opts     SQLExportOptions
+		expected string
+	}{
+		{PostgreSQL, SQLExportOptions{WriteMode: InsertIgnore},
+			`INSERT INTO "test" ("id","name","price") VALUES ($1,$2,$3),($4,$5,$6) ON CONFLICT DO NOTHING`},
+		{PostgreSQL, SQLExportOptions{WriteMode: Upsert, ConflictColumns: []string{"id"}},
+			`INSERT INTO "test" ("id","name","price") VALUES ($1,$2,$3),($4,$5,$6) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name", "price" = EXCLUDED."price"`},
+		{PostgreSQL, SQLExportOptions{WriteMode: Upsert, ConflictColumns: []string{"id"}, UpdateColumns: []string{"price"}},
+			`INSERT INTO "test" ("id","name","price") VALUES ($1,$2,$3),($4,$5,$6) ON CONFLICT ("id") DO UPDATE SET "price" = EXCLUDED."price"`},
+		{PostgreSQL, SQLExportOptions{WriteMode: Replace, ConflictColumns: []string{"id"}, UpdateColumns: []string{"price"}},
+			`INSERT INTO "test" ("id","name","price") VALUES ($1,$2,$3),($4,$5,$6) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name", "price" = EXCLUDED."price"`},
+		{PostgreSQL, SQLExportOptions{WriteMode: Replace, ConflictColumns: []string{"id", "name", "price"}},
+			`INSERT INTO "test" ("id","name","price") VALUES ($1,$2,$3),($4,$5,$6) ON CONFLICT ("id","name","price") DO NOTHING`},
+		{PostgreSQL, SQLExportOptions{WriteMode: Upsert, SeriesToColumn: map[string]*string{"id": nil}, PrimaryKey: &PrimaryKey{PrimaryKey: "uuid"}},
+			`INSERT INTO "test" ("uuid","name","price") VALUES ($1,$2,$3),($4,$5,$6) ON CONFLICT ("uuid") DO UPDATE SET "name" = EXCLUDED."name", "price" = EXCLUDED."price"`},
+		{MySQL, SQLExportOptions{WriteMode: InsertIgnore},
+			"INSERT IGNORE INTO `test` (`id`,`name`,`price`) VALUES (?,?,?),(?,?,?)"},
+		{MySQL, SQLExportOptions{WriteMode: Upsert, ConflictColumns: []string{"id"}},
+			"INSERT INTO `test` (`id`,`name`,`price`) VALUES (?,?,?),(?,?,?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `price` = VALUES(`price`)"},
+		{MySQL, SQLExportOptions{WriteMode: Replace},
+			"REPLACE INTO `test` (`id`,`name`,`price`) VALUES (?,?,?),(?,?,?)"},
+		{SQLite, SQLExportOptions{WriteMode: InsertIgnore},
+			`INSERT OR IGNORE INTO "test" ("id","name","price") VALUES (?,?,?),(?,?,?)`},
+		{SQLite, SQLExportOptions{WriteMode: Upsert, ConflictColumns: []string{"id"}},
+			`INSERT INTO "test" ("id","name","price") VALUES (?,?,?),(?,?,?) ON CONFLICT ("id") DO UPDATE SET "name" = excluded."name", "price" = excluded."price"`},
+		{SQLite, SQLExportOptions{WriteMode: Replace},
+			`INSERT OR REPLACE INTO "test" ("id","name","price") VALUES (?,?,?),(?,?,?)`},
+		{MSSQL, SQLExportOptions{WriteMode: Upsert, ConflictColumns: []string{"id"}},
+			`MERGE INTO [test] AS target USING (VALUES (@p1,@p2,@p3),(@p4,@p5,@p6)) AS source ([id],[name],[price]) ON (target.[id] = source.[id]) WHEN MATCHED THEN UPDATE SET target.[name] = source.[name], target.[price] = source.[price] WHEN NOT MATCHED THEN INSERT ([id],[name],[price]) VALUES (source.[id],source.[name],source.[price]);`},
+		{MSSQL, SQLExportOptions{WriteMode: InsertIgnore, ConflictColumns: []string{"id", "name"}},
+			`MERGE INTO [test] AS target USING (VALUES (@p1,@p2,@p3),(@p4,@p5,@p6)) AS source ([id],[name],[price]) ON (target.[id] = source.[id] AND target.[name] = source.[name]) WHEN NOT MATCHED THEN INSERT ([id],[name],[price]) VALUES (source.[id],source.[name],source.[price]);`},
+		{Oracle, SQLExportOptions{WriteMode: Upsert, ConflictColumns: []string{"id"}, UpdateColumns: []string{"name"}},
+			`MERGE INTO "test" target USING (SELECT :1 "id",:2 "name",:3 "price" FROM DUAL UNION ALL SELECT :4,:5,:6 FROM DUAL) source ON (target."id" = source."id") WHEN MATCHED THEN UPDATE SET target."name" = source."name" WHEN NOT MATCHED THEN INSERT ("id","name","price") VALUES (source."id",source."name",source."price")`},
+	}
+
+	for i, tc := range tests {
+		rec := &execRecorder{}
+
+		tc.opts.Database = tc.database
+		err := ExportToSQL(ctx, rec, df, "test", tc.opts)
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+
+		if len(rec.stmts) != 1 || rec.stmts[0] != tc.expected {
+			t.Errorf("%d: expected:\n%s\nactual:\n%s", i, tc.expected, strings.Join(rec.stmts, "\n"))
+		}
+	}
+
+	// Errors
+	errTests := []struct {
+		database Database
+		opts     SQLExportOptions
+	}{
+		{PostgreSQL, SQLExportOptions{WriteMode: Upsert}},
+		{PostgreSQL, SQLExportOptions{WriteMode: Upsert, ConflictColumns: []string{"unknown"}}},
+		{PostgreSQL, SQLExportOptions{WriteMode: Upsert, ConflictColumns: []string{"id"}, UpdateColumns: []string{"unknown"}}},
+		{PostgreSQL, SQLExportOptions{WriteMode: Upsert, ConflictColumns: []string{"id", "name", "price"}}}, // nothing to update
+		{PostgreSQL, SQLExportOptions{WriteMode: Upsert, ConflictColumns: []string{"id"}, UpdateColumns: []string{}}},
+		{MySQL, SQLExportOptions{WriteMode: Upsert, ConflictColumns: []string{"id", "name", "price"}}},
+		{PostgreSQL, SQLExportOptions{WriteMode: 10}},
+		{MSSQL, SQLExportOptions{WriteMode: InsertIgnore}},
+		{Oracle, SQLExportOptions{WriteMode: Replace}},
+		{100, SQLExportOptions{WriteMode: Upsert, ConflictColumns: []string{"id"}}}, // customDialect doesn't implement Upserter
+	}
+
+	RegisterDialect(100, customDialect{})
+
+	for i, tc := range errTests {
+		rec := &execRecorder{}
+
+		tc.opts.Database = tc.database
+		err := ExportToSQL(ctx, rec, df, "test", tc.opts)
+		if err == nil {
+			t.Errorf("%d: expected error", i)
+		}
+		if len(rec.stmts) != 0 {
+			t.Errorf("%d: expected no statements to be executed", i)
+		}
+	}
+}
+
+func TestCreateSQLTableStmt(t *testing.T) {
+	ctx := context.Background()
+
+	df := dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("id", nil, 1, 2),
+		dataframe.NewSeriesString("name", nil, "ab", "abcé"),
+		dataframe.NewSeriesFloat64("price", nil, 1.5, nil),
+		dataframe.NewSeriesTime("created", nil, nil, nil),
+		dataframe.NewSeriesString("note", nil, nil, nil),
+	)
+
+	tests := []struct {
+		database Database
+		opts     SQLTableOptions
+		expected string
+	}{
+		{PostgreSQL, SQLTableOptions{},
+			`CREATE TABLE "test" ("id" BIGINT NOT NULL, "name" VARCHAR(4) NOT NULL, "price" FLOAT8, "created" TIMESTAMP, "note" TEXT)`},
+		{PostgreSQL, SQLTableOptions{IfNotExists: true, PrimaryKey: &PrimaryKey{PrimaryKey: "id"}, ColumnTypes: map[string]string{"price": "NUMERIC(10,2)"}},
+			`CREATE TABLE IF NOT EXISTS "test" ("id" BIGINT NOT NULL, "name" VARCHAR(4) NOT NULL, "price" NUMERIC(10,2), "created" TIMESTAMP, "note" TEXT, PRIMARY KEY ("id"))`},
+		{PostgreSQL, SQLTableOptions{PrimaryKey: &PrimaryKey{PrimaryKey: "pk"}, SeriesToColumn: map[string]*string{"id": nil, "note": &[]string{"comment"}[0]}},
+			`CREATE TABLE "test" ("pk" BIGSERIAL, "name" VARCHAR(4) NOT NULL, "price" FLOAT8, "created" TIMESTAMP, "comment" TEXT, PRIMARY KEY ("pk"))`},
+		{MySQL, SQLTableOptions{IfNotExists: true, PrimaryKey: &PrimaryKey{PrimaryKey: "uuid", Value: func(row int, n int) *string { return nil }}},
+			"CREATE TABLE IF NOT EXISTS `test` (`uuid` VARCHAR(255), `id` BIGINT NOT NULL, `name` VARCHAR(4) NOT NULL, `price` DOUBLE, `created` DATETIME, `note` TEXT, PRIMARY KEY (`uuid`))"},
+		{SQLite, SQLTableOptions{PrimaryKey: &PrimaryKey{PrimaryKey: "id"}},
+			`CREATE TABLE "test" ("id" INTEGER NOT NULL, "name" TEXT NOT NULL, "price" REAL, "created" DATETIME, "note" TEXT, PRIMARY KEY ("id"))`},
+		{MSSQL, SQLTableOptions{IfNotExists: true},
+			`IF OBJECT_ID(N'[test]', N'U') IS NULL CREATE TABLE [test] ([id] BIGINT NOT NULL, [name] NVARCHAR(4) NOT NULL, [price] FLOAT, [created] DATETIME2, [note] NVARCHAR(MAX))`},
+		{Oracle, SQLTableOptions{PrimaryKey: &PrimaryKey{PrimaryKey: "pk"}},
+			`CREATE TABLE "test" ("pk" NUMBER(19) GENERATED BY DEFAULT ON NULL AS IDENTITY, "id" NUMBER(19) NOT NULL, "name" VARCHAR2(4 CHAR) NOT NULL, "price" BINARY_DOUBLE, "created" TIMESTAMP, "note" CLOB, PRIMARY KEY ("pk"))`},
+	}
+
+	for i, tc := range tests {
+		tc.opts.Database = tc.database
+		stmt, err := CreateSQLTableStmt(ctx, df, "test", tc.opts)
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+
+		if stmt != tc.expected {
+			t.Errorf("%d: expected:\n%s\nactual:\n%s", i, tc.expected, stmt)
+		}
+	}
+
+	// Empty Dataframe
+	stmt, err := CreateSQLTableStmt(ctx, dataframe.NewDataFrame(dataframe.NewSeriesInt64("id", nil)), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `CREATE TABLE "test" ("id" BIGINT)`; stmt != expected {
+		t.Errorf("expected:\n%s\nactual:\n%s", expected, stmt)
+	}
+
+	// Errors
+	errTests := []SQLTableOptions{
+		{ColumnTypes: map[string]string{"unknown": "TEXT"}},
+		{PrimaryKey: &PrimaryKey{}},
+		{Database: 99},
+		{Database: 100}, // customDialect doesn't implement TableCreator
+	}
+
+	RegisterDialect(100, customDialect{})
+
+	for i, opts := range errTests {
+		_, err := CreateSQLTableStmt(ctx, df, "test", opts)
+		if err == nil {
+			t.Errorf("%d: expected error", i)
+		}
+	}
+
+	// Execute statement
+	rec := &execRecorder{}
+	err = CreateSQLTable(ctx, rec, df, "test", SQLTableOptions{Database: SQLite})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.stmts) != 1 || !strings.HasPrefix(rec.stmts[0], `CREATE TABLE "test"`) {
+		t.Errorf("unexpected statements: %v", rec.stmts)
+	}
+}
+
+// readerHandlers mimics the reader handler registry of github.com/go-sql-driver/mysql.
+type readerHandlers struct {
+	execRecorder
+	handlers map[string]func() io.Reader
+	data     []string
+}
+
+func (rh *readerHandlers) Register(name string, handler func() io.Reader) {
+	rh.handlers[name] = handler
+}
+func (rh *readerHandlers) Deregister(name string) { delete(rh.handlers, name) }
+
+func (rh *readerHandlers) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	rh.execRecorder.ExecContext(ctx, query, args...)
+
+	name := query[strings.Index(query, "Reader::")+8 : strings.Index(query, "' ")]
+	data, err := ioutil.ReadAll(rh.handlers[name]())
+	if err != nil {
+		return nil, err
+	}
+	rh.data = append(rh.data, string(data))
+	return nil, nil
+}
+
+func TestExportToSQLBulkLoad(t *testing.T) {
+	ctx := context.Background()
+
+	df := dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("id", nil, 1, nil, 3, 4, 5),
+		dataframe.NewSeriesString("name", nil, "a,b", `"q"`, nil, `\N`, "NULL"),
+		dataframe.NewSeriesTime("created", nil, time.Date(2000, 1, 1, 0, 0, 1, 0, time.UTC), nil, nil, nil, nil),
+	)
+
+	// PostgreSQL (csv)
+	var (
+		stmt string
+		data string
+	)
+
+	copyFrom := func(ctx context.Context, s string, r io.Reader) error {
+		b, err := ioutil.ReadAll(r)
+		stmt, data = s, string(b)
+		return err
+	}
+
+	err := ExportToSQL(ctx, nil, df, "test", SQLExportOptions{
+		BulkLoad:   &BulkLoad{CopyFrom: copyFrom},
+		PrimaryKey: &PrimaryKey{PrimaryKey: "pk"},
+		Range:      dataframe.Range{Start: &[]int{1}[0]},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := `COPY "test" ("id","name","created") FROM STDIN WITH (FORMAT csv, NULL '\N')`; stmt != expected {
+		t.Errorf("expected:\n%s\nactual:\n%s", expected, stmt)
+	}
+	// Strings that look like NULL are quoted
+	if expected := "\\N,\"\"\"q\"\"\",\\N\n\"3\",\\N,\\N\n\"4\",\"\\N\",\\N\n\"5\",\"NULL\",\\N\n"; data != expected {
+		t.Errorf("expected:\n%q\nactual:\n%q", expected, data)
+	}
+
+	// PostgreSQL (binary)
+	err = ExportToSQL(ctx, nil, df, "test", SQLExportOptions{
+		BulkLoad:   &BulkLoad{CopyFrom: copyFrom, Binary: true},
+		PrimaryKey: &PrimaryKey{PrimaryKey: "pk", Value: func(row int, n int) *string { return &[]string{"x"}[0] }},
+		Range:      dataframe.Range{End: &[]int{0}[0]},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := `COPY "test" ("pk","id","name","created") FROM STDIN WITH (FORMAT binary)`; stmt != expected {
+		t.Errorf("expected:\n%s\nactual:\n%s", expected, stmt)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("PGCOPY\n\377\r\n\x00")
+	for _, v := range []interface{}{int32(0), int32(0), int16(4), int32(1), []byte("x"), int32(8), int64(1), int32(3), []byte("a,b"), int32(8), int64(1000000), int16(-1)} {
+		binary.Write(&buf, binary.BigEndian, v)
+	}
+	if data != buf.String() {
+		t.Errorf("expected:\n%q\nactual:\n%q", buf.String(), data)
+	}
+
+	// MySQL
+	rh := &readerHandlers{handlers: map[string]func() io.Reader{}}
+	err = ExportToSQL(ctx, rh, df, "test", SQLExportOptions{
+		Database:   MySQL,
+		WriteMode:  Replace,
+		NullString: &[]string{"NA"}[0],
+		BulkLoad:   &BulkLoad{RegisterReaderHandler: rh.Register, DeregisterReaderHandler: rh.Deregister},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rh.stmts) != 1 || !strings.HasPrefix(rh.stmts[0], "LOAD DATA LOCAL INFILE 'Reader::dataframe-go-") || !strings.HasSuffix(rh.stmts[0], "' REPLACE INTO TABLE `test` CHARACTER SET utf8mb4 FIELDS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '\"' ESCAPED BY '' LINES TERMINATED BY '\\n' (`id`,`name`,`created`)") {
+		t.Errorf("unexpected statements: %v", rh.stmts)
+	}
+	if expected := "\"1\",\"a,b\",\"2000-01-01 00:00:01\"\n\"NA\",\"\"\"q\"\"\",\"NA\"\n\"3\",\"NA\",\"NA\"\n\"4\",\"\\N\",\"NA\"\n\"5\",\"NULL\",\"NA\"\n"; len(rh.data) != 1 || rh.data[0] != expected {
+		t.Errorf("expected:\n%q\nactual:\n%q", expected, rh.data)
+	}
+	if len(rh.handlers) != 0 {
+		t.Errorf("reader handler not deregistered")
+	}
+
+	// Without NullString, nil values are written as NULL
+	rh.data = nil
+	err = ExportToSQL(ctx, rh, df, "test", SQLExportOptions{
+		Database: MySQL,
+		BulkLoad: &BulkLoad{RegisterReaderHandler: rh.Register, DeregisterReaderHandler: rh.Deregister},
+		Range:    dataframe.Range{Start: &[]int{3}[0]},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "\"4\",\"\\N\",NULL\n\"5\",\"NULL\",NULL\n"; len(rh.data) != 1 || rh.data[0] != expected {
+		t.Errorf("expected:\n%q\nactual:\n%q", expected, rh.data)
+	}
+
+	// The data is not read
+	err = ExportToSQL(ctx, nil, df, "test", SQLExportOptions{
+		BulkLoad: &BulkLoad{CopyFrom: func(ctx context.Context, s string, r io.Reader) error { return errors.New("failed") }},
+	})
+	if err == nil || err.Error() != "failed" {
+		t.Errorf("expected error: failed actual: %v", err)
+	}
+
+	// Errors
+	errTests := []SQLExportOptions{
+		{BulkLoad: &BulkLoad{}},
+		{BulkLoad: &BulkLoad{CopyFrom: copyFrom}, WriteMode: InsertIgnore},
+		{BulkLoad: &BulkLoad{}, Database: MySQL},
+		{BulkLoad: &BulkLoad{RegisterReaderHandler: rh.Register, DeregisterReaderHandler: rh.Deregister}, Database: MySQL, WriteMode: Upsert},
+		{BulkLoad: &BulkLoad{RegisterReaderHandler: rh.Register, DeregisterReaderHandler: rh.Deregister, Binary: true}, Database: MySQL},
+		{BulkLoad: &BulkLoad{}, Database: SQLite},
+	}
+
+	for i, opts := range errTests {
+		err := ExportToSQL(ctx, rh, df, "test", opts)
+		if err == nil {
+			t.Errorf("%d: expected error", i)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package exports
+
+import (
+	"errors"
+	"strings"
+)
+
+// WriteMode determines what happens when a row conflicts with an existing row
+// (i.e. it violates a primary key or unique constraint).
+type WriteMode int
+
+const (
+	// Insert inserts the rows. A conflicting row causes an error.
+	Insert WriteMode = 0
+
+	// InsertIgnore skips conflicting rows.
+	InsertIgnore WriteMode = 1
+
+	// Upsert updates the UpdateColumns of conflicting rows.
+	//
+	// NOTE: For PostgreSQL, MSSQL and Oracle, a batch must not contain the same row more than once.
+	Upsert WriteMode = 2
+
+	// Replace replaces conflicting rows.
+	// For MySQL and SQLite, the existing row is deleted before the new row is inserted.
+	// For other databases, every column that is not a conflict column is updated.
+	Replace WriteMode = 3
+)
+
+// errConflictColumns is returned when a write mode requires conflict columns but none are set.
+var errConflictColumns = errors.New("ConflictColumns must be set")
+
+// Upserter can optionally be implemented by a Dialect that supports write modes other than Insert.
+type Upserter interface {
+
+	// UpsertStmt returns the statement that writes rows into tableName using mode.
+	// conflictColumns identify a row (i.e. they have a primary key or unique constraint).
+	// updateColumns are updated when a row conflicts with an existing row.
+	// tableName, columnNames, conflictColumns and updateColumns are already escaped.
+	UpsertStmt(mode WriteMode, tableName string, columnNames []string, rows int, conflictColumns, updateColumns []string) (string, error)
+}
+
+func (d postgreSQLDialect) UpsertStmt(mode WriteMode, tableName string, columnNames []string, rows int, conflictColumns, updateColumns []string) (string, error) {
+	return onConflict(d, "EXCLUDED", mode, tableName, columnNames, rows, conflictColumns, updateColumns)
+}
+
+func (d sqliteDialect) UpsertStmt(mode WriteMode, tableName string, columnNames []string, rows int, conflictColumns, updateColumns []string) (string, error) {
+	switch mode {
+	case InsertIgnore:
+		return "INSERT OR IGNORE INTO " + tableName + " (" + strings.Join(columnNames, ",") + ") VALUES " + placeholders(d, columnNames, rows), nil
+	case Replace:
+		return "INSERT OR REPLACE INTO " + tableName + " (" + strings.Join(columnNames, ",") + ") VALUES " + placeholders(d, columnNames, rows), nil
+	default:
+		return onConflict(d, "excluded", mode, tableName, columnNames, rows, conflictColumns, updateColumns)
+	}
+}
+
+// onConflict returns an INSERT statement with an ON CONFLICT clause (PostgreSQL 9.5+ and SQLite 3.24+).
+func onConflict(d Dialect, excluded string, mode WriteMode, tableName string, columnNames []string, rows int, conflictColumns, updateColumns []string) (string, error) {
+
+	stmt := "INSERT INTO " + tableName + " (" + strings.Join(columnNames, ",") + ") VALUES " + placeholders(d, columnNames, rows) + " ON CONFLICT"
+	if len(conflictColumns) > 0 {
+		stmt = stmt + " (" + strings.Join(conflictColumns, ",") + ")"
+	}
+
+	if mode == InsertIgnore {
+		return stmt + " DO NOTHING", nil
+	}
+
+	if len(conflictColumns) == 0 {
+		return "", errConflictColumns
+	}
+
+	set := []string{}
+	for _, col := range updateColumns {
+		set = append(set, col+" = "+excluded+"."+col)
+	}
+
+	return stmt + " DO UPDATE SET " + strings.Join(set, ", "), nil
+}
+
+func (d mySQLDialect) UpsertStmt(mode WriteMode, tableName string, columnNames []string, rows int, conflictColumns, updateColumns []string) (string, error) {
+
+	values := " (" + strings.Join(columnNames, ",") + ") VALUES " + placeholders(d, columnNames, rows)
+
+	switch mode {
+	case InsertIgnore:
+		return "INSERT IGNORE INTO " + tableName + values, nil
+	case Replace:
+		return "REPLACE INTO " + tableName + values, nil
+	default:
+		// MySQL uses every primary key and unique constraint of the table to detect conflicts.
+		set := []string{}
+		for _, col := range updateColumns {
+			set = append(set, col+" = VALUES("+col+")")
+		}
+		return "INSERT INTO " + tableName + values + " ON DUPLICATE KEY UPDATE " + strings.Join(set, ", "), nil
+	}
+}
+
+func (d mssqlDialect) UpsertStmt(mode WriteMode, tableName string, columnNames []string, rows int, conflictColumns, updateColumns []string) (string, error) {
+	source := "(VALUES " + placeholders(d, columnNames, rows) + ") AS source (" + strings.Join(columnNames, ",") + ")"
+
+	stmt, err := merge(mode, tableName+" AS target", source, columnNames, conflictColumns, updateColumns)
+	if err != nil {
+		return "", err
+	}
+	return stmt + ";", nil
+}
+
+func (d oracleDialect) UpsertStmt(mode WriteMode, tableName string, columnNames []string, rows int, conflictColumns, updateColumns []string) (string, error) {
+
+	selects := []string{}
+	for i := 0; i < rows; i++ {
+		fields := []string{}
+		for j, col := range columnNames {
+			field := d.Placeholder(i*len(columnNames) + j + 1)
+			if i == 0 {
+				field = field + " " + col
+			}
+			fields = append(fields, field)
+		}
+		selects = append(selects, "SELECT "+strings.Join(fields, ",")+" FROM DUAL")
+	}
+	source := "(" + strings.Join(selects, " UNION ALL ") + ") source"
+
+	return merge(mode, tableName+" target", source, columnNames, conflictColumns, updateColumns)
+}
+
+// merge returns a MERGE statement.
+func merge(mode WriteMode, target string, source string, columnNames []string, conflictColumns, updateColumns []string) (string, error) {
+
+	if len(conflictColumns) == 0 {
+		return "", errConflictColumns
+	}
+
+	on := []string{}
+	for _, col := range conflictColumns {
+		on = append(on, "target."+col+" = source."+col)
+	}
+
+	stmt := "MERGE INTO " + target + " USING " + source + " ON (" + strings.Join(on, " AND ") + ")"
+
+	if mode != InsertIgnore {
+		set := []string{}
+		for _, col := range updateColumns {
+			set = append(set, "target."+col+" = source."+col)
+		}
+		stmt = stmt + " WHEN MATCHED THEN UPDATE SET " + strings.Join(set, ", ")
+	}
+
+	values := []string{}
+	for _, col := range columnNames {
+		values = append(values, "source."+col)
+	}
+
+	return stmt + " WHEN NOT MATCHED THEN INSERT (" + strings.Join(columnNames, ",") + ") VALUES (" + strings.Join(values, ",") + ")", nil
+}
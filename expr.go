@@ -0,0 +1,494 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package dataframe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ExprError is returned when an expression can't be compiled.
+type ExprError struct {
+
+	// Pos is the byte offset of the offending token in the expression.
+	Pos int
+
+	// Token is the offending token. It is blank at the end of the expression.
+	Token string
+
+	// Msg describes the error.
+	Msg string
+}
+
+// Error implements the error interface.
+func (e *ExprError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+	}
+	return fmt.Sprintf("%s: %q at position %d", e.Msg, e.Token, e.Pos)
+}
+
+// Expr is a compiled expression.
+//
+// The expression language supports:
+//
+//  Series:      price, `unit price` (backticks for names that aren't identifiers)
+//  Literals:    100 (int64), 1.5 (float64), 'AU' or "AU" (string), true, false, nil
+//  Arithmetic:  + - * / % (+ also concatenates strings, / always returns a float64, % requires int64 and x % 0 is nil)
+//  Comparison:  == != < <= > >= (a time can be compared with a string such as '2020-01-31')
+//  Logical:     && || !
+//
+// Only SeriesFloat64, SeriesInt64, SeriesString, SeriesTime and a SeriesGeneric of bool (eg. created by Eval) can be used.
+//
+// nil values propagate: arithmetic with a nil value is nil and ordering a nil value (eg. price > 100) is nil.
+// x == nil and x != nil can be used to check for nil values. && and || follow three-valued logic
+// (eg. nil && false is false). Filter only keeps rows where the expression is true.
+type Expr struct {
+	src  string
+	root exprNode
+}
+
+// CompileExpr compiles an expression against the Series of df. The expression must only be evaluated
+// against df (or a DataFrame with the same Series layout).
+//
+// Example:
+//
+//  expr, err := dataframe.CompileExpr(df, "price * qty > 100 && country == 'AU'")
+//
+func CompileExpr(df *DataFrame, expr string, opts ...Options) (*Expr, error) {
+	if len(opts) == 0 || !opts[0].DontLock {
+		df.lock.RLock()
+		defer df.lock.RUnlock()
+	}
+
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{df: df, tokens: tokens}
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Expr{src: expr, root: root}, nil
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Type returns the type of the result: "float64", "int64", "string", "time", "bool" or "nil".
+func (e *Expr) Type() string {
+	return e.root.typ().String()
+}
+
+// Evaluate returns the result of the expression for a row. vals must contain the values of the row
+// keyed by column index (as returned by the DataFrame's ValuesIterator).
+func (e *Expr) Evaluate(vals map[interface{}]interface{}) (interface{}, error) {
+	return e.root.eval(func(col int) interface{} { return vals[col] })
+}
+
+// FilterFn returns a FilterDataFrameFn that keeps the rows where the expression is true.
+// The expression must return a bool.
+func (e *Expr) FilterFn() (FilterDataFrameFn, error) {
+	if e.root.typ() != exprBool {
+		return nil, fmt.Errorf("expression must return bool: %s", e.Type())
+	}
+
+	return func(vals map[interface{}]interface{}, row, nRows int) (FilterAction, error) {
+		res, err := e.Evaluate(vals)
+		if err != nil {
+			return DROP, &RowError{Row: row, Err: err}
+		}
+		if res == true {
+			return KEEP, nil
+		}
+		return DROP, nil
+	}, nil
+}
+
+// evaluateSeries returns a Series containing the result of the expression for each row of df.
+func (e *Expr) evaluateSeries(ctx context.Context, df *DataFrame, name string) (Series, error) {
+
+	s, err := e.newSeries(name, &SeriesInit{Size: df.n})
+	if err != nil {
+		return nil, err
+	}
+
+	for row := 0; row < df.n; row++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		val, err := e.evaluateRow(df, row)
+		if err != nil {
+			return nil, &RowError{Row: row, Err: err}
+		}
+		if val != nil {
+			s.Update(row, val, dontLock)
+		}
+	}
+
+	return s, nil
+}
+
+// filterDataFrameFn converts the fn argument of Filter to a FilterDataFrameFn.
+// fn can also be an expression (string) or an *Expr.
+func filterDataFrameFn(df *DataFrame, fn interface{}, opts ...FilterOptions) (FilterDataFrameFn, error) {
+	switch v := fn.(type) {
+	case string:
+		e, err := CompileExpr(df, v, Options{DontLock: len(opts) > 0 && opts[0].DontLock})
+		if err != nil {
+			return nil, err
+		}
+		return e.FilterFn()
+	case *Expr:
+		return v.FilterFn()
+	}
+	return fn.(FilterDataFrameFn), nil
+}
+
+// evaluateRow returns the result of the expression for a row of df.
+func (e *Expr) evaluateRow(df *DataFrame, row int) (interface{}, error) {
+	return e.root.eval(func(col int) interface{} { return df.Series[col].Value(row, dontLock) })
+}
+
+// newSeries creates a Series to hold the results of the expression.
+func (e *Expr) newSeries(name string, init *SeriesInit) (Series, error) {
+	switch e.root.typ() {
+	case exprFloat64:
+		return NewSeriesFloat64(name, init), nil
+	case exprInt64:
+		return NewSeriesInt64(name, init), nil
+	case exprString:
+		return NewSeriesString(name, init), nil
+	case exprTime:
+		return NewSeriesTime(name, init), nil
+	case exprBool:
+		s := NewSeriesGeneric(name, false, init)
+		s.SetIsLessThanFunc(func(a, b interface{}) bool {
+			// nil < false < true
+			return (a == nil && b != nil) || (a == false && b == true)
+		})
+		return s, nil
+	}
+	return nil, errors.New("expression must not be nil")
+}
+
+// Eval adds a Series computed from an expression of the form: name = expression.
+// If a Series called name already exists, it is replaced. A bool expression creates a SeriesGeneric of bool.
+//
+// Example:
+//
+//  err := df.Eval(ctx, "total = price * qty")
+//
+// See: Expr for the expression language.
+func (df *DataFrame) Eval(ctx context.Context, expr string, opts ...Options) error {
+	if len(opts) == 0 || !opts[0].DontLock {
+		df.lock.Lock()
+		defer df.lock.Unlock()
+	}
+
+	tokens, err := lex(expr)
+	if err != nil {
+		return err
+	}
+
+	if tokens[0].kind != tokIdent {
+		return &ExprError{Pos: tokens[0].pos, Token: tokens[0].text, Msg: "expected name of series"}
+	}
+	if tokens[1].kind != tokAssign {
+		return &ExprError{Pos: tokens[1].pos, Token: tokens[1].text, Msg: "expected ="}
+	}
+	name := tokens[0].val
+
+	// Compile the expression after the =
+	p := &parser{df: df, tokens: tokens[2:]}
+	root, err := p.parseExpr()
+	if err != nil {
+		return err
+	}
+	src := expr[tokens[2].pos:]
+	e := &Expr{src: src, root: root}
+
+	col, err := df.NameToColumn(name, dontLock)
+	replace := err == nil
+	if replace {
+		for _, s := range df.index {
+			if s == df.Series[col] {
+				return fmt.Errorf("can't replace index series: %s", name)
+			}
+		}
+	}
+
+	s, err := e.evaluateSeries(ctx, df, name)
+	if err != nil {
+		return err
+	}
+
+	if replace {
+		df.Series[col] = s
+	} else {
+		df.Series = append(df.Series, s)
+	}
+
+	return nil
+}
+
+// exprType is the type of the result of an expression node.
+type exprType int
+
+const (
+	exprNil exprType = iota
+	exprFloat64
+	exprInt64
+	exprString
+	exprTime
+	exprBool
+)
+
+func (t exprType) String() string {
+	return [...]string{"nil", "float64", "int64", "string", "time", "bool"}[t]
+}
+
+func (t exprType) numeric() bool {
+	return t == exprFloat64 || t == exprInt64
+}
+
+// exprNode is a node of a compiled expression. get returns the value of a Series for the row being evaluated.
+type exprNode interface {
+	typ() exprType
+	eval(get func(col int) interface{}) (interface{}, error)
+}
+
+type literalNode struct {
+	t   exprType
+	val interface{}
+	tok token
+}
+
+func (n *literalNode) typ() exprType { return n.t }
+
+func (n *literalNode) eval(get func(col int) interface{}) (interface{}, error) {
+	return n.val, nil
+}
+
+type columnNode struct {
+	t   exprType
+	col int
+}
+
+func (n *columnNode) typ() exprType { return n.t }
+
+func (n *columnNode) eval(get func(col int) interface{}) (interface{}, error) {
+	return get(n.col), nil
+}
+
+type notNode struct {
+	x exprNode
+}
+
+func (n *notNode) typ() exprType { return exprBool }
+
+func (n *notNode) eval(get func(col int) interface{}) (interface{}, error) {
+	x, err := n.x.eval(get)
+	if x == nil || err != nil {
+		return nil, err
+	}
+	return !x.(bool), nil
+}
+
+type negNode struct {
+	x exprNode
+}
+
+func (n *negNode) typ() exprType { return n.x.typ() }
+
+func (n *negNode) eval(get func(col int) interface{}) (interface{}, error) {
+	x, err := n.x.eval(get)
+	if x == nil || err != nil {
+		return nil, err
+	}
+	if i, ok := x.(int64); ok {
+		return -i, nil
+	}
+	return -x.(float64), nil
+}
+
+type logicalNode struct {
+	op   tokenKind
+	l, r exprNode
+}
+
+func (n *logicalNode) typ() exprType { return exprBool }
+
+func (n *logicalNode) eval(get func(col int) interface{}) (interface{}, error) {
+	l, err := n.l.eval(get)
+	if err != nil {
+		return nil, err
+	}
+
+	// Short circuit
+	if n.op == tokAnd && l == false {
+		return false, nil
+	}
+	if n.op == tokOr && l == true {
+		return true, nil
+	}
+
+	r, err := n.r.eval(get)
+	if err != nil {
+		return nil, err
+	}
+
+	if l == nil {
+		if n.op == tokAnd && r == false || n.op == tokOr && r == true {
+			return r, nil
+		}
+		return nil, nil
+	}
+	return r, nil
+}
+
+type arithmeticNode struct {
+	op   tokenKind
+	t    exprType
+	l, r exprNode
+}
+
+func (n *arithmeticNode) typ() exprType { return n.t }
+
+func (n *arithmeticNode) eval(get func(col int) interface{}) (interface{}, error) {
+	l, err := n.l.eval(get)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.r.eval(get)
+	if l == nil || r == nil || err != nil {
+		return nil, err
+	}
+
+	switch n.t {
+	case exprString:
+		return l.(string) + r.(string), nil
+	case exprInt64:
+		a, b := l.(int64), r.(int64)
+		switch n.op {
+		case tokAdd:
+			return a + b, nil
+		case tokSub:
+			return a - b, nil
+		case tokMul:
+			return a * b, nil
+		case tokMod:
+			if b == 0 {
+				return nil, nil
+			}
+			return a % b, nil
+		}
+	}
+
+	a, b := exprFloat(l), exprFloat(r)
+	switch n.op {
+	case tokAdd:
+		return a + b, nil
+	case tokSub:
+		return a - b, nil
+	case tokMul:
+		return a * b, nil
+	}
+	return a / b, nil
+}
+
+type comparisonNode struct {
+	op   tokenKind
+	l, r exprNode
+}
+
+func (n *comparisonNode) typ() exprType { return exprBool }
+
+func (n *comparisonNode) eval(get func(col int) interface{}) (interface{}, error) {
+	l, err := n.l.eval(get)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.r.eval(get)
+	if err != nil {
+		return nil, err
+	}
+
+	if l == nil || r == nil {
+		switch n.op {
+		case tokEq:
+			return l == nil && r == nil, nil
+		case tokNe:
+			return l != nil || r != nil, nil
+		}
+		return nil, nil
+	}
+
+	var c int // -1, 0 or 1
+	switch a := l.(type) {
+	case string:
+		c = strings.Compare(a, r.(string))
+	case time.Time:
+		b := r.(time.Time)
+		switch {
+		case a.Before(b):
+			c = -1
+		case a.After(b):
+			c = 1
+		}
+	case bool:
+		if a != r.(bool) {
+			c = 1
+		}
+	default:
+		ai, aok := l.(int64)
+		bi, bok := r.(int64)
+		if aok && bok {
+			switch {
+			case ai < bi:
+				c = -1
+			case ai > bi:
+				c = 1
+			}
+		} else {
+			af, bf := exprFloat(l), exprFloat(r)
+			switch {
+			case af < bf:
+				c = -1
+			case af > bf:
+				c = 1
+			}
+		}
+	}
+
+	switch n.op {
+	case tokEq:
+		return c == 0, nil
+	case tokNe:
+		return c != 0, nil
+	case tokLt:
+		return c < 0, nil
+	case tokLe:
+		return c <= 0, nil
+	case tokGt:
+		return c > 0, nil
+	}
+	return c >= 0, nil
+}
+
+// exprFloat converts an int64 or float64 to float64.
+func exprFloat(v interface{}) float64 {
+	if i, ok := v.(int64); ok {
+		return float64(i)
+	}
+	return v.(float64)
+}
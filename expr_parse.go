@@ -0,0 +1,485 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package dataframe
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokTrue
+	tokFalse
+	tokNil
+	tokLParen
+	tokRParen
+	tokAssign
+	tokOr
+	tokAnd
+	tokNot
+	tokEq
+	tokNe
+	tokLt
+	tokLe
+	tokGt
+	tokGe
+	tokAdd
+	tokSub
+	tokMul
+	tokDiv
+	tokMod
+)
+
+type token struct {
+	kind tokenKind
+	text string // source text of the token
+	val  string // unquoted value of strings and identifiers
+	pos  int    // byte offset in the expression
+}
+
+var exprOperators = []struct {
+	text string
+	kind tokenKind
+}{
+	// Longer operators must be listed first
+	{"||", tokOr}, {"&&", tokAnd}, {"==", tokEq}, {"!=", tokNe}, {"<=", tokLe}, {">=", tokGe},
+	{"<", tokLt}, {">", tokGt}, {"!", tokNot}, {"=", tokAssign}, {"+", tokAdd}, {"-", tokSub},
+	{"*", tokMul}, {"/", tokDiv}, {"%", tokMod}, {"(", tokLParen}, {")", tokRParen},
+}
+
+// lex splits an expression into tokens. The last token is always tokEOF.
+func lex(src string) ([]token, error) {
+
+	tokens := []token{}
+
+	for pos := 0; pos < len(src); {
+		r, size := utf8.DecodeRuneInString(src[pos:])
+
+		switch {
+		case unicode.IsSpace(r):
+			pos += size
+			continue
+
+		case r >= '0' && r <= '9' || r == '.' && pos+1 < len(src) && src[pos+1] >= '0' && src[pos+1] <= '9':
+			end := pos
+			for end < len(src) && (isDigit(src[end]) || src[end] == '.' || src[end] == 'e' || src[end] == 'E' ||
+				(src[end] == '+' || src[end] == '-') && (src[end-1] == 'e' || src[end-1] == 'E')) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: src[pos:end], pos: pos})
+			pos = end
+			continue
+
+		case r == '_' || unicode.IsLetter(r):
+			end := pos
+			for end < len(src) {
+				r, size := utf8.DecodeRuneInString(src[end:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				end += size
+			}
+
+			tok := token{kind: tokIdent, text: src[pos:end], val: src[pos:end], pos: pos}
+			switch tok.text {
+			case "true":
+				tok.kind = tokTrue
+			case "false":
+				tok.kind = tokFalse
+			case "nil":
+				tok.kind = tokNil
+			}
+			tokens = append(tokens, tok)
+			pos = end
+			continue
+
+		case r == '\'' || r == '"' || r == '`':
+			end, val, err := lexQuoted(src, pos)
+			if err != nil {
+				return nil, &ExprError{Pos: pos, Token: src[pos:], Msg: err.Error()}
+			}
+
+			kind := tokString
+			if r == '`' {
+				kind = tokIdent // Series names that aren't valid identifiers
+			}
+			tokens = append(tokens, token{kind: kind, text: src[pos:end], val: val, pos: pos})
+			pos = end
+			continue
+		}
+
+		found := false
+		for _, op := range exprOperators {
+			if strings.HasPrefix(src[pos:], op.text) {
+				tokens = append(tokens, token{kind: op.kind, text: op.text, pos: pos})
+				pos += len(op.text)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, &ExprError{Pos: pos, Token: string(r), Msg: "unexpected character"}
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+// lexQuoted returns the end of the quoted string starting at pos and its unquoted value.
+// A quote character can be escaped using a backslash.
+func lexQuoted(src string, pos int) (int, string, error) {
+
+	quote := src[pos]
+
+	var sb strings.Builder
+	for i := pos + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			if i+1 < len(src) {
+				i++
+				switch src[i] {
+				case 'n':
+					sb.WriteByte('\n')
+				case 't':
+					sb.WriteByte('\t')
+				default:
+					sb.WriteByte(src[i])
+				}
+			}
+		case quote:
+			return i + 1, sb.String(), nil
+		default:
+			sb.WriteByte(src[i])
+		}
+	}
+
+	return 0, "", errors.New("unterminated string")
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// parser is a recursive descent parser that type checks the expression as it is parsed.
+type parser struct {
+	df     *DataFrame
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorf(tok token, format string, a ...interface{}) error {
+	return &ExprError{Pos: tok.pos, Token: tok.text, Msg: fmt.Sprintf(format, a...)}
+}
+
+// parseExpr parses an entire expression.
+func (p *parser) parseExpr() (exprNode, error) {
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokEOF {
+		if tok.kind == tokAssign {
+			return nil, p.errorf(tok, "unexpected = (use == to compare)")
+		}
+		return nil, p.errorf(tok, "unexpected token")
+	}
+	return n, nil
+}
+
+func (p *parser) parseOr() (exprNode, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokOr {
+		op := p.next()
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if l, err = p.logical(op, l, r); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func (p *parser) parseAnd() (exprNode, error) {
+	l, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokAnd {
+		op := p.next()
+		r, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		if l, err = p.logical(op, l, r); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func (p *parser) parseComparison() (exprNode, error) {
+	l, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	switch p.peek().kind {
+	case tokEq, tokNe, tokLt, tokLe, tokGt, tokGe:
+		op := p.next()
+		r, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return p.comparison(op, l, r)
+	}
+	return l, nil
+}
+
+func (p *parser) parseAdditive() (exprNode, error) {
+	l, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokAdd || p.peek().kind == tokSub {
+		op := p.next()
+		r, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		if l, err = p.arithmetic(op, l, r); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func (p *parser) parseMultiplicative() (exprNode, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokMul || p.peek().kind == tokDiv || p.peek().kind == tokMod {
+		op := p.next()
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if l, err = p.arithmetic(op, l, r); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func (p *parser) parseUnary() (exprNode, error) {
+	switch p.peek().kind {
+	case tokNot:
+		op := p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if x.typ() != exprBool {
+			return nil, p.errorf(op, "operator ! requires bool operand, got %s", x.typ())
+		}
+		return &notNode{x: x}, nil
+	case tokSub:
+		op := p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if !x.typ().numeric() {
+			return nil, p.errorf(op, "operator - requires numeric operand, got %s", x.typ())
+		}
+		return &negNode{x: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (exprNode, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokNumber:
+		if i, err := strconv.ParseInt(tok.text, 10, 64); err == nil {
+			return &literalNode{t: exprInt64, val: i, tok: tok}, nil
+		}
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorf(tok, "invalid number")
+		}
+		return &literalNode{t: exprFloat64, val: f, tok: tok}, nil
+	case tokString:
+		return &literalNode{t: exprString, val: tok.val, tok: tok}, nil
+	case tokTrue:
+		return &literalNode{t: exprBool, val: true, tok: tok}, nil
+	case tokFalse:
+		return &literalNode{t: exprBool, val: false, tok: tok}, nil
+	case tokNil:
+		return &literalNode{t: exprNil, tok: tok}, nil
+	case tokIdent:
+		return p.column(tok)
+	case tokLParen:
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, p.errorf(closing, "expected )")
+		}
+		return n, nil
+	case tokEOF:
+		return nil, p.errorf(tok, "unexpected end of expression")
+	}
+
+	return nil, p.errorf(tok, "unexpected token")
+}
+
+// column resolves a Series name.
+func (p *parser) column(tok token) (exprNode, error) {
+	col, err := p.df.NameToColumn(tok.val, dontLock)
+	if err != nil {
+		return nil, p.errorf(tok, "unknown series")
+	}
+
+	s := p.df.Series[col]
+
+	var t exprType
+	switch s.(type) {
+	case *SeriesFloat64:
+		t = exprFloat64
+	case *SeriesInt64:
+		t = exprInt64
+	case *SeriesString:
+		t = exprString
+	case *SeriesTime:
+		t = exprTime
+	case *SeriesGeneric:
+		// Eval stores the result of a bool expression in a SeriesGeneric
+		if s.Type() != "bool" {
+			return nil, p.errorf(tok, "unsupported series type %s", s.Type())
+		}
+		t = exprBool
+	default:
+		return nil, p.errorf(tok, "unsupported series type %s", s.Type())
+	}
+
+	return &columnNode{t: t, col: col}, nil
+}
+
+func (p *parser) logical(op token, l, r exprNode) (exprNode, error) {
+	if l.typ() != exprBool || r.typ() != exprBool {
+		return nil, p.errorf(op, "operator %s requires bool operands, got %s and %s", op.text, l.typ(), r.typ())
+	}
+	return &logicalNode{op: op.kind, l: l, r: r}, nil
+}
+
+func (p *parser) arithmetic(op token, l, r exprNode) (exprNode, error) {
+	lt, rt := l.typ(), r.typ()
+
+	var t exprType
+	switch {
+	case lt == exprString && rt == exprString && op.kind == tokAdd:
+		t = exprString
+	case lt.numeric() && rt.numeric():
+		t = exprFloat64
+		if lt == exprInt64 && rt == exprInt64 && op.kind != tokDiv {
+			t = exprInt64
+		}
+		if op.kind == tokMod && t != exprInt64 {
+			return nil, p.errorf(op, "operator %% requires int64 operands, got %s and %s", lt, rt)
+		}
+	default:
+		return nil, p.errorf(op, "operator %s not defined for %s and %s", op.text, lt, rt)
+	}
+
+	return &arithmeticNode{op: op.kind, t: t, l: l, r: r}, nil
+}
+
+func (p *parser) comparison(op token, l, r exprNode) (exprNode, error) {
+	lt, rt := l.typ(), r.typ()
+
+	// A string literal can be compared with a time
+	var err error
+	if lt == exprTime {
+		if r, err = p.timeLiteral(r); err != nil {
+			return nil, err
+		}
+	} else if rt == exprTime {
+		if l, err = p.timeLiteral(l); err != nil {
+			return nil, err
+		}
+	}
+	lt, rt = l.typ(), r.typ()
+
+	equality := op.kind == tokEq || op.kind == tokNe
+
+	switch {
+	case lt == exprNil || rt == exprNil:
+		if !equality {
+			return nil, p.errorf(op, "operator %s not defined for nil", op.text)
+		}
+	case lt.numeric() && rt.numeric():
+	case lt == rt && (lt == exprString || lt == exprTime):
+	case lt == rt && lt == exprBool:
+		if !equality {
+			return nil, p.errorf(op, "operator %s not defined for bool", op.text)
+		}
+	default:
+		return nil, p.errorf(op, "mismatched types %s and %s", lt, rt)
+	}
+
+	return &comparisonNode{op: op.kind, l: l, r: r}, nil
+}
+
+// timeLiteral converts a string literal to a time literal.
+func (p *parser) timeLiteral(n exprNode) (exprNode, error) {
+	lit, ok := n.(*literalNode)
+	if !ok || lit.t != exprString {
+		return n, nil
+	}
+
+	str := lit.val.(string)
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, str); err == nil {
+			return &literalNode{t: exprTime, val: t, tok: lit.tok}, nil
+		}
+	}
+
+	return nil, p.errorf(lit.tok, "invalid time")
+}
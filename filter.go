@@ -43,6 +43,14 @@ type FilterDataFrameFn func(vals map[interface{}]interface{}, row, nRows int) (F
 // Filter is used to filter particular rows in a Series or DataFrame.
 // If the InPlace option is set, the function returns nil. Instead the Series or DataFrame is modified "in place".
 // Alternatively, a new Series or DataFrame is returned.
+//
+// For a DataFrame, fn can also be an expression (string) or an *Expr. The rows where the expression is true are kept.
+//
+// Example:
+//
+//  fdf, err := dataframe.Filter(ctx, df, "price * qty > 100 && country == 'AU'")
+//
+// See: Expr for the expression language.
 func Filter(ctx context.Context, sdf interface{}, fn interface{}, opts ...FilterOptions) (interface{}, error) {
 
 	switch typ := sdf.(type) {
@@ -53,7 +61,11 @@ func Filter(ctx context.Context, sdf interface{}, fn interface{}, opts ...Filter
 		}
 		return s, err
 	case *DataFrame:
-		df, err := filterDataFrame(ctx, typ, fn.(FilterDataFrameFn), opts...)
+		dfn, err := filterDataFrameFn(typ, fn, opts...)
+		if err != nil {
+			return nil, err
+		}
+		df, err := filterDataFrame(ctx, typ, dfn, opts...)
 		if df == nil {
 			return nil, err
 		}
@@ -208,6 +220,7 @@ func filterDataFrame(ctx context.Context, df *DataFrame, fn FilterDataFrameFn, o
 			vals := df.Row(rowToTransfer, true, SeriesName)
 			ndf.Append(&dontLock, vals)
 		}
+		df.inheritIndex(ndf)
 		return ndf, nil
 	}
 
@@ -14,5 +14,6 @@ require (
 	github.com/tealeg/xlsx v1.0.5
 	golang.org/x/exp v0.0.0-20200213203834-85f925bdd4d0
 	golang.org/x/sync v0.0.0-20190911185100-cd5d95a43a6e
+	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1
 	gonum.org/v1/gonum v0.6.2
 )
@@ -37,7 +37,55 @@ type Converter struct {
 	ConverterFunc GenericDataConverter
 }
 
-func parseObject(v map[string]interface{}, prefix string) map[string]interface{} {
+// durationConverter is used for time.Duration values (eg. TIME columns), which are stored in a SeriesGeneric.
+var durationConverter = Converter{
+	ConcreteType:  time.Duration(0),
+	ConverterFunc: parseDuration,
+}
+
+// schemaDataTypes returns the data types of the fields of schema using the conventions of DictateDataType.
+// The data types in dictated override the schema.
+func schemaDataTypes(schema *dataframe.Schema, dictated map[string]interface{}) (map[string]interface{}, error) {
+
+	out := map[string]interface{}{}
+
+	for _, f := range schema.Fields {
+		switch f.Type {
+		case "float64":
+			out[f.Name] = float64(0)
+		case "int64":
+			out[f.Name] = int64(0)
+		case "string":
+			out[f.Name] = ""
+		case "time":
+			out[f.Name] = time.Time{}
+		case "time.Duration":
+			out[f.Name] = durationConverter
+		default:
+			s, err := f.NewSeries(nil)
+			if err != nil {
+				return nil, err
+			}
+
+			ns, ok := s.(dataframe.NewSerieser)
+			if _, generic := s.(*dataframe.SeriesGeneric); !ok || generic {
+				return nil, fmt.Errorf("series type %s requires a Converter in DictateDataType. field: %s", f.Type, f.Name)
+			}
+			out[f.Name] = ns
+		}
+	}
+
+	for name, typ := range dictated {
+		out[name] = typ
+	}
+
+	return out, nil
+}
+
+// parseObject flattens nested objects. The keys of nested objects are joined using sep.
+// Objects nested deeper than maxDepth are not flattened and are instead stored as a JSON string.
+// A negative maxDepth means there is no limit.
+func parseObject(v map[string]interface{}, prefix string, sep string, maxDepth int) map[string]interface{} {
 
 	out := map[string]interface{}{}
 
@@ -46,12 +94,18 @@ func parseObject(v map[string]interface{}, prefix string) map[string]interface{}
 		if prefix == "" {
 			key = k
 		} else {
-			key = prefix + "." + k
+			key = prefix + sep + k
 		}
 
 		switch v := t.(type) {
 		case map[string]interface{}:
-			for k, t := range parseObject(v, key) {
+			if maxDepth == 0 {
+				b, _ := json.Marshal(v)
+				out[key] = string(b)
+				continue
+			}
+
+			for k, t := range parseObject(v, key, sep, maxDepth-1) {
 				out[k] = t
 			}
 		default:
@@ -203,3 +257,29 @@ func dictateForce(row int, insertVals map[string]interface{}, name string, typ i
 
 	return nil
 }
+
+// newSeries creates a Series for the data type typ. typ follows the same
+// conventions as DictateDataType.
+func newSeries(name string, typ interface{}, init *dataframe.SeriesInit) dataframe.Series {
+	switch T := typ.(type) {
+	case float64:
+		return dataframe.NewSeriesFloat64(name, init)
+	case int64, bool:
+		return dataframe.NewSeriesInt64(name, init)
+	case string:
+		return dataframe.NewSeriesString(name, init)
+	case time.Time:
+		return dataframe.NewSeriesTime(name, init)
+	case dataframe.NewSerieser:
+		return T.NewSeries(name, init)
+	case Converter:
+		switch T.ConcreteType.(type) {
+		case time.Time:
+			return dataframe.NewSeriesTime(name, init)
+		default:
+			return dataframe.NewSeriesGeneric(name, T.ConcreteType, init)
+		}
+	default:
+		return dataframe.NewSeriesGeneric(name, typ, init)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package imports
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+// Decompressor returns a reader that decompresses the data read from r.
+type Decompressor func(r io.Reader) (io.ReadCloser, error)
+
+type decompressor struct {
+	magic []byte
+	fn    Decompressor
+
+	// next, if not nil, must return true for the byte that follows magic.
+	next func(b byte) bool
+}
+
+var (
+	decompressorsMu sync.RWMutex
+	decompressors   = []decompressor{
+		// gzip
+		{[]byte{0x1f, 0x8b}, func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) }, nil},
+		// bzip2 (followed by the block size: '1' to '9')
+		{[]byte("BZh"), func(r io.Reader) (io.ReadCloser, error) { return ioutil.NopCloser(bzip2.NewReader(r)), nil }, func(b byte) bool { return b >= '1' && b <= '9' }},
+		// zlib (default compression level)
+		{[]byte{0x78, 0x9c}, zlib.NewReader, nil},
+		// zlib (best speed)
+		{[]byte{0x78, 0x01}, zlib.NewReader, nil},
+		// zlib (best compression)
+		{[]byte{0x78, 0xda}, zlib.NewReader, nil},
+	}
+)
+
+// RegisterDecompressor registers a Decompressor for data that begins with magic.
+// It is used to add compression formats that are not supported by the standard library.
+// A Decompressor registered later takes precedence over an earlier one with the same magic bytes.
+//
+// Example (zstd):
+//
+//  import "github.com/klauspost/compress/zstd"
+//
+//  imports.RegisterDecompressor([]byte{0x28, 0xb5, 0x2f, 0xfd}, func(r io.Reader) (io.ReadCloser, error) {
+//     d, err := zstd.NewReader(r)
+//     if err != nil {
+//        return nil, err
+//     }
+//     return d.IOReadCloser(), nil
+//  })
+//
+func RegisterDecompressor(magic []byte, d Decompressor) {
+	if len(magic) == 0 {
+		panic("magic must not be empty")
+	}
+
+	decompressorsMu.Lock()
+	defer decompressorsMu.Unlock()
+	decompressors = append([]decompressor{{append([]byte{}, magic...), d, nil}}, decompressors...)
+}
+
+// Decompress detects the compression format of the data from its magic bytes and returns a reader
+// of the decompressed data. gzip, zlib and bzip2 are supported by default. Other formats (eg. zstd and snappy)
+// can be added using RegisterDecompressor. If the compression format is not recognized, the data is
+// returned unchanged.
+//
+// Example:
+//
+//  f, _ := os.Open("data.csv.gz")
+//  r, _ := imports.Decompress(f)
+//  defer r.Close()
+//
+//  df, err := imports.LoadFromCSV(ctx, r)
+//
+// NOTE: The returned reader does not implement io.Seeker.
+func Decompress(r io.Reader) (io.ReadCloser, error) {
+
+	br := bufio.NewReader(r)
+
+	decompressorsMu.RLock()
+	defer decompressorsMu.RUnlock()
+
+	for _, d := range decompressors {
+		magic, _ := br.Peek(len(d.magic))
+		if !bytes.Equal(magic, d.magic) {
+			continue
+		}
+
+		if d.next != nil {
+			b, _ := br.Peek(len(d.magic) + 1)
+			if len(b) <= len(d.magic) || !d.next(b[len(d.magic)]) {
+				continue
+			}
+		}
+
+		return d.fn(br)
+	}
+
+	return ioutil.NopCloser(br), nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package imports
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// bzip2Data is "a,b\n1,2\n" compressed using bzip2.
+var bzip2Data = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0xbf, 0x87,
+	0x40, 0x7f, 0x00, 0x00, 0x03, 0x59, 0x00, 0x00, 0x10, 0x00, 0x04, 0x30,
+	0x00, 0x30, 0x00, 0x20, 0x00, 0x30, 0xc0, 0x08, 0x69, 0xb2, 0x88, 0x23,
+	0x27, 0x8b, 0xb9, 0x22, 0x9c, 0x28, 0x48, 0x5f, 0xc3, 0xa0, 0x3f, 0x80,
+}
+
+func TestDecompress(t *testing.T) {
+	ctx := context.Background()
+
+	data := "a,b\n1,2\n"
+
+	compress := func(newWriter func(w io.Writer) io.WriteCloser) []byte {
+		var buf bytes.Buffer
+		w := newWriter(&buf)
+		w.Write([]byte(data))
+		w.Close()
+		return buf.Bytes()
+	}
+
+	zlibLevel := func(level int) func(w io.Writer) io.WriteCloser {
+		return func(w io.Writer) io.WriteCloser {
+			zw, _ := zlib.NewWriterLevel(w, level)
+			return zw
+		}
+	}
+
+	tests := []struct {
+		name     string
+		input    []byte
+		expected string
+	}{
+		{"gzip", compress(func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }), data},
+		{"zlib", compress(zlibLevel(zlib.DefaultCompression)), data},
+		{"zlib best speed", compress(zlibLevel(zlib.BestSpeed)), data},
+		{"zlib best compression", compress(zlibLevel(zlib.BestCompression)), data},
+		{"bzip2", bzip2Data, data},
+		{"none", []byte(data), data},
+		{"bzip2 magic without block size", []byte("BZhello\n"), "BZhello\n"},
+		{"short", []byte("BZh"), "BZh"},
+		{"empty", []byte{}, ""},
+	}
+
+	for _, tc := range tests {
+		r, err := Decompress(bytes.NewReader(tc.input))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+
+		out, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if string(out) != tc.expected {
+			t.Errorf("%s: expected: %q actual: %q", tc.name, tc.expected, string(out))
+		}
+	}
+
+	// The decompressed data can be loaded
+	r, err := Decompress(bytes.NewReader(bzip2Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	df, err := LoadFromCSV(ctx, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDataFrame(t, "csv", df, expectedDF{[]string{"a", "b"}, nil, [][]interface{}{{"1", "2"}}})
+}
+
+func TestRegisterDecompressor(t *testing.T) {
+
+	// A trivial format: the magic bytes followed by the data in upper case
+	RegisterDecompressor([]byte("UP!"), func(r io.Reader) (io.ReadCloser, error) {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.NopCloser(strings.NewReader(strings.ToLower(string(b[3:])))), nil
+	})
+
+	r, err := Decompress(strings.NewReader("UP!A,B\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ := ioutil.ReadAll(r)
+	if string(out) != "a,b\n" {
+		t.Errorf("expected: %q actual: %q", "a,b\n", string(out))
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected panic for empty magic")
+			}
+		}()
+		RegisterDecompressor(nil, nil)
+	}()
+}
@@ -3,11 +3,14 @@
 package imports
 
 import (
+	"bytes"
 	"context"
 	"encoding/csv"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"strconv"
+	"strings"
 	"time"
 
 	dataframe "github.com/rocketlaunchr/dataframe-go"
@@ -45,64 +48,226 @@ type CSVLoadOptions struct {
 	// The value for a given key must be of the data type of the data.
 	// eg. For a string use "". For a int64 use int64(0). What is relevant is the data type and not the value itself.
 	//
+	// The keys are the field names after Rename is applied.
+	//
 	// NOTE: A custom Series must implement NewSerieser interface and be able to interpret strings to work.
 	DictateDataType map[string]interface{}
 
+	// Schema can be used in place of DictateDataType. The data type of each field is determined by its Type.
+	// DictateDataType takes precedence for fields found in both.
+	//
+	// NOTE: Nullable and Constraints are not checked. Use Schema.Validate after loading.
+	Schema *dataframe.Schema
+
 	// NilValue allows you to set what string value in the CSV file should be interpreted as a nil value for
 	// the purposes of insertion.
 	//
 	// Common values are: NULL, \N, NaN, NA
 	NilValue *string
+
+	// NilValues allows you to set additional string values that should be interpreted as nil values.
+	NilValues []string
+
+	// Headers is used to set the field names when the csv file does not contain headings.
+	// When set, every row (after SkipRows) is treated as data and HeaderRow is ignored.
+	Headers []string
+
+	// HeaderRow is the row containing the headings. All rows above it are ignored.
+	// The default is 0 (the first row).
+	HeaderRow int
+
+	// SkipRows is the number of rows to skip after the headings.
+	SkipRows int
+
+	// SkipFooter is the number of rows to ignore at the end of the csv file.
+	SkipFooter int
+
+	// Limit is the maximum number of rows to load. The default (0) loads every row.
+	Limit int
+
+	// Columns is used to load a subset of the fields. Each item can be the name of the field (string) or the position
+	// of the field (int). The starting index is 0. The fields are loaded in the order provided.
+	// When not set, every field is loaded.
+	Columns []interface{}
+
+	// Rename is used to rename fields on load. The key is the field name in the csv file and the value is
+	// the new name. An error is returned if the loaded field names are not unique.
+	Rename map[string]string
+
+	// ThousandsSeparator, if not 0, is removed from int64 and float64 values before they are parsed.
+	// eg. ',' for "1,234,567.89"
+	//
+	// NOTE: It must be different from the decimal separator. eg. When it is '.', DecimalSeparator must also be set.
+	ThousandsSeparator rune
+
+	// DecimalSeparator, if not 0, is the decimal point used by float64 values. eg. ',' for "1234,56"
+	// The default is '.'.
+	DecimalSeparator rune
+
+	// ParseFuncs is used to convert the values of a field to a typed value. The key is the field name
+	// (after Rename is applied). Nil values are not passed to the function.
+	//
+	// The returned value must be compatible with the Series created using DictateDataType.
+	// If the field is not dictated, a SeriesMixed is created.
+	ParseFuncs map[string]func(string) (interface{}, error)
+
+	// InferDataTypes will infer the data type of each field that is not dictated by DictateDataType.
+	// When not set, fields that are not dictated are loaded as strings.
+	//
+	// Fields with a ParseFunc are not inferred. The first InferSampleSize rows are sampled. When LargeDataSet is set, every row is sampled.
+	// See InferCSVDataTypes for details.
+	//
+	// NOTE: If a row that was not sampled can't be converted to the inferred data type, an error is returned.
+	InferDataTypes bool
+
+	// InferSampleSize is the number of rows sampled when inferring data types.
+	// The default is 1000.
+	InferSampleSize int
+
+	// TimeLayouts are the candidate layouts (see time.Parse) used when inferring time.Time fields.
+	// When not set, DefaultTimeLayouts is used.
+	TimeLayouts []string
+
+	// InferReport, if not nil, is populated with the results of inferring the data types.
+	InferReport *CSVInferReport
+
+	// Workers is the number of goroutines used to parse the csv file. When greater than 1, the file is split into
+	// byte ranges on record boundaries which are parsed and converted concurrently. The result is identical to
+	// parsing the file serially. If Workers is negative, runtime.NumCPU() goroutines are used.
+	//
+	// NOTE: LoadFromCSVInChunks ignores this option.
+	Workers int
 }
 
 // LoadFromCSV will load data from a csv file.
-func LoadFromCSV(ctx context.Context, r io.ReadSeeker, options ...CSVLoadOptions) (*dataframe.DataFrame, error) {
+//
+// r does not need to implement io.Seeker. However, if it doesn't and LargeDataSet or Workers is set,
+// the entire csv file is read into memory first.
+func LoadFromCSV(ctx context.Context, r io.Reader, options ...CSVLoadOptions) (*dataframe.DataFrame, error) {
+
+	if len(options) > 0 && options[0].Schema != nil {
+		opts := options[0]
+		dictated, err := schemaDataTypes(opts.Schema, opts.DictateDataType)
+		if err != nil {
+			return nil, err
+		}
+		opts.DictateDataType, opts.Schema = dictated, nil
+		options = []CSVLoadOptions{opts}
+	}
 
-	var init *dataframe.SeriesInit
+	var (
+		init *dataframe.SeriesInit
+		inf  *csvInferer
+	)
 
-	cr := csv.NewReader(r)
-	cr.ReuseRecord = true
 	if len(options) > 0 {
-		cr.Comma = options[0].Comma
-		if cr.Comma == 0 {
-			cr.Comma = ','
+		parallel := options[0].Workers > 1 || options[0].Workers < 0
+
+		rs, seekable := r.(io.ReadSeeker)
+		if !seekable && (options[0].LargeDataSet || parallel) {
+			b, err := ioutil.ReadAll(r)
+			if err != nil {
+				return nil, err
+			}
+			rs = bytes.NewReader(b)
+			r, seekable = rs, true
 		}
-		cr.Comment = options[0].Comment
-		cr.TrimLeadingSpace = options[0].TrimLeadingSpace
 
 		// Count how many rows we have in order to preallocate underlying slices
-		if options[0].LargeDataSet {
+		if options[0].LargeDataSet && (!parallel || options[0].InferDataTypes) {
 			init = &dataframe.SeriesInit{}
-			for {
-				if err := ctx.Err(); err != nil {
+			if options[0].InferDataTypes {
+				// Sample every row while counting
+				var err error
+				inf, err = inferCSV(ctx, newCSVRecordReader(r, options...), 0, options...)
+				if err != nil && err != dataframe.ErrNoRows {
+					return nil, err
+				}
+				if inf != nil {
+					init.Size = inf.rows
+				}
+			} else {
+				rr := newCSVRecordReader(r, options...)
+				if _, err := rr.header(); err != nil && err != dataframe.ErrNoRows {
 					return nil, err
 				}
+				for {
+					if err := ctx.Err(); err != nil {
+						return nil, err
+					}
 
-				_, err := cr.Read()
-				if err != nil {
-					if err == io.EOF {
-						r.Seek(0, io.SeekStart)
-						break
+					_, err := rr.Read()
+					if err != nil {
+						if err == io.EOF {
+							break
+						}
+						return nil, err
 					}
-					return nil, err
+					init.Size++
 				}
-				init.Size++
 			}
-			if init.Size > 0 {
-				init.Size-- // Remove the space allocated for the "heading"
+			rs.Seek(0, io.SeekStart)
+		} else if options[0].InferDataTypes {
+			sampleSize := defaultInferSampleSize
+			if options[0].InferSampleSize > 0 {
+				sampleSize = options[0].InferSampleSize
 			}
+
+			var buf bytes.Buffer
+
+			sr := r
+			if !seekable {
+				// Record the data read while sampling
+				sr = io.TeeReader(r, &buf)
+			}
+
+			var err error
+			inf, err = inferCSV(ctx, newCSVRecordReader(sr, options...), sampleSize, options...)
+			if err != nil && err != dataframe.ErrNoRows {
+				return nil, err
+			}
+
+			if seekable {
+				rs.Seek(0, io.SeekStart)
+			} else {
+				// Replay the data read while sampling
+				r = io.MultiReader(&buf, r)
+			}
+		}
+
+		if inf != nil && options[0].InferReport != nil {
+			*options[0].InferReport = *inf.report()
 		}
+
+		if parallel {
+			// The number of rows is known once the byte ranges are parsed
+			return loadFromCSVParallel(ctx, rs, options[0].Workers, inf, options...)
+		}
+	}
+
+	rr := newCSVRecordReader(r, options...)
+
+	// Determine the headings
+	names, err := rr.header()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := newCSVFields(names, options...)
+	if inf != nil {
+		inf.merge(fields)
 	}
 
-	var row int
-	var df *dataframe.DataFrame
+	// Create the dataframe
+	df := dataframe.NewDataFrame(fields.series(init)...)
 
+	row := 1 // The heading is row 0
 	for {
 		if err := ctx.Err(); err != nil {
 			return nil, err
 		}
 
-		rec, err := cr.Read()
+		rec, err := rr.Read()
 		if err != nil {
 			if err == io.EOF {
 				break
@@ -110,144 +275,538 @@ func LoadFromCSV(ctx context.Context, r io.ReadSeeker, options ...CSVLoadOptions
 			return nil, err
 		}
 
-		if row == 0 {
-			// First row contains headings
+		insertVals, err := fields.record(rec, row)
+		if err != nil {
+			return nil, err
+		}
+
+		if init == nil {
+			df.Append(&dataframe.DontLock, insertVals...)
+		} else {
+			df.UpdateRow(row-1, &dataframe.DontLock, insertVals...)
+		}
+		row++
+	}
 
-			seriess := []dataframe.Series{}
+	return df, nil
+}
 
-			// Create the series
-			for _, name := range rec {
+// LoadFromCSVInChunks will load data from a csv file in chunks of chunkSize rows.
+// It returns an iterator that returns a new DataFrame containing the next chunk of rows each time it is called.
+// When there are no more rows, the iterator returns nil. If the csv file doesn't contain the headings,
+// the iterator returns dataframe.ErrNoRows.
+//
+// Unlike LoadFromCSV, the entire file is never held in memory, which makes it suitable for very large files.
+// Every DataFrame has the same Series (with the same names and data types) which are fixed
+// by the headings and DictateDataType. The LargeDataSet option is ignored.
+//
+// When InferDataTypes is set, the data types are inferred from the first chunk.
+//
+// Example:
+//
+//  iterator := imports.LoadFromCSVInChunks(ctx, r, 10000)
+//
+//  for {
+//     df, err := iterator()
+//     if err != nil {
+//        return err
+//     }
+//     if df == nil {
+//        break
+//     }
+//     fmt.Println(df.NRows())
+//  }
+//
+func LoadFromCSVInChunks(ctx context.Context, r io.Reader, chunkSize int, options ...CSVLoadOptions) func() (*dataframe.DataFrame, error) {
+
+	if chunkSize <= 0 {
+		panic("chunkSize must be greater than 0")
+	}
 
-				// Check if we know what the datatype should be. Otherwise assume string
-				if len(options) > 0 && len(options[0].DictateDataType) > 0 {
+	if len(options) > 0 && options[0].Schema != nil {
+		opts := options[0]
+		dictated, err := schemaDataTypes(opts.Schema, opts.DictateDataType)
+		if err != nil {
+			return func() (*dataframe.DataFrame, error) { return nil, err }
+		}
+		opts.DictateDataType, opts.Schema = dictated, nil
+		options = []CSVLoadOptions{opts}
+	}
 
-					typ, exists := options[0].DictateDataType[name]
-					if !exists {
-						seriess = append(seriess, dataframe.NewSeriesString(name, init))
-						continue
+	var (
+		row        int
+		done       bool
+		fields     *csvFields
+		pending    [][]string // records read while inferring data types
+		pendingErr error
+	)
+
+	rr := newCSVRecordReader(r, options...)
+
+	read := func() ([]string, error) {
+		if len(pending) > 0 {
+			rec := pending[0]
+			pending = pending[1:]
+			return rec, nil
+		}
+		if pendingErr != nil {
+			return nil, pendingErr
+		}
+		return rr.Read()
+	}
+
+	return func() (*dataframe.DataFrame, error) {
+		if done {
+			return nil, nil
+		}
+
+		if row == 0 {
+			// Determine the headings
+			names, err := rr.header()
+			if err != nil {
+				done = true
+				return nil, err
+			}
+
+			fields = newCSVFields(names, options...)
+			row++
+
+			if len(options) > 0 && options[0].InferDataTypes {
+				// Infer the data types from the first chunk
+				inf := newCSVInferer(names, options...)
+				for len(pending) < chunkSize {
+					if err := ctx.Err(); err != nil {
+						done = true
+						return nil, err
 					}
 
-					switch T := typ.(type) {
-					case float64:
-						seriess = append(seriess, dataframe.NewSeriesFloat64(name, init))
-					case int64, bool:
-						seriess = append(seriess, dataframe.NewSeriesInt64(name, init))
-					case string:
-						seriess = append(seriess, dataframe.NewSeriesString(name, init))
-					case time.Time:
-						seriess = append(seriess, dataframe.NewSeriesTime(name, init))
-					case dataframe.NewSerieser:
-						seriess = append(seriess, T.NewSeries(name, init))
-					case Converter:
-						switch T.ConcreteType.(type) {
-						case time.Time:
-							seriess = append(seriess, dataframe.NewSeriesTime(name, init))
-						default:
-							seriess = append(seriess, dataframe.NewSeriesGeneric(name, T.ConcreteType, init))
-						}
-					default:
-						seriess = append(seriess, dataframe.NewSeriesGeneric(name, typ, init))
+					rec, err := rr.Read()
+					if err != nil {
+						pendingErr = err
+						break
 					}
-				} else {
-					seriess = append(seriess, dataframe.NewSeriesString(name, init))
+					rec = append([]string{}, rec...)
+					inf.add(rec)
+					pending = append(pending, rec)
 				}
 
+				inf.merge(fields)
+				if options[0].InferReport != nil {
+					*options[0].InferReport = *inf.report()
+				}
 			}
+		}
 
-			// Create the dataframe
-			df = dataframe.NewDataFrame(seriess...)
-		} else {
+		var df *dataframe.DataFrame
 
-			insertVals := []interface{}{}
-			for idx, v := range rec {
+		for {
+			if err := ctx.Err(); err != nil {
+				done = true
+				return nil, err
+			}
 
-				// Check if v represents a nil value
-				if len(options) > 0 && options[0].NilValue != nil {
-					if v == *options[0].NilValue {
-						insertVals = append(insertVals, nil)
-						continue
-					}
+			rec, err := read()
+			if err != nil {
+				done = true
+				if err == io.EOF {
+					break
 				}
+				return nil, err
+			}
 
-				if len(options) > 0 && len(options[0].DictateDataType) > 0 {
-
-					name := df.Names(dataframe.DontLock)[idx]
-
-					// Check if a datatype is dictated
-					typ, exists := options[0].DictateDataType[name]
-					if !exists {
-						// Store value as a string
-						insertVals = append(insertVals, v)
-					} else {
-
-						switch T := typ.(type) {
-						case string:
-							insertVals = append(insertVals, v)
-						case bool:
-							if v == "TRUE" || v == "true" || v == "1" {
-								insertVals = append(insertVals, int64(1))
-							} else if v == "FALSE" || v == "false" || v == "0" {
-								insertVals = append(insertVals, int64(0))
-							} else {
-								return nil, fmt.Errorf("can't force string: %s to bool. row: %d field: %s", v, row-1, name)
-							}
-						case int64:
-							i, err := strconv.ParseInt(v, 10, 64)
-							if err != nil {
-								return nil, fmt.Errorf("can't force string: %s to int64. row: %d field: %s", v, row-1, name)
-							}
-							insertVals = append(insertVals, i)
-						case float64:
-							f, err := strconv.ParseFloat(v, 64)
-							if err != nil {
-								return nil, fmt.Errorf("can't force string: %s to float64. row: %d field: %s", v, row-1, name)
-							}
-							insertVals = append(insertVals, f)
-						case time.Time:
-							t, err := time.Parse(time.RFC3339, v)
-							if err != nil {
-								// Assume unix timestamp
-								sec, err := strconv.ParseInt(v, 10, 64)
-								if err != nil {
-									return nil, fmt.Errorf("can't force string: %s to time.Time (%s). row: %d field: %s", v, time.RFC3339, row-1, name)
-								}
-								insertVals = append(insertVals, time.Unix(sec, 0))
-							} else {
-								insertVals = append(insertVals, t)
-							}
-						case dataframe.NewSerieser:
-							insertVals = append(insertVals, v)
-						case Converter:
-							cv, err := T.ConverterFunc(v)
-							if err != nil {
-								return nil, fmt.Errorf("can't force string: %s to generic data type. row: %d field: %s", v, row-1, name)
-							}
-							insertVals = append(insertVals, cv)
-						default:
-							insertVals = append(insertVals, v)
-						}
-					}
+			if df == nil {
+				df = dataframe.NewDataFrame(fields.series(&dataframe.SeriesInit{Capacity: chunkSize})...)
+			}
+
+			insertVals, err := fields.record(rec, row)
+			if err != nil {
+				done = true
+				return nil, err
+			}
+			df.Append(&dataframe.DontLock, insertVals...)
+			row++
+
+			if df.NRows(dataframe.DontLock) == chunkSize {
+				break
+			}
+		}
+
+		return df, nil
+	}
+}
+
+// newCSVReader creates a csv.Reader configured using the options.
+func newCSVReader(r io.Reader, options ...CSVLoadOptions) *csv.Reader {
+
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	if len(options) > 0 {
+		cr.Comma = options[0].Comma
+		if cr.Comma == 0 {
+			cr.Comma = ','
+		}
+		cr.Comment = options[0].Comment
+		cr.TrimLeadingSpace = options[0].TrimLeadingSpace
+	}
+
+	return cr
+}
+
+// csvRecordReader reads the records of a csv file. It applies the options that determine which
+// rows and fields are loaded: Headers, HeaderRow, SkipRows, SkipFooter, Limit, Columns and Rename.
+type csvRecordReader struct {
+	cr   *csv.Reader
+	opts CSVLoadOptions
+
+	cols    []int // positions of the loaded fields. nil means every field is loaded.
+	nFields int   // number of fields in each record. 0 means it is checked by the csv.Reader.
+	limit   int
+	footer  int
 
-				} else {
-					// Store value as a string
-					insertVals = append(insertVals, v)
+	lookahead [][]string // records that may belong to the footer
+	count     int        // number of records returned
+	buf       []string
+}
+
+func newCSVRecordReader(r io.Reader, options ...CSVLoadOptions) *csvRecordReader {
+
+	rr := &csvRecordReader{cr: newCSVReader(r, options...)}
+
+	if len(options) > 0 {
+		rr.opts = options[0]
+		rr.limit = rr.opts.Limit
+		rr.footer = rr.opts.SkipFooter
+	}
+
+	if rr.opts.Headers != nil || rr.opts.HeaderRow > 0 || rr.opts.SkipRows > 0 || rr.footer > 0 {
+		// The skipped rows may have a different number of fields
+		rr.cr.FieldsPerRecord = -1
+	}
+
+	return rr
+}
+
+// header reads the headings (unless Headers is set) and skips the rows after them.
+// It returns the names of the loaded fields.
+func (rr *csvRecordReader) header() ([]string, error) {
+
+	decimal := rr.opts.DecimalSeparator
+	if decimal == 0 {
+		decimal = '.'
+	}
+	if rr.opts.ThousandsSeparator != 0 && rr.opts.ThousandsSeparator == decimal {
+		return nil, fmt.Errorf("ThousandsSeparator can't be the same as the decimal separator: %q", decimal)
+	}
+
+	var names []string
+
+	if rr.opts.Headers != nil {
+		names = append([]string{}, rr.opts.Headers...)
+	} else {
+		for i := 0; i <= rr.opts.HeaderRow; i++ {
+			rec, err := rr.cr.Read()
+			if err != nil {
+				if err == io.EOF {
+					return nil, dataframe.ErrNoRows
+				}
+				return nil, err
+			}
+			names = append(names[:0], rec...)
+		}
+	}
+
+	for i := 0; i < rr.opts.SkipRows; i++ {
+		_, err := rr.cr.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+	}
+
+	if rr.cr.FieldsPerRecord < 0 {
+		rr.nFields = len(names)
+	}
+
+	// Determine which fields are loaded
+	if len(rr.opts.Columns) > 0 {
+		rr.cols = []int{}
+		selected := []string{}
+
+		for _, c := range rr.opts.Columns {
+			switch c := c.(type) {
+			case string:
+				idx := -1
+				for i, name := range names {
+					if name == c {
+						idx = i
+						break
+					}
+				}
+				if idx < 0 {
+					return nil, fmt.Errorf("column not found: %s", c)
+				}
+				rr.cols = append(rr.cols, idx)
+			case int:
+				if c < 0 || c >= len(names) {
+					return nil, fmt.Errorf("column not found: %d", c)
 				}
+				rr.cols = append(rr.cols, c)
+			default:
+				return nil, fmt.Errorf("invalid column: %T", c)
+			}
+			selected = append(selected, names[rr.cols[len(rr.cols)-1]])
+		}
+		names = selected
+	}
+
+	for idx, name := range names {
+		if newName, exists := rr.opts.Rename[name]; exists {
+			names[idx] = newName
+		}
+	}
+
+	seen := map[string]struct{}{}
+	for _, name := range names {
+		if _, exists := seen[name]; exists {
+			return nil, fmt.Errorf("duplicate field name: %s", name)
+		}
+		seen[name] = struct{}{}
+	}
+
+	return names, nil
+}
+
+// fork returns a csvRecordReader that reads data records from r using the same fields as rr.
+// The returned reader does not apply SkipFooter or Limit. header must be called before fork.
+func (rr *csvRecordReader) fork(r io.Reader) *csvRecordReader {
 
+	cr := newCSVReader(r, rr.opts)
+	cr.FieldsPerRecord = rr.cr.FieldsPerRecord
+
+	return &csvRecordReader{
+		cr:      cr,
+		opts:    rr.opts,
+		cols:    rr.cols,
+		nFields: rr.nFields,
+	}
+}
+
+// Read returns the next record. The returned slice may be reused by the next call to Read.
+func (rr *csvRecordReader) Read() ([]string, error) {
+
+	if rr.limit > 0 && rr.count >= rr.limit {
+		return nil, io.EOF
+	}
+
+	var rec []string
+
+	if rr.footer == 0 {
+		var err error
+		rec, err = rr.cr.Read()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		for len(rr.lookahead) <= rr.footer {
+			rec, err := rr.cr.Read()
+			if err != nil {
+				// At the end of the file, the remaining records are the footer
+				return nil, err
 			}
+			rr.lookahead = append(rr.lookahead, append([]string{}, rec...))
+		}
+		rec = rr.lookahead[0]
+		rr.lookahead = rr.lookahead[1:]
+	}
+
+	rr.count++
+	return rr.project(rec, rr.count)
+}
 
-			if init == nil {
-				df.Append(&dataframe.DontLock, insertVals...)
+// project checks the number of fields in rec and returns the loaded fields.
+// row is the position of the record, where the heading is row 0.
+func (rr *csvRecordReader) project(rec []string, row int) ([]string, error) {
+
+	if rr.nFields > 0 && len(rec) != rr.nFields {
+		return nil, fmt.Errorf("wrong number of fields. row: %d", row-1)
+	}
+
+	if rr.cols == nil {
+		return rec, nil
+	}
+
+	rr.buf = rr.buf[:0]
+	for _, idx := range rr.cols {
+		rr.buf = append(rr.buf, rec[idx])
+	}
+	return rr.buf, nil
+}
+
+// csvFields contains what is required to convert the loaded fields of a record.
+type csvFields struct {
+	names    []string
+	types    []interface{} // nil means the data type was not dictated and the values will be stored as strings
+	parsers  []func(string) (interface{}, error)
+	blankNil []bool // empty values are interpreted as nil
+
+	nilValues []string
+	thousands rune
+	decimal   rune
+}
+
+func newCSVFields(names []string, options ...CSVLoadOptions) *csvFields {
+
+	fields := &csvFields{
+		names:    names,
+		types:    make([]interface{}, len(names)),
+		parsers:  make([]func(string) (interface{}, error), len(names)),
+		blankNil: make([]bool, len(names)),
+	}
+
+	if len(options) > 0 {
+		// Check if we know what the datatype should be. Otherwise assume string
+		for idx, name := range names {
+			fields.types[idx] = options[0].DictateDataType[name]
+			fields.parsers[idx] = options[0].ParseFuncs[name]
+		}
+
+		if options[0].NilValue != nil {
+			fields.nilValues = append(fields.nilValues, *options[0].NilValue)
+		}
+		fields.nilValues = append(fields.nilValues, options[0].NilValues...)
+		fields.thousands = options[0].ThousandsSeparator
+		fields.decimal = options[0].DecimalSeparator
+	}
+
+	return fields
+}
+
+// series creates a Series for each field.
+func (fields *csvFields) series(init *dataframe.SeriesInit) []dataframe.Series {
+
+	seriess := []dataframe.Series{}
+
+	for idx, name := range fields.names {
+		if fields.types[idx] == nil {
+			if fields.parsers[idx] != nil {
+				seriess = append(seriess, dataframe.NewSeriesMixed(name, init))
 			} else {
-				df.UpdateRow(row-1, &dataframe.DontLock, insertVals...)
+				seriess = append(seriess, dataframe.NewSeriesString(name, init))
 			}
+		} else {
+			seriess = append(seriess, newSeries(name, fields.types[idx], init))
+		}
+	}
 
+	return seriess
+}
+
+// isNil returns true if v represents a nil value.
+func (fields *csvFields) isNil(v string) bool {
+	for _, nv := range fields.nilValues {
+		if v == nv {
+			return true
 		}
-		row++
 	}
+	return false
+}
 
-	if df == nil {
-		return nil, dataframe.ErrNoRows
+// number removes the thousands separator from v and replaces the decimal separator with a '.'.
+func (fields *csvFields) number(v string) string {
+	if fields.thousands != 0 {
+		v = strings.Replace(v, string(fields.thousands), "", -1)
 	}
+	if fields.decimal != 0 && fields.decimal != '.' {
+		v = strings.Replace(v, string(fields.decimal), ".", -1)
+	}
+	return v
+}
 
-	return df, nil
+// record converts a record from the csv file to the values that are inserted into the DataFrame.
+// row is the position of the record, where the heading is row 0.
+func (fields *csvFields) record(rec []string, row int) ([]interface{}, error) {
+
+	insertVals := make([]interface{}, 0, len(rec))
+	for idx, v := range rec {
+
+		// Check if v represents a nil value
+		if fields.isNil(v) || (v == "" && fields.blankNil[idx]) {
+			insertVals = append(insertVals, nil)
+			continue
+		}
+
+		if parser := fields.parsers[idx]; parser != nil {
+			val, err := parser(v)
+			if err != nil {
+				return nil, fmt.Errorf("can't parse string: %s (%v). row: %d field: %s", v, err, row-1, fields.names[idx])
+			}
+			insertVals = append(insertVals, val)
+			continue
+		}
+
+		switch fields.types[idx].(type) {
+		case int64, float64:
+			v = fields.number(v)
+		}
+
+		val, err := csvValue(v, fields.types[idx], row, fields.names[idx])
+		if err != nil {
+			return nil, err
+		}
+		insertVals = append(insertVals, val)
+	}
+
+	return insertVals, nil
+}
+
+// csvValue converts a field from the csv file to the data type typ.
+// When typ is nil, v is stored as a string.
+func csvValue(v string, typ interface{}, row int, name string) (interface{}, error) {
+
+	switch T := typ.(type) {
+	case nil, string:
+		return v, nil
+	case bool:
+		if v == "TRUE" || v == "true" || v == "1" {
+			return int64(1), nil
+		} else if v == "FALSE" || v == "false" || v == "0" {
+			return int64(0), nil
+		}
+		return nil, fmt.Errorf("can't force string: %s to bool. row: %d field: %s", v, row-1, name)
+	case int64:
+		i, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("can't force string: %s to int64. row: %d field: %s", v, row-1, name)
+		}
+		return i, nil
+	case float64:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("can't force string: %s to float64. row: %d field: %s", v, row-1, name)
+		}
+		return f, nil
+	case time.Time:
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			// Assume unix timestamp
+			sec, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("can't force string: %s to time.Time (%s). row: %d field: %s", v, time.RFC3339, row-1, name)
+			}
+			return time.Unix(sec, 0), nil
+		}
+		return t, nil
+	case dataframe.NewSerieser:
+		return v, nil
+	case Converter:
+		cv, err := T.ConverterFunc(v)
+		if err != nil {
+			return nil, fmt.Errorf("can't force string: %s to generic data type. row: %d field: %s", v, row-1, name)
+		}
+		return cv, nil
+	default:
+		return v, nil
+	}
 }
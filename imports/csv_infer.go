@@ -0,0 +1,325 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package imports
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"time"
+)
+
+// DefaultTimeLayouts are the layouts used to infer time.Time fields when CSVLoadOptions.TimeLayouts is not set.
+var DefaultTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// defaultInferSampleSize is the number of rows sampled when CSVLoadOptions.InferSampleSize is not set.
+const defaultInferSampleSize = 1000
+
+// CSVInferReport contains the results of inferring the data types of the fields in a csv file.
+type CSVInferReport struct {
+
+	// DataTypes contains the inferred data type of each field that was not dictated.
+	// It uses the same conventions as DictateDataType, so it can be used as DictateDataType
+	// for subsequent imports of similar files.
+	//
+	// NOTE: A time.Time field is represented by a Converter.
+	DataTypes map[string]interface{}
+
+	// TimeLayouts contains the layout of each field that was inferred to be a time.Time.
+	TimeLayouts map[string]string
+
+	// Fallbacks contains the rows with values that forced a field to fall back to
+	// a more general data type.
+	Fallbacks []CSVInferFallback
+
+	// Rows is the number of rows that were sampled.
+	Rows int
+}
+
+// CSVInferFallback records a value that forced a field to fall back to a more general data type.
+// eg. A field that only contained integers, until a row containing 3.14 made it float64.
+type CSVInferFallback struct {
+
+	// Row is the row that contained Value. The first row after the headings is 0.
+	Row int
+
+	// Field is the name of the field.
+	Field string
+
+	// Value is the value that caused the fallback.
+	Value string
+
+	// From is the data type before the fallback: int64, float64, bool or time.
+	From string
+
+	// To is the data type after the fallback: float64, bool, time or string.
+	To string
+}
+
+// InferCSVDataTypes will infer the data type of each field in a csv file by sampling the first
+// InferSampleSize rows. The data types are chosen from int64, float64, bool, time.Time (using TimeLayouts)
+// and string, in that order of preference.
+//
+// Fields dictated by DictateDataType are not inferred. Values matching NilValue and empty values are ignored.
+// When the csv file is loaded, empty values of a field that is not inferred to be a string are interpreted as nil.
+func InferCSVDataTypes(ctx context.Context, r io.Reader, options ...CSVLoadOptions) (*CSVInferReport, error) {
+
+	if len(options) > 0 && options[0].Schema != nil {
+		opts := options[0]
+		dictated, err := schemaDataTypes(opts.Schema, opts.DictateDataType)
+		if err != nil {
+			return nil, err
+		}
+		opts.DictateDataType, opts.Schema = dictated, nil
+		options = []CSVLoadOptions{opts}
+	}
+
+	sampleSize := defaultInferSampleSize
+	if len(options) > 0 && options[0].InferSampleSize > 0 {
+		sampleSize = options[0].InferSampleSize
+	}
+
+	inf, err := inferCSV(ctx, newCSVRecordReader(r, options...), sampleSize, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return inf.report(), nil
+}
+
+// inferCSV reads the headings and then samples up to sampleSize records from rr.
+// When sampleSize is 0, every record is sampled.
+func inferCSV(ctx context.Context, rr *csvRecordReader, sampleSize int, options ...CSVLoadOptions) (*csvInferer, error) {
+
+	names, err := rr.header()
+	if err != nil {
+		return nil, err
+	}
+
+	inf := newCSVInferer(names, options...)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		rec, err := rr.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		inf.add(rec)
+		if inf.rows == sampleSize {
+			break
+		}
+	}
+
+	return inf, nil
+}
+
+// csvInferer infers the data types of fields one record at a time.
+type csvInferer struct {
+	names     []string
+	dictated  []bool
+	fields    *csvFields
+	layouts   []string
+	inferred  []csvFieldInference
+	fallbacks []CSVInferFallback
+	rows      int
+}
+
+// csvFieldInference keeps track of which data types are still possible for a field.
+// The candidates are (in order): int64, float64, bool, followed by each time layout.
+type csvFieldInference struct {
+	candidates []bool
+	best       int
+	seen       bool
+}
+
+func newCSVInferer(names []string, options ...CSVLoadOptions) *csvInferer {
+
+	inf := &csvInferer{
+		names:    names,
+		dictated: make([]bool, len(names)),
+		fields:   newCSVFields(names, options...),
+		layouts:  DefaultTimeLayouts,
+		inferred: make([]csvFieldInference, len(names)),
+	}
+
+	if len(options) > 0 && len(options[0].TimeLayouts) > 0 {
+		inf.layouts = options[0].TimeLayouts
+	}
+
+	for idx := range names {
+		inf.dictated[idx] = inf.fields.types[idx] != nil || inf.fields.parsers[idx] != nil
+	}
+
+	for idx := range inf.inferred {
+		candidates := make([]bool, 3+len(inf.layouts))
+		for i := range candidates {
+			candidates[i] = true
+		}
+		inf.inferred[idx].candidates = candidates
+	}
+
+	return inf
+}
+
+// add updates the inferred data types using a record from the csv file.
+func (inf *csvInferer) add(rec []string) {
+
+	for idx, v := range rec {
+		if idx >= len(inf.inferred) || inf.dictated[idx] {
+			continue
+		}
+
+		// Check if v represents a nil value. Empty values are also ignored.
+		if v == "" || inf.fields.isNil(v) {
+			continue
+		}
+
+		f := &inf.inferred[idx]
+
+		for i, possible := range f.candidates {
+			if !possible {
+				continue
+			}
+
+			switch i {
+			case 0:
+				_, err := strconv.ParseInt(inf.fields.number(v), 10, 64)
+				f.candidates[i] = err == nil
+			case 1:
+				_, err := strconv.ParseFloat(inf.fields.number(v), 64)
+				f.candidates[i] = err == nil
+			case 2:
+				f.candidates[i] = v == "true" || v == "false" || v == "TRUE" || v == "FALSE"
+			default:
+				_, err := time.Parse(inf.layouts[i-3], v)
+				f.candidates[i] = err == nil
+			}
+		}
+
+		best := len(f.candidates) // string
+		for i, possible := range f.candidates {
+			if possible {
+				best = i
+				break
+			}
+		}
+
+		if f.seen && best != f.best {
+			inf.fallbacks = append(inf.fallbacks, CSVInferFallback{
+				Row:   inf.rows,
+				Field: inf.names[idx],
+				Value: v,
+				From:  inf.typeName(f.best),
+				To:    inf.typeName(best),
+			})
+		}
+
+		f.best = best
+		f.seen = true
+	}
+
+	inf.rows++
+}
+
+func (inf *csvInferer) typeName(candidate int) string {
+	switch candidate {
+	case 0:
+		return "int64"
+	case 1:
+		return "float64"
+	case 2:
+		return "bool"
+	case 3 + len(inf.layouts):
+		return "string"
+	default:
+		return "time"
+	}
+}
+
+// merge sets the inferred data type of each field of fields whose data type is nil.
+// Empty values of the fields that are not inferred to be strings are interpreted as nil.
+func (inf *csvInferer) merge(fields *csvFields) {
+	for idx, typ := range inf.types() {
+		if idx < len(fields.types) && fields.types[idx] == nil {
+			fields.types[idx] = typ
+			if _, ok := typ.(string); !ok && typ != nil {
+				fields.blankNil[idx] = true
+			}
+		}
+	}
+}
+
+// types returns the data type of each field. Dictated fields are nil.
+func (inf *csvInferer) types() []interface{} {
+
+	types := make([]interface{}, len(inf.inferred))
+
+	for idx, f := range inf.inferred {
+		if inf.dictated[idx] {
+			continue
+		}
+
+		if !f.seen {
+			// Only nil values
+			types[idx] = ""
+			continue
+		}
+
+		switch f.best {
+		case 0:
+			types[idx] = int64(0)
+		case 1:
+			types[idx] = float64(0)
+		case 2:
+			types[idx] = true
+		case 3 + len(inf.layouts):
+			types[idx] = ""
+		default:
+			layout := inf.layouts[f.best-3]
+			types[idx] = Converter{
+				ConcreteType: time.Time{},
+				ConverterFunc: func(in interface{}) (interface{}, error) {
+					return time.Parse(layout, in.(string))
+				},
+			}
+		}
+	}
+
+	return types
+}
+
+func (inf *csvInferer) report() *CSVInferReport {
+
+	rep := &CSVInferReport{
+		DataTypes:   map[string]interface{}{},
+		TimeLayouts: map[string]string{},
+		Fallbacks:   inf.fallbacks,
+		Rows:        inf.rows,
+	}
+
+	for idx, typ := range inf.types() {
+		if inf.dictated[idx] {
+			continue
+		}
+
+		name := inf.names[idx]
+		rep.DataTypes[name] = typ
+		if _, ok := typ.(Converter); ok {
+			rep.TimeLayouts[name] = inf.layouts[inf.inferred[idx].best-3]
+		}
+	}
+
+	return rep
+}
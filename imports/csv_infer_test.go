@@ -0,0 +1,127 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package imports
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInferCSVDataTypes(t *testing.T) {
+	ctx := context.Background()
+
+	nilValue := "NA"
+
+	tests := []struct {
+		data      string
+		options   CSVLoadOptions
+		types     map[string]string // int64, float64, bool, time (with layout) or string
+		fallbacks []CSVInferFallback
+	}{
+		{
+			"i,f,b,t,d,s\n1,1.5,true,2020-01-02T03:04:05Z,2020-01-02,x\n-2,3,FALSE,2021-01-02T03:04:05+10:00,2021-12-31,1\n",
+			CSVLoadOptions{},
+			map[string]string{"i": "int64", "f": "float64", "b": "bool", "t": "time " + time.RFC3339, "d": "time 2006-01-02", "s": "string"},
+			nil,
+		},
+		{
+			// A value that forces a fallback
+			"a,b\n1,true\n2.5,1\n",
+			CSVLoadOptions{},
+			map[string]string{"a": "float64", "b": "string"},
+			[]CSVInferFallback{
+				{Row: 1, Field: "a", Value: "2.5", From: "int64", To: "float64"},
+				{Row: 1, Field: "b", Value: "1", From: "bool", To: "string"},
+			},
+		},
+		{
+			// Empty values and NilValue are ignored
+			"a,b,c\n1,,NA\n,x,\n3,,NA\n",
+			CSVLoadOptions{NilValue: &nilValue},
+			map[string]string{"a": "int64", "b": "string", "c": "string"},
+			nil,
+		},
+		{
+			// Only the sampled rows are inferred
+			"a\n1\n2\nx\n",
+			CSVLoadOptions{InferSampleSize: 2},
+			map[string]string{"a": "int64"},
+			nil,
+		},
+		{
+			// Dictated fields and fields with a ParseFunc are not inferred
+			"a,b,c\n1,2,3\n",
+			CSVLoadOptions{DictateDataType: map[string]interface{}{"a": ""}, ParseFuncs: map[string]func(string) (interface{}, error){"b": func(v string) (interface{}, error) { return v, nil }}},
+			map[string]string{"c": "int64"},
+			nil,
+		},
+		{
+			"a,b\n1.234,5\n1.234.567,6\n",
+			CSVLoadOptions{ThousandsSeparator: '.', DecimalSeparator: ','},
+			map[string]string{"a": "int64", "b": "int64"},
+			nil,
+		},
+		{
+			"a,b\n02/01/2020,15:04\n",
+			CSVLoadOptions{TimeLayouts: []string{"02/01/2006", "15:04"}},
+			map[string]string{"a": "time 02/01/2006", "b": "time 15:04"},
+			nil,
+		},
+	}
+
+	for i, tc := range tests {
+		rep, err := InferCSVDataTypes(ctx, strings.NewReader(tc.data), tc.options)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %v", i, err)
+			continue
+		}
+
+		types := map[string]string{}
+		for name, typ := range rep.DataTypes {
+			switch typ.(type) {
+			case int64:
+				types[name] = "int64"
+			case float64:
+				types[name] = "float64"
+			case bool:
+				types[name] = "bool"
+			case string:
+				types[name] = "string"
+			case Converter:
+				types[name] = "time " + rep.TimeLayouts[name]
+			default:
+				types[name] = "unknown"
+			}
+		}
+
+		if !reflect.DeepEqual(types, tc.types) {
+			t.Errorf("%d: wrong types. expected: %v actual: %v", i, tc.types, types)
+		}
+		if len(rep.Fallbacks) != 0 || len(tc.fallbacks) != 0 {
+			if !reflect.DeepEqual(rep.Fallbacks, tc.fallbacks) {
+				t.Errorf("%d: wrong fallbacks. expected: %v actual: %v", i, tc.fallbacks, rep.Fallbacks)
+			}
+		}
+	}
+}
+
+func TestLoadFromCSVInferEmpty(t *testing.T) {
+	ctx := context.Background()
+
+	data := "a,b,c\n1,,x\n,2.5,\n3,,\n"
+
+	df, err := LoadFromCSV(ctx, strings.NewReader(data), CSVLoadOptions{InferDataTypes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Empty values of fields that are not inferred to be strings are nil
+	checkDataFrame(t, "infer", df, expectedDF{
+		[]string{"a", "b", "c"},
+		[]string{"int64", "float64", "string"},
+		[][]interface{}{{int64(1), nil, "x"}, {nil, 2.5, ""}, {int64(3), nil, ""}},
+	})
+}
@@ -0,0 +1,320 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package imports
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"io"
+	"io/ioutil"
+	"runtime"
+	"sync"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+// csvChunksPerWorker is the number of byte ranges created for each worker.
+// More byte ranges than workers keeps every worker busy when some byte ranges are slower to parse.
+const csvChunksPerWorker = 4
+
+// csvByteRange is a section of a csv file that starts and ends on a record boundary.
+type csvByteRange struct {
+	start, end int64
+	line       int // number of lines before start
+}
+
+// csvChunk is the result of parsing and converting a csvByteRange.
+type csvChunk struct {
+	rows  [][]interface{}
+	count int // number of records in the byte range
+
+	// convRec is the first record that could not be converted. It was at position len(rows)
+	// in the byte range. The remaining records are counted but not converted.
+	convRec []string
+
+	// err is an error that prevented the remaining records from being read.
+	err error
+}
+
+// loadFromCSVParallel parses and converts byte ranges of the csv file concurrently. The result is identical
+// to the serial path in LoadFromCSV.
+func loadFromCSVParallel(ctx context.Context, r io.ReadSeeker, workers int, inf *csvInferer, options ...CSVLoadOptions) (*dataframe.DataFrame, error) {
+
+	if workers < 0 {
+		workers = runtime.NumCPU()
+	}
+
+	opts := options[0]
+
+	// Records before the data
+	skip := opts.SkipRows
+	if opts.Headers == nil {
+		skip = skip + opts.HeaderRow + 1
+	}
+
+	// Find the record boundaries
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	header, ranges, err := csvByteRanges(ctx, r, opts.Comment, skip, workers*csvChunksPerWorker)
+	if err != nil {
+		return nil, err
+	}
+
+	ra, ok := r.(io.ReaderAt)
+	if !ok {
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		ra = bytes.NewReader(b)
+	}
+
+	// Read the headings
+	rr := newCSVRecordReader(io.NewSectionReader(ra, 0, header), options...)
+	names, err := rr.header()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := newCSVFields(names, options...)
+	if inf != nil {
+		inf.merge(fields)
+	}
+
+	// Parse and convert each byte range
+	chunks := make([]csvChunk, len(ranges))
+
+	var wg sync.WaitGroup
+	jobs := make(chan int, len(ranges))
+	for i := range ranges {
+		jobs <- i
+	}
+	close(jobs)
+
+	for w := 0; w < workers && w < len(ranges); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				br := ranges[i]
+				chunks[i] = parseCSVByteRange(ctx, rr.fork(io.NewSectionReader(ra, br.start, br.end-br.start)), br.line, fields)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Determine the number of records and the first errors
+	var (
+		total   int
+		convAt  = -1 // position of the first record that could not be converted
+		convRec []string
+		readErr error // occurred while reading record total
+	)
+
+	for i := range chunks {
+		if chunks[i].convRec != nil && convAt < 0 {
+			convAt = total + len(chunks[i].rows)
+			convRec = chunks[i].convRec
+		}
+		total = total + chunks[i].count
+		if chunks[i].err != nil {
+			readErr = chunks[i].err
+			break
+		}
+	}
+
+	// The serial path converts a record after reading SkipFooter more records
+	// and stops reading once Limit records are converted.
+	nRows := total - opts.SkipFooter
+	if nRows < 0 {
+		nRows = 0
+	}
+	if opts.Limit > 0 && nRows > opts.Limit {
+		nRows = opts.Limit
+	}
+
+	if convAt >= 0 && convAt < nRows {
+		// Recreate the error using the position of the record in the file
+		rec, err := rr.project(convRec, convAt+1)
+		if err != nil {
+			return nil, err
+		}
+		_, err = fields.record(rec, convAt+1)
+		return nil, err
+	}
+
+	if readErr != nil && (opts.Limit <= 0 || total < opts.Limit+opts.SkipFooter) {
+		return nil, readErr
+	}
+
+	// Assemble the Series in order
+	df := dataframe.NewDataFrame(fields.series(&dataframe.SeriesInit{Capacity: nRows})...)
+
+	row := 0
+	for i := range chunks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		for _, insertVals := range chunks[i].rows {
+			if row == nRows {
+				return df, nil
+			}
+			df.Append(&dataframe.DontLock, insertVals...)
+			row++
+		}
+		chunks[i].rows = nil
+	}
+
+	return df, nil
+}
+
+// parseCSVByteRange parses and converts the records in a byte range.
+func parseCSVByteRange(ctx context.Context, rr *csvRecordReader, line int, fields *csvFields) csvChunk {
+
+	var chunk csvChunk
+
+	for {
+		if err := ctx.Err(); err != nil {
+			chunk.err = err
+			return chunk
+		}
+
+		raw, err := rr.cr.Read()
+		if err != nil {
+			if err == io.EOF {
+				return chunk
+			}
+			if pe, ok := err.(*csv.ParseError); ok {
+				// Make line numbers relative to the start of the file
+				pe.StartLine = pe.StartLine + line
+				pe.Line = pe.Line + line
+			}
+			chunk.err = err
+			return chunk
+		}
+		chunk.count++
+
+		if chunk.convRec != nil {
+			continue
+		}
+
+		rec, err := rr.project(raw, len(chunk.rows)+1)
+		if err == nil {
+			var insertVals []interface{}
+			insertVals, err = fields.record(rec, len(chunk.rows)+1)
+			if err == nil {
+				chunk.rows = append(chunk.rows, insertVals)
+				continue
+			}
+		}
+		chunk.convRec = append([]string{}, raw...)
+	}
+}
+
+// csvByteRanges finds the end of the headings and splits the remainder of the csv file into
+// approximately n byte ranges that start and end on record boundaries. The headings are the first skip records.
+//
+// Record boundaries are found by counting quotes, so newlines inside quoted fields are respected.
+// Blank lines and comment lines before the headings are skipped, as encoding/csv does.
+func csvByteRanges(ctx context.Context, r io.ReadSeeker, comment rune, skip int, n int) (int64, []csvByteRange, error) {
+
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, nil, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return 0, nil, err
+	}
+
+	var commentPrefix []byte
+	if comment != 0 {
+		commentPrefix = []byte(string(comment))
+	}
+
+	var (
+		offset    int64
+		line      int
+		inQuote   bool
+		lineStart = true
+		skipLine  bool  // current line is a comment line
+		header    int64 = -1
+		target    int64
+		ranges    []csvByteRange
+		skipped   int
+	)
+
+	if skip == 0 {
+		header = 0
+		target = size / int64(n)
+		ranges = append(ranges, csvByteRange{})
+	}
+
+	br := bufio.NewReaderSize(r, 1<<16)
+
+	for {
+		seg, err := br.ReadSlice('\n')
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return 0, nil, err
+		}
+
+		if len(seg) > 0 {
+			if lineStart && !inQuote {
+				skipLine = commentPrefix != nil && bytes.HasPrefix(seg, commentPrefix)
+			}
+			if !skipLine && bytes.Count(seg, []byte{'"'})%2 == 1 {
+				inQuote = !inQuote
+			}
+			blank := lineStart && (len(bytes.TrimRight(seg, "\r\n")) == 0)
+
+			offset = offset + int64(len(seg))
+			lineStart = seg[len(seg)-1] == '\n'
+
+			if lineStart {
+				line++
+
+				if !inQuote && !skipLine && !blank {
+					// End of a record
+					if header < 0 {
+						skipped++
+						if skipped == skip {
+							header = offset
+							target = header + (size-header)/int64(n)
+							ranges = append(ranges, csvByteRange{start: header, line: line})
+						}
+
+						if err := ctx.Err(); err != nil {
+							return 0, nil, err
+						}
+					} else if offset >= target && offset < size {
+						ranges[len(ranges)-1].end = offset
+						ranges = append(ranges, csvByteRange{start: offset, line: line})
+						target = offset + (size-header)/int64(n)
+
+						if err := ctx.Err(); err != nil {
+							return 0, nil, err
+						}
+					}
+				}
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	if header < 0 {
+		// The file ends before the data
+		return offset, nil, nil
+	}
+	ranges[len(ranges)-1].end = offset
+
+	return header, ranges, nil
+}
@@ -0,0 +1,415 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package imports
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+var csvBenchSize = flag.Int64("csv-bench-size", 1<<30, "size in bytes of the csv file used by the csv benchmarks")
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	code := m.Run()
+	if csvBenchFile != "" {
+		os.Remove(csvBenchFile)
+	}
+	os.Exit(code)
+}
+
+func generateCSV(rows int) string {
+	var sb strings.Builder
+
+	sb.WriteString("# comment with a \" quote\n")
+	sb.WriteString("id,name,price,when,note\n")
+	for i := 0; i < rows; i++ {
+		if i%97 == 0 {
+			sb.WriteString("\n")
+		}
+
+		price := fmt.Sprintf("%d.%d", i, i%10)
+		if i%13 == 0 {
+			price = "NA"
+		}
+
+		note := "plain"
+		switch i % 5 {
+		case 1:
+			note = "\"multi\nline\""
+		case 2:
+			note = "\"with \"\"quotes\"\" and, comma\""
+		case 3:
+			note = "\"\n\""
+		}
+
+		fmt.Fprintf(&sb, "%d,name%d,%s,%s,%s\n", i, i, price, time.Unix(int64(i)*3600, 0).UTC().Format(time.RFC3339), note)
+	}
+
+	return sb.String()
+}
+
+func TestLoadFromCSVWorkers(t *testing.T) {
+	ctx := context.Background()
+	nilValue := "NA"
+
+	dictate := map[string]interface{}{
+		"id":    int64(0),
+		"price": float64(0),
+		"when":  time.Time{},
+	}
+
+	tests := []struct {
+		data    string
+		options CSVLoadOptions
+	}{
+		{generateCSV(1000), CSVLoadOptions{Comment: '#', NilValue: &nilValue, DictateDataType: dictate}},
+		{generateCSV(1000), CSVLoadOptions{Comment: '#', NilValue: &nilValue, InferDataTypes: true}},
+		{generateCSV(3), CSVLoadOptions{Comment: '#', NilValue: &nilValue, DictateDataType: dictate}},
+		{generateCSV(0), CSVLoadOptions{Comment: '#'}},
+		{"a,b", CSVLoadOptions{}},
+		{"a,b\n1,2", CSVLoadOptions{}},
+		{"", CSVLoadOptions{}},
+		{strings.Replace(generateCSV(1000), "name900,", "name900,x", 1), CSVLoadOptions{Comment: '#', NilValue: &nilValue, DictateDataType: dictate}},
+		{strings.Replace(generateCSV(1000), "name900,", "name900,1,", 1), CSVLoadOptions{Comment: '#', NilValue: &nilValue, DictateDataType: dictate}},
+		{strings.Replace(generateCSV(1000), "name900,", "name\"900,", 1), CSVLoadOptions{Comment: '#', NilValue: &nilValue, DictateDataType: dictate}},
+		{"junk\n" + generateCSV(500), CSVLoadOptions{Comment: '#', HeaderRow: 1, SkipRows: 3, SkipFooter: 7, NilValues: []string{"NA", "plain"}, DictateDataType: dictate}},
+		{"junk\n" + generateCSV(500), CSVLoadOptions{Comment: '#', HeaderRow: 1, SkipRows: 3, SkipFooter: 7, Limit: 100, NilValue: &nilValue, DictateDataType: dictate}},
+		{"junk\n" + generateCSV(500), CSVLoadOptions{Comment: '#', HeaderRow: 1, SkipFooter: 7, Limit: 495, NilValue: &nilValue, DictateDataType: dictate}},
+		{generateCSV(500), CSVLoadOptions{Comment: '#', NilValue: &nilValue, Columns: []interface{}{"price", 0, "note"}, Rename: map[string]string{"price": "cost"}, DictateDataType: map[string]interface{}{"cost": float64(0)}}},
+		{generateCSV(500), CSVLoadOptions{Comment: '#', NilValue: &nilValue, ThousandsSeparator: '.', DecimalSeparator: ',', InferDataTypes: true}},
+		{generateCSV(500)[strings.Index(generateCSV(500), "\n0,"):], CSVLoadOptions{Headers: []string{"a", "b", "c", "d", "e"}, Limit: 50}},
+		{strings.Replace(generateCSV(1000), "name900,", "name900,x", 1), CSVLoadOptions{Comment: '#', NilValue: &nilValue, DictateDataType: dictate, Limit: 100}},
+		{strings.Replace(generateCSV(1000), "name999,", "name999,x", 1), CSVLoadOptions{Comment: '#', NilValue: &nilValue, DictateDataType: dictate, SkipFooter: 1}},
+		{strings.Replace(generateCSV(1000), "name999,", "name999,1,", 1), CSVLoadOptions{Comment: '#', NilValue: &nilValue, DictateDataType: dictate, SkipFooter: 2}},
+		{strings.Replace(generateCSV(1000), "name900,", "name900,1,", 1), CSVLoadOptions{Comment: '#', NilValue: &nilValue, DictateDataType: dictate, SkipFooter: 2}},
+	}
+
+	for i, tc := range tests {
+		expected, expectedErr := LoadFromCSV(ctx, strings.NewReader(tc.data), tc.options)
+
+		for _, workers := range []int{2, 3, 8, -1} {
+			opts := tc.options
+			opts.Workers = workers
+
+			df, err := LoadFromCSV(ctx, strings.NewReader(tc.data), opts)
+			if fmt.Sprint(err) != fmt.Sprint(expectedErr) {
+				t.Errorf("%d (workers: %d): wrong error. expected: %v actual: %v", i, workers, expectedErr, err)
+				continue
+			}
+			if err != nil {
+				continue
+			}
+
+			if df.NRows() != expected.NRows() {
+				t.Errorf("%d (workers: %d): wrong number of rows. expected: %d actual: %d", i, workers, expected.NRows(), df.NRows())
+				continue
+			}
+
+			for j, s := range df.Series {
+				es := expected.Series[j]
+				if s.Name() != es.Name() || s.Type() != es.Type() {
+					t.Errorf("%d (workers: %d): wrong series. expected: %s %s actual: %s %s", i, workers, es.Name(), es.Type(), s.Name(), s.Type())
+					continue
+				}
+				for row := 0; row < s.NRows(); row++ {
+					if s.ValueString(row) != es.ValueString(row) {
+						t.Errorf("%d (workers: %d): wrong value in %s row %d. expected: %s actual: %s", i, workers, s.Name(), row, es.ValueString(row), s.ValueString(row))
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+var (
+	csvBenchOnce sync.Once
+	csvBenchFile string
+)
+
+// benchCSVFile creates a csv file of approximately csv-bench-size bytes.
+func benchCSVFile(b *testing.B) string {
+	csvBenchOnce.Do(func() {
+		f, err := ioutil.TempFile("", "dataframe-bench-*.csv")
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer f.Close()
+
+		w := bufio.NewWriter(f)
+		chunk := generateCSV(10000)
+		w.WriteString(chunk)
+		body := chunk[strings.Index(chunk, "\nid,")+1:]
+		body = body[strings.Index(body, "\n")+1:]
+		for written := int64(len(chunk)); written < *csvBenchSize; written = written + int64(len(body)) {
+			w.WriteString(body)
+		}
+		if err := w.Flush(); err != nil {
+			b.Fatal(err)
+		}
+		csvBenchFile = f.Name()
+	})
+	return csvBenchFile
+}
+
+func benchmarkLoadFromCSV(b *testing.B, workers int) {
+	name := benchCSVFile(b)
+	nilValue := "NA"
+
+	opts := CSVLoadOptions{
+		Comment:  '#',
+		NilValue: &nilValue,
+		DictateDataType: map[string]interface{}{
+			"id":    int64(0),
+			"price": float64(0),
+			"when":  time.Time{},
+		},
+		Workers: workers,
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f, err := os.Open(name)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_, err = LoadFromCSV(context.Background(), f, opts)
+		f.Close()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoadFromCSVSerial(b *testing.B) { benchmarkLoadFromCSV(b, 0) }
+
+func BenchmarkLoadFromCSVWorkers4(b *testing.B) { benchmarkLoadFromCSV(b, 4) }
+
+func BenchmarkLoadFromCSVWorkersNumCPU(b *testing.B) { benchmarkLoadFromCSV(b, -1) }
+
+func TestLoadFromCSVSchema(t *testing.T) {
+	ctx := context.Background()
+
+	data := "id,price,at,note\n1,1.5,15:04:05,a\n2,,00:00:01.5,b\n"
+
+	schema := &dataframe.Schema{Fields: []dataframe.SchemaField{
+		{Name: "id", Type: "int64"},
+		{Name: "price", Type: "float64", Nullable: true},
+		{Name: "at", Type: "time.Duration"},
+		{Name: "note", Type: "string"},
+	}}
+
+	df, err := LoadFromCSV(ctx, strings.NewReader(data), CSVLoadOptions{Schema: schema, NilValues: []string{""}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := schema.Validate(ctx, df); err != nil {
+		t.Errorf("expected valid: %v", err)
+	}
+	if df.Series[2].Value(1) != 1500*time.Millisecond {
+		t.Errorf("wrong value: %v", df.Series[2].Value(1))
+	}
+
+	// DictateDataType takes precedence
+	df, err = LoadFromCSV(ctx, strings.NewReader(data), CSVLoadOptions{Schema: schema, NilValues: []string{""}, DictateDataType: map[string]interface{}{"id": ""}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if df.Series[0].Type() != "string" {
+		t.Errorf("expected DictateDataType to override Schema")
+	}
+
+	// Unknown type
+	schema.Fields[0].Type = "unknown"
+	if _, err := LoadFromCSV(ctx, strings.NewReader(data), CSVLoadOptions{Schema: schema}); err == nil {
+		t.Errorf("expected error for unknown type")
+	}
+}
+
+func TestLoadFromCSVInChunks(t *testing.T) {
+	ctx := context.Background()
+	nilValue := "NA"
+
+	dictate := map[string]interface{}{
+		"id":    int64(0),
+		"price": float64(0),
+		"when":  time.Time{},
+	}
+
+	tests := []struct {
+		data      string
+		chunkSize int
+		options   CSVLoadOptions
+	}{
+		{generateCSV(100), 7, CSVLoadOptions{Comment: '#', NilValue: &nilValue, DictateDataType: dictate}},
+		{generateCSV(100), 25, CSVLoadOptions{Comment: '#', NilValue: &nilValue, DictateDataType: dictate}}, // exact multiple
+		{generateCSV(100), 100, CSVLoadOptions{Comment: '#', NilValue: &nilValue, DictateDataType: dictate}},
+		{generateCSV(100), 1000, CSVLoadOptions{Comment: '#', NilValue: &nilValue, DictateDataType: dictate}},
+		{generateCSV(100), 30, CSVLoadOptions{Comment: '#', NilValue: &nilValue, InferDataTypes: true}},
+		{generateCSV(100), 9, CSVLoadOptions{Comment: '#', NilValue: &nilValue, SkipRows: 3, SkipFooter: 5, Limit: 50}},
+		{generateCSV(1), 1, CSVLoadOptions{Comment: '#', DictateDataType: dictate}},
+		{generateCSV(1), 5, CSVLoadOptions{Comment: '#', InferDataTypes: true}},
+		{generateCSV(0), 5, CSVLoadOptions{Comment: '#'}},
+		{"", 5, CSVLoadOptions{}},
+		{strings.Replace(generateCSV(100), "name50,", "name50,x", 1), 10, CSVLoadOptions{Comment: '#', NilValue: &nilValue, DictateDataType: dictate}},
+	}
+
+	for i, tc := range tests {
+		expected, expectedErr := LoadFromCSV(ctx, strings.NewReader(tc.data), tc.options)
+
+		iterator := LoadFromCSVInChunks(ctx, strings.NewReader(tc.data), tc.chunkSize, tc.options)
+
+		var (
+			chunks []*dataframe.DataFrame
+			err    error
+		)
+		for {
+			var df *dataframe.DataFrame
+			df, err = iterator()
+			if err != nil || df == nil {
+				break
+			}
+			chunks = append(chunks, df)
+		}
+
+		if df, err := iterator(); df != nil || err != nil {
+			t.Errorf("%d: expected iterator to be exhausted", i)
+		}
+
+		if expectedErr != nil {
+			if expectedErr == dataframe.ErrNoRows {
+				// No chunks are returned. ErrNoRows is returned if there are no headings.
+				if (err != nil && err != dataframe.ErrNoRows) || len(chunks) != 0 {
+					t.Errorf("%d: expected no chunks. actual: %d %v", i, len(chunks), err)
+				}
+			} else if fmt.Sprint(err) != fmt.Sprint(expectedErr) {
+				t.Errorf("%d: wrong error. expected: %v actual: %v", i, expectedErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %v", i, err)
+			continue
+		}
+
+		nRows := 0
+		for j, chunk := range chunks {
+			if j < len(chunks)-1 && chunk.NRows() != tc.chunkSize {
+				t.Errorf("%d: wrong chunk size. expected: %d actual: %d", i, tc.chunkSize, chunk.NRows())
+			}
+			if chunk.NRows() == 0 {
+				t.Errorf("%d: empty chunk", i)
+			}
+
+			for col, s := range chunk.Series {
+				es := expected.Series[col]
+				if s.Name() != es.Name() || s.Type() != es.Type() {
+					t.Errorf("%d: wrong series. expected: %s %s actual: %s %s", i, es.Name(), es.Type(), s.Name(), s.Type())
+					continue
+				}
+				for row := 0; row < s.NRows(); row++ {
+					if s.ValueString(row) != es.ValueString(nRows+row) {
+						t.Errorf("%d: wrong value in %s row %d. expected: %s actual: %s", i, s.Name(), nRows+row, es.ValueString(nRows+row), s.ValueString(row))
+						break
+					}
+				}
+			}
+			nRows += chunk.NRows()
+		}
+
+		if nRows != expected.NRows() {
+			t.Errorf("%d: wrong number of rows. expected: %d actual: %d", i, expected.NRows(), nRows)
+		}
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected panic for chunkSize 0")
+			}
+		}()
+		LoadFromCSVInChunks(ctx, strings.NewReader(""), 0)
+	}()
+}
+
+func TestLoadFromCSVOptions(t *testing.T) {
+	ctx := context.Background()
+	nilValue := "NA"
+
+	base := "a,b,c\n1,x,1.5\n2,NA,2.5\n3,-,3.5\n4,y,4.5\n"
+	abc := []string{"a", "b", "c"}
+	strs := []string{"string", "string", "string"}
+
+	tests := []struct {
+		data     string
+		options  CSVLoadOptions
+		expected expectedDF
+		err      string
+	}{
+		{base, CSVLoadOptions{}, expectedDF{abc, strs, [][]interface{}{{"1", "x", "1.5"}, {"2", "NA", "2.5"}, {"3", "-", "3.5"}, {"4", "y", "4.5"}}}, ""},
+		{base, CSVLoadOptions{SkipRows: 1}, expectedDF{abc, strs, [][]interface{}{{"2", "NA", "2.5"}, {"3", "-", "3.5"}, {"4", "y", "4.5"}}}, ""},
+		{base, CSVLoadOptions{SkipFooter: 2}, expectedDF{abc, strs, [][]interface{}{{"1", "x", "1.5"}, {"2", "NA", "2.5"}}}, ""},
+		{base, CSVLoadOptions{Limit: 2}, expectedDF{abc, strs, [][]interface{}{{"1", "x", "1.5"}, {"2", "NA", "2.5"}}}, ""},
+		{base, CSVLoadOptions{SkipRows: 1, SkipFooter: 1, Limit: 1}, expectedDF{abc, strs, [][]interface{}{{"2", "NA", "2.5"}}}, ""},
+		{base, CSVLoadOptions{SkipRows: 2, SkipFooter: 2}, expectedDF{abc, strs, [][]interface{}{}}, ""},
+		{"title\n\n" + base + "total,,10\n", CSVLoadOptions{HeaderRow: 1, SkipFooter: 1}, expectedDF{abc, strs, [][]interface{}{{"1", "x", "1.5"}, {"2", "NA", "2.5"}, {"3", "-", "3.5"}, {"4", "y", "4.5"}}}, ""},
+		{base, CSVLoadOptions{Headers: []string{"p", "q", "r"}, Limit: 2}, expectedDF{[]string{"p", "q", "r"}, strs, [][]interface{}{{"a", "b", "c"}, {"1", "x", "1.5"}}}, ""},
+		{base, CSVLoadOptions{Columns: []interface{}{"c", 0}, Limit: 1}, expectedDF{[]string{"c", "a"}, nil, [][]interface{}{{"1.5", "1"}}}, ""},
+		{base, CSVLoadOptions{Rename: map[string]string{"a": "id", "z": "unused"}, Limit: 1}, expectedDF{[]string{"id", "b", "c"}, nil, [][]interface{}{{"1", "x", "1.5"}}}, ""},
+		{base, CSVLoadOptions{Columns: []interface{}{2}, Rename: map[string]string{"c": "price"}, DictateDataType: map[string]interface{}{"price": float64(0)}}, expectedDF{[]string{"price"}, []string{"float64"}, [][]interface{}{{1.5}, {2.5}, {3.5}, {4.5}}}, ""},
+		{base, CSVLoadOptions{NilValue: &nilValue, NilValues: []string{"-"}, Columns: []interface{}{"b"}}, expectedDF{[]string{"b"}, nil, [][]interface{}{{"x"}, {nil}, {nil}, {"y"}}}, ""},
+		{"a;b\n1;2\n", CSVLoadOptions{Comma: ';'}, expectedDF{[]string{"a", "b"}, nil, [][]interface{}{{"1", "2"}}}, ""},
+
+		// Separators
+		{"f,i\n\"1,234.5\",\"1,000\"\n-2,3\n", CSVLoadOptions{ThousandsSeparator: ',', DictateDataType: map[string]interface{}{"f": float64(0), "i": int64(0)}}, expectedDF{[]string{"f", "i"}, []string{"float64", "int64"}, [][]interface{}{{1234.5, int64(1000)}, {-2.0, int64(3)}}}, ""},
+		{"f;i\n1.234,5;1.000\n-2;3\n", CSVLoadOptions{Comma: ';', ThousandsSeparator: '.', DecimalSeparator: ',', DictateDataType: map[string]interface{}{"f": float64(0), "i": int64(0)}}, expectedDF{[]string{"f", "i"}, []string{"float64", "int64"}, [][]interface{}{{1234.5, int64(1000)}, {-2.0, int64(3)}}}, ""},
+		{"f\n\"1234,5\"\n", CSVLoadOptions{DecimalSeparator: ',', DictateDataType: map[string]interface{}{"f": float64(0)}}, expectedDF{[]string{"f"}, []string{"float64"}, [][]interface{}{{1234.5}}}, ""},
+		{"f\n1.5\n", CSVLoadOptions{ThousandsSeparator: '.'}, expectedDF{}, `ThousandsSeparator can't be the same as the decimal separator: '.'`},
+		{"f\n1.5\n", CSVLoadOptions{ThousandsSeparator: ',', DecimalSeparator: ','}, expectedDF{}, `ThousandsSeparator can't be the same as the decimal separator: ','`},
+
+		// Field names
+		{base, CSVLoadOptions{Rename: map[string]string{"a": "b"}}, expectedDF{}, "duplicate field name: b"},
+		{base, CSVLoadOptions{Rename: map[string]string{"a": "b", "b": "a"}, Limit: 1}, expectedDF{[]string{"b", "a", "c"}, nil, [][]interface{}{{"1", "x", "1.5"}}}, ""},
+		{"a,b,a\n1,2,3\n", CSVLoadOptions{}, expectedDF{}, "duplicate field name: a"},
+		{"a,b,a\n1,2,3\n", CSVLoadOptions{Rename: map[string]string{"a": "x"}}, expectedDF{}, "duplicate field name: x"},
+		{"a,b,a\n1,2,3\n", CSVLoadOptions{Columns: []interface{}{0, 1}}, expectedDF{[]string{"a", "b"}, nil, [][]interface{}{{"1", "2"}}}, ""},
+		{base, CSVLoadOptions{Columns: []interface{}{"a", 0}}, expectedDF{}, "duplicate field name: a"},
+		{base, CSVLoadOptions{Columns: []interface{}{"z"}}, expectedDF{}, "column not found: z"},
+		{base, CSVLoadOptions{Columns: []interface{}{1.5}}, expectedDF{}, "invalid column: float64"},
+		{base, CSVLoadOptions{Headers: []string{"p", "p", "q"}}, expectedDF{}, "duplicate field name: p"},
+	}
+
+	for i, tc := range tests {
+		for _, workers := range []int{0, 2} {
+			opts := tc.options
+			opts.Workers = workers
+			label := fmt.Sprintf("%d (workers: %d)", i, workers)
+
+			df, err := LoadFromCSV(ctx, strings.NewReader(tc.data), opts)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Errorf("%s: wrong error. expected: %s actual: %v", label, tc.err, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", label, err)
+				continue
+			}
+
+			checkDataFrame(t, label, df, tc.expected)
+		}
+	}
+}
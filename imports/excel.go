@@ -0,0 +1,402 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package imports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+	"github.com/tealeg/xlsx"
+)
+
+// ExcelLoadOptions is likely to change.
+type ExcelLoadOptions struct {
+
+	// Sheet is used to select which sheet to import. It can be the name of the sheet (string)
+	// or the position of the sheet (int). The starting index is 0.
+	// When not set, the first sheet is used.
+	//
+	// NOTE: This option is ignored by LoadSheetsFromExcel.
+	Sheet interface{}
+
+	// CellRange is used to import a subset of the sheet. eg. "B2:F100"
+	// When not set, the entire sheet is imported.
+	CellRange *string
+
+	// HeaderRow is the row containing the headings. It is relative to the top of CellRange.
+	// All rows above it are ignored. The default is 0 (the first row).
+	HeaderRow int
+
+	// DictateDataType is used to inform LoadFromExcel what the true underlying data type is for a given field name.
+	// The value for a given key must be of the data type of the data.
+	// eg. For a string use "". For a int64 use int64(0). What is relevant is the data type and not the value itself.
+	//
+	// When a field name is not dictated, the data type is inferred from the cells:
+	// numeric cells produce a SeriesFloat64, date cells a SeriesTime, boolean cells a SeriesInt64
+	// and everything else a SeriesString.
+	//
+	// NOTE: A custom Series must implement NewSerieser interface and be able to interpret strings to work.
+	DictateDataType map[string]interface{}
+
+	// Schema can be used in place of DictateDataType. The data type of each field is determined by its Type.
+	// DictateDataType takes precedence for fields found in both.
+	//
+	// NOTE: Nullable and Constraints are not checked. Use Schema.Validate after loading.
+	Schema *dataframe.Schema
+
+	// NilValue allows you to set what string value in the sheet should be interpreted as a nil value for
+	// the purposes of insertion. Empty cells are always interpreted as nil.
+	//
+	// Common values are: NULL, \N, NaN, NA
+	NilValue *string
+}
+
+// LoadFromExcel will load data from a sheet of an Excel (xlsx) file.
+// The first row (see HeaderRow) contains the headings. A blank heading is named after
+// its column letter (eg. "C"). An error is returned if the headings are not unique.
+func LoadFromExcel(ctx context.Context, r io.Reader, options ...ExcelLoadOptions) (*dataframe.DataFrame, error) {
+
+	var opts ExcelLoadOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	if opts.Schema != nil {
+		dictated, err := schemaDataTypes(opts.Schema, opts.DictateDataType)
+		if err != nil {
+			return nil, err
+		}
+		opts.DictateDataType = dictated
+	}
+
+	file, err := openExcel(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(file.Sheets) == 0 {
+		return nil, dataframe.ErrNoRows
+	}
+
+	var sheet *xlsx.Sheet
+
+	switch s := opts.Sheet.(type) {
+	case nil:
+		sheet = file.Sheets[0]
+	case string:
+		var exists bool
+		sheet, exists = file.Sheet[s]
+		if !exists {
+			return nil, fmt.Errorf("sheet not found: %s", s)
+		}
+	case int:
+		if s < 0 || s >= len(file.Sheets) {
+			return nil, fmt.Errorf("sheet not found: %d", s)
+		}
+		sheet = file.Sheets[s]
+	default:
+		return nil, errors.New("Sheet must be a string or int")
+	}
+
+	return loadExcelSheet(ctx, file, sheet, opts)
+}
+
+// LoadSheetsFromExcel will load every sheet of an Excel (xlsx) file.
+// The returned map is keyed by sheet name. Sheets that contain no rows are omitted.
+func LoadSheetsFromExcel(ctx context.Context, r io.Reader, options ...ExcelLoadOptions) (map[string]*dataframe.DataFrame, error) {
+
+	var opts ExcelLoadOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	if opts.Schema != nil {
+		dictated, err := schemaDataTypes(opts.Schema, opts.DictateDataType)
+		if err != nil {
+			return nil, err
+		}
+		opts.DictateDataType = dictated
+	}
+
+	file, err := openExcel(r)
+	if err != nil {
+		return nil, err
+	}
+
+	out := map[string]*dataframe.DataFrame{}
+
+	for _, sheet := range file.Sheets {
+		df, err := loadExcelSheet(ctx, file, sheet, opts)
+		if err != nil {
+			if err == dataframe.ErrNoRows {
+				continue
+			}
+			return nil, fmt.Errorf("sheet: %s: %w", sheet.Name, err)
+		}
+		out[sheet.Name] = df
+	}
+
+	return out, nil
+}
+
+func openExcel(r io.Reader) (*xlsx.File, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return xlsx.OpenBinary(b)
+}
+
+func loadExcelSheet(ctx context.Context, file *xlsx.File, sheet *xlsx.Sheet, opts ExcelLoadOptions) (*dataframe.DataFrame, error) {
+
+	// Determine boundaries of sheet
+	minRow, minCol := 0, 0
+	maxRow, maxCol := len(sheet.Rows)-1, sheet.MaxCol-1
+
+	if opts.CellRange != nil {
+		parts := strings.Split(*opts.CellRange, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid CellRange: %s", *opts.CellRange)
+		}
+
+		x1, y1, err := xlsx.GetCoordsFromCellIDString(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid CellRange: %s", *opts.CellRange)
+		}
+		x2, y2, err := xlsx.GetCoordsFromCellIDString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid CellRange: %s", *opts.CellRange)
+		}
+
+		if x1 > x2 || y1 > y2 {
+			return nil, fmt.Errorf("invalid CellRange: %s", *opts.CellRange)
+		}
+
+		minRow, minCol = y1, x1
+		if y2 < maxRow {
+			maxRow = y2
+		}
+		if x2 < maxCol {
+			maxCol = x2
+		}
+	}
+
+	headerRow := minRow + opts.HeaderRow
+	if opts.HeaderRow < 0 || headerRow > maxRow || minCol > maxCol {
+		return nil, dataframe.ErrNoRows
+	}
+
+	// Ignore empty rows at the end of the sheet
+	for maxRow > headerRow {
+		empty := true
+		for col := minCol; col <= maxCol; col++ {
+			if excelCell(sheet, maxRow, col) != nil {
+				empty = false
+				break
+			}
+		}
+		if !empty {
+			break
+		}
+		maxRow--
+	}
+
+	// Determine the headings
+	names := []string{}
+	seen := map[string]bool{} // name -> from a blank header cell
+	for col := minCol; col <= maxCol; col++ {
+		var name string
+
+		cell := excelCell(sheet, headerRow, col)
+		if cell == nil {
+			// Use the column letter
+			name = xlsx.ColIndexToLetters(col)
+		} else {
+			name = cell.String()
+		}
+
+		if blank, exists := seen[name]; exists {
+			if blank || cell == nil {
+				return nil, fmt.Errorf("blank header in column %s collides with header: %s", name, name)
+			}
+			return nil, fmt.Errorf("duplicate header: %s", name)
+		}
+		seen[name] = cell == nil
+		names = append(names, name)
+	}
+
+	// Determine the data type of each column
+	types := []interface{}{}
+	for idx, name := range names {
+		if typ, exists := opts.DictateDataType[name]; exists {
+			types = append(types, typ)
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var inferred interface{}
+		for row := headerRow + 1; row <= maxRow; row++ {
+			cell := excelCell(sheet, row, minCol+idx)
+			if cell == nil || (opts.NilValue != nil && cell.Value == *opts.NilValue) {
+				continue
+			}
+
+			var typ interface{}
+			switch cell.Type() {
+			case xlsx.CellTypeNumeric:
+				if cell.IsTime() {
+					typ = time.Time{}
+				} else {
+					typ = float64(0)
+				}
+			case xlsx.CellTypeDate:
+				typ = time.Time{}
+			case xlsx.CellTypeBool:
+				typ = true
+			default:
+				typ = ""
+			}
+
+			if inferred == nil {
+				inferred = typ
+			} else if inferred != typ {
+				// Mixed cell types
+				inferred = ""
+				break
+			}
+		}
+
+		if inferred == nil {
+			// No values in column
+			inferred = ""
+		}
+		types = append(types, inferred)
+	}
+
+	init := &dataframe.SeriesInit{Capacity: maxRow - headerRow}
+
+	seriess := []dataframe.Series{}
+	for idx, name := range names {
+		seriess = append(seriess, newSeries(name, types[idx], init))
+	}
+
+	// Create the dataframe
+	df := dataframe.NewDataFrame(seriess...)
+
+	for row := headerRow + 1; row <= maxRow; row++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		insertVals := []interface{}{}
+		for idx, name := range names {
+			cell := excelCell(sheet, row, minCol+idx)
+			if cell == nil || (opts.NilValue != nil && cell.Value == *opts.NilValue) {
+				insertVals = append(insertVals, nil)
+				continue
+			}
+
+			val, err := excelValue(cell, types[idx], file.Date1904)
+			if err != nil {
+				return nil, fmt.Errorf("%s. row: %d field: %s", err.Error(), row-headerRow-1, name)
+			}
+			insertVals = append(insertVals, val)
+		}
+
+		df.Append(&dataframe.DontLock, insertVals...)
+	}
+
+	return df, nil
+}
+
+// excelCell returns the cell at a given position. It returns nil if the cell is empty.
+func excelCell(sheet *xlsx.Sheet, row, col int) *xlsx.Cell {
+	if row >= len(sheet.Rows) || sheet.Rows[row] == nil {
+		return nil
+	}
+
+	cells := sheet.Rows[row].Cells
+	if col >= len(cells) || cells[col] == nil {
+		return nil
+	}
+
+	cell := cells[col]
+	if cell.Value == "" {
+		return nil
+	}
+	return cell
+}
+
+// excelValue converts the contents of a cell to the data type typ.
+func excelValue(cell *xlsx.Cell, typ interface{}, date1904 bool) (interface{}, error) {
+
+	switch T := typ.(type) {
+	case float64:
+		f, err := cell.Float()
+		if err != nil {
+			return nil, fmt.Errorf("can't force string: %s to float64", cell.Value)
+		}
+		return f, nil
+	case int64:
+		if cell.Type() == xlsx.CellTypeBool {
+			return int64(dataframe.B(cell.Bool())), nil
+		}
+		f, err := cell.Float()
+		if err != nil || f != float64(int64(f)) {
+			return nil, fmt.Errorf("can't force string: %s to int64", cell.Value)
+		}
+		return int64(f), nil
+	case bool:
+		if cell.Type() == xlsx.CellTypeBool {
+			return int64(dataframe.B(cell.Bool())), nil
+		}
+		switch cell.Value {
+		case "TRUE", "true", "1":
+			return int64(1), nil
+		case "FALSE", "false", "0":
+			return int64(0), nil
+		}
+		return nil, fmt.Errorf("can't force string: %s to bool", cell.Value)
+	case string:
+		return cell.String(), nil
+	case time.Time:
+		if cell.Type() == xlsx.CellTypeNumeric {
+			t, err := cell.GetTime(date1904)
+			if err != nil {
+				return nil, fmt.Errorf("can't force string: %s to time.Time", cell.Value)
+			}
+			return t, nil
+		}
+
+		t, err := time.Parse(time.RFC3339, cell.Value)
+		if err != nil {
+			// Assume unix timestamp
+			sec, err := strconv.ParseInt(cell.Value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("can't force string: %s to time.Time (%s)", cell.Value, time.RFC3339)
+			}
+			return time.Unix(sec, 0), nil
+		}
+		return t, nil
+	case dataframe.NewSerieser:
+		return cell.String(), nil
+	case Converter:
+		cv, err := T.ConverterFunc(cell.String())
+		if err != nil {
+			return nil, fmt.Errorf("can't force string: %s to generic data type", cell.Value)
+		}
+		return cv, nil
+	default:
+		return cell.String(), nil
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package imports
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tealeg/xlsx"
+)
+
+// newWorkbook creates an Excel file in memory. Each sheet is a list of rows.
+// A cell is set according to the data type of its value. A nil value leaves the cell blank.
+func newWorkbook(t *testing.T, sheets map[string][][]interface{}, order ...string) *bytes.Buffer {
+	t.Helper()
+
+	file := xlsx.NewFile()
+	for _, name := range order {
+		sheet, err := file.AddSheet(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, vals := range sheets[name] {
+			row := sheet.AddRow()
+			for _, val := range vals {
+				cell := row.AddCell()
+				switch v := val.(type) {
+				case nil:
+				case float64:
+					cell.SetFloat(v)
+				case bool:
+					cell.SetBool(v)
+				case time.Time:
+					cell.SetDateTime(v)
+				case string:
+					cell.SetString(v)
+				default:
+					t.Fatalf("unsupported cell value: %T", val)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := file.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestLoadFromExcel(t *testing.T) {
+	ctx := context.Background()
+
+	when := time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	sheets := map[string][][]interface{}{
+		"first": {
+			{"num", "date", "flag", "mixed", nil, "note"},
+			{1.5, when, true, 1.0, "x", "a"},
+			{nil, when.AddDate(0, 0, 1), false, "b", nil, nil},
+			{-2.0, nil, nil, true, "y", "NA"},
+		},
+		"second": {
+			{"id"},
+			{3.0},
+		},
+		"empty": {},
+	}
+	data := newWorkbook(t, sheets, "first", "second", "empty").Bytes()
+
+	df, err := LoadFromExcel(ctx, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if df.NRows() != 3 {
+		t.Fatalf("wrong number of rows. expected: 3 actual: %d", df.NRows())
+	}
+
+	expected := []struct {
+		name string
+		typ  string
+		vals []interface{}
+	}{
+		{"num", "float64", []interface{}{1.5, nil, -2.0}},
+		{"date", "time", []interface{}{when, when.AddDate(0, 0, 1), nil}},
+		{"flag", "int64", []interface{}{int64(1), int64(0), nil}},
+		{"mixed", "string", []interface{}{"1", "b", "TRUE"}},
+		{"E", "string", []interface{}{"x", nil, "y"}}, // blank header
+		{"note", "string", []interface{}{"a", nil, "NA"}},
+	}
+
+	if len(df.Series) != len(expected) {
+		t.Fatalf("wrong number of series. expected: %d actual: %d", len(expected), len(df.Series))
+	}
+
+	for i, e := range expected {
+		s := df.Series[i]
+		if s.Name() != e.name || s.Type() != e.typ {
+			t.Errorf("wrong series. expected: %s %s actual: %s %s", e.name, e.typ, s.Name(), s.Type())
+			continue
+		}
+		for row, ev := range e.vals {
+			v := s.Value(row)
+			if et, ok := ev.(time.Time); ok {
+				if at, ok := v.(time.Time); !ok || at.Sub(et).Round(time.Second) != 0 {
+					t.Errorf("%s row %d: expected: %v actual: %v", e.name, row, ev, v)
+				}
+				continue
+			}
+			if v != ev {
+				t.Errorf("%s row %d: expected: %v actual: %v", e.name, row, ev, v)
+			}
+		}
+	}
+
+	// NilValue
+	nilValue := "NA"
+	df, err = LoadFromExcel(ctx, bytes.NewReader(data), ExcelLoadOptions{NilValue: &nilValue})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := df.Series[5].Value(2); v != nil {
+		t.Errorf("expected nil value. actual: %v", v)
+	}
+
+	// DictateDataType
+	df, err = LoadFromExcel(ctx, bytes.NewReader(data), ExcelLoadOptions{DictateDataType: map[string]interface{}{"num": "", "flag": int64(0)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if df.Series[0].Type() != "string" || df.Series[0].Value(0) != "1.5" {
+		t.Errorf("wrong dictated value: %s %v", df.Series[0].Type(), df.Series[0].Value(0))
+	}
+	if df.Series[2].Value(0) != int64(1) {
+		t.Errorf("wrong dictated value: %v", df.Series[2].Value(0))
+	}
+
+	// Sheet by name and position
+	for _, sheet := range []interface{}{"second", 1} {
+		df, err = LoadFromExcel(ctx, bytes.NewReader(data), ExcelLoadOptions{Sheet: sheet})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if df.NRows() != 1 || df.Series[0].Name() != "id" || df.Series[0].Value(0) != 3.0 {
+			t.Errorf("%v: wrong sheet loaded", sheet)
+		}
+	}
+
+	for _, sheet := range []interface{}{"missing", 3, 1.0} {
+		if _, err := LoadFromExcel(ctx, bytes.NewReader(data), ExcelLoadOptions{Sheet: sheet}); err == nil {
+			t.Errorf("%v: expected error", sheet)
+		}
+	}
+
+	// CellRange and HeaderRow
+	cellRange := "E1:F4"
+	df, err = LoadFromExcel(ctx, bytes.NewReader(data), ExcelLoadOptions{CellRange: &cellRange, HeaderRow: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(df.Series) != 2 || df.NRows() != 2 || df.Series[1].Name() != "a" || df.Series[1].Value(0) != nil || df.Series[1].Value(1) != "NA" {
+		t.Errorf("wrong CellRange: %d series %d rows", len(df.Series), df.NRows())
+	}
+}
+
+func TestLoadSheetsFromExcel(t *testing.T) {
+	ctx := context.Background()
+
+	sheets := map[string][][]interface{}{
+		"a":     {{"x", "y"}, {1.0, "p"}, {2.0, "q"}},
+		"b":     {{"z"}, {true}},
+		"empty": {},
+	}
+	data := newWorkbook(t, sheets, "a", "b", "empty")
+
+	dfs, err := LoadSheetsFromExcel(ctx, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dfs) != 2 {
+		t.Fatalf("wrong number of sheets. expected: 2 actual: %d", len(dfs))
+	}
+	if df := dfs["a"]; df == nil || df.NRows() != 2 || len(df.Series) != 2 || df.Series[0].Type() != "float64" {
+		t.Errorf("wrong sheet: a")
+	}
+	if df := dfs["b"]; df == nil || df.NRows() != 1 || df.Series[0].Type() != "int64" {
+		t.Errorf("wrong sheet: b")
+	}
+}
+
+func TestLoadFromExcelHeaders(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		header []interface{}
+		err    string
+	}{
+		{[]interface{}{"a", nil, "b"}, ""},
+		{[]interface{}{"B", nil, "c"}, "blank header in column B collides with header: B"},
+		{[]interface{}{"a", nil, "B"}, "blank header in column B collides with header: B"},
+		{[]interface{}{"a", "b", "a"}, "duplicate header: a"},
+	}
+
+	for i, tc := range tests {
+		data := newWorkbook(t, map[string][][]interface{}{"s": {tc.header, {1.0, 2.0, 3.0}}}, "s")
+
+		_, err := LoadFromExcel(ctx, data)
+		if tc.err == "" {
+			if err != nil {
+				t.Errorf("%d: unexpected error: %v", i, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%d: wrong error. expected: %s actual: %v", i, tc.err, err)
+		}
+	}
+}
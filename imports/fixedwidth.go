@@ -0,0 +1,419 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package imports
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+// defaultFixedWidthSampleSize is the number of lines used to detect the columns.
+const defaultFixedWidthSampleSize = 100
+
+// FixedWidthColumn describes the position and data type of a field in a fixed-width file.
+type FixedWidthColumn struct {
+
+	// Name is the name of the field.
+	Name string
+
+	// Start is the position of the first character of the field. The starting index is 0.
+	Start int
+
+	// Width is the number of characters in the field.
+	Width int
+
+	// Type is the data type of the field. It follows the same conventions as DictateDataType.
+	// When not set, DictateDataType is checked. Otherwise the field is loaded as a string
+	// (or float64 if ImpliedDecimals is set).
+	Type interface{}
+
+	// DontTrim will preserve the leading and trailing spaces of the field.
+	DontTrim bool
+
+	// ImpliedDecimals is the number of digits after an implied decimal point.
+	// eg. With 2, "0012345" is loaded as 123.45. It can't be used with an int64, bool or time.Time field.
+	ImpliedDecimals int
+}
+
+// FixedWidthLoadOptions is likely to change.
+type FixedWidthLoadOptions struct {
+
+	// Columns describes the fields in the file.
+	// When not set, the fields are detected from the positions that contain a space in every sampled line.
+	// Characters in later lines that fall outside the detected fields are ignored.
+	Columns []FixedWidthColumn
+
+	// Header indicates that the first line (after SkipRows) contains the headings.
+	// When Columns is set, the headings are ignored.
+	// When not set and Columns is not set, the fields are named "0", "1", "2" etc.
+	//
+	// NOTE: When Columns is not set, a heading may contain single spaces (eg. "first name"). A field that is blank
+	// in every sampled line and is separated from a neighbouring field by a single space is treated as part of
+	// that field's heading. Set Columns if this is not desired.
+	Header bool
+
+	// SkipRows is the number of lines to skip at the start of the file.
+	SkipRows int
+
+	// SampleSize is the number of lines used to detect the fields when Columns is not set.
+	// The default is 100.
+	SampleSize int
+
+	// DictateDataType is used to inform LoadFromFixedWidth what the true underlying data type is for a given field name.
+	// The value for a given key must be of the data type of the data.
+	// eg. For a string use "". For a int64 use int64(0). What is relevant is the data type and not the value itself.
+	//
+	// NOTE: A custom Series must implement NewSerieser interface and be able to interpret strings to work.
+	DictateDataType map[string]interface{}
+
+	// Schema can be used in place of DictateDataType. The data type of each field is determined by its Type.
+	// DictateDataType takes precedence for fields found in both.
+	//
+	// NOTE: Nullable and Constraints are not checked. Use Schema.Validate after loading.
+	Schema *dataframe.Schema
+
+	// NilValue allows you to set what string value (after trimming) should be interpreted as a nil value for
+	// the purposes of insertion. Blank fields are always interpreted as nil.
+	//
+	// Common values are: NULL, \N, NaN, NA
+	NilValue *string
+}
+
+// LoadFromFixedWidth will load data from a fixed-width text file.
+// Positions and widths are measured in characters (runes). Empty lines are ignored.
+func LoadFromFixedWidth(ctx context.Context, r io.Reader, options ...FixedWidthLoadOptions) (*dataframe.DataFrame, error) {
+
+	var opts FixedWidthLoadOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	if opts.Schema != nil {
+		dictated, err := schemaDataTypes(opts.Schema, opts.DictateDataType)
+		if err != nil {
+			return nil, err
+		}
+		opts.DictateDataType = dictated
+	}
+
+	br := bufio.NewReader(r)
+
+	readLine := func() (string, error) {
+		line, err := br.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	for i := 0; i < opts.SkipRows; i++ {
+		if _, err := readLine(); err != nil {
+			if err == io.EOF {
+				return nil, dataframe.ErrNoRows
+			}
+			return nil, err
+		}
+	}
+
+	// Lines that are read to detect the fields
+	var pending []string
+
+	if opts.Columns == nil {
+		sampleSize := defaultFixedWidthSampleSize
+		if opts.SampleSize > 0 {
+			sampleSize = opts.SampleSize
+		}
+
+		for len(pending) < sampleSize {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			line, err := readLine()
+			if err != nil {
+				if err == io.EOF {
+					break
+				}
+				return nil, err
+			}
+			pending = append(pending, line)
+		}
+
+		if len(pending) == 0 {
+			return nil, dataframe.ErrNoRows
+		}
+
+		opts.Columns = detectFixedWidthColumns(pending, opts.Header)
+	} else if opts.Header {
+		// Ignore the headings
+		if _, err := readLine(); err != nil {
+			if err == io.EOF {
+				return nil, dataframe.ErrNoRows
+			}
+			return nil, err
+		}
+	}
+
+	if opts.Header && len(pending) > 0 {
+		pending = pending[1:]
+	}
+
+	// Determine the data type of each field
+	types := make([]interface{}, len(opts.Columns))
+	seriess := []dataframe.Series{}
+	names := map[string]struct{}{}
+
+	for idx, col := range opts.Columns {
+		if col.Start < 0 || col.Width <= 0 || col.ImpliedDecimals < 0 {
+			return nil, fmt.Errorf("invalid column: %s", col.Name)
+		}
+
+		if _, exists := names[col.Name]; exists {
+			return nil, fmt.Errorf("duplicate field name: %s", col.Name)
+		}
+		names[col.Name] = struct{}{}
+
+		typ := col.Type
+		if typ == nil {
+			typ = opts.DictateDataType[col.Name]
+		}
+		if typ == nil {
+			if col.ImpliedDecimals > 0 {
+				typ = float64(0)
+			} else {
+				typ = ""
+			}
+		}
+		if col.ImpliedDecimals > 0 {
+			switch typ.(type) {
+			case int64, bool, time.Time:
+				return nil, fmt.Errorf("ImpliedDecimals can't be used with a %T field: %s", typ, col.Name)
+			}
+		}
+
+		types[idx] = typ
+		seriess = append(seriess, newSeries(col.Name, typ, nil))
+	}
+
+	// Create the dataframe
+	df := dataframe.NewDataFrame(seriess...)
+
+	row := 1
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var line string
+		if len(pending) > 0 {
+			line = pending[0]
+			pending = pending[1:]
+		} else {
+			var err error
+			line, err = readLine()
+			if err != nil {
+				if err == io.EOF {
+					break
+				}
+				return nil, err
+			}
+		}
+
+		if line == "" {
+			// Ignore empty lines
+			continue
+		}
+
+		insertVals, err := fixedWidthRecord(line, row, opts.Columns, types, opts.NilValue)
+		if err != nil {
+			return nil, err
+		}
+		df.Append(&dataframe.DontLock, insertVals...)
+		row++
+	}
+
+	return df, nil
+}
+
+// fixedWidthRecord converts a line to the values that are inserted into the DataFrame.
+// row is the position of the line, where the headings (if any) are row 0.
+func fixedWidthRecord(line string, row int, cols []FixedWidthColumn, types []interface{}, nilValue *string) ([]interface{}, error) {
+
+	var runes []rune
+	if !isASCII(line) {
+		runes = []rune(line)
+	}
+
+	insertVals := make([]interface{}, 0, len(cols))
+	for idx, col := range cols {
+
+		var v string
+		if runes == nil {
+			v = substr(line, len(line), col.Start, col.Width, func(s, e int) string { return line[s:e] })
+		} else {
+			v = substr(line, len(runes), col.Start, col.Width, func(s, e int) string { return string(runes[s:e]) })
+		}
+
+		if !col.DontTrim {
+			v = strings.TrimSpace(v)
+		}
+
+		// Check if v represents a nil value
+		if nilValue != nil && v == *nilValue {
+			insertVals = append(insertVals, nil)
+			continue
+		}
+
+		if strings.TrimSpace(v) == "" {
+			insertVals = append(insertVals, nil)
+			continue
+		}
+
+		if col.ImpliedDecimals > 0 {
+			v = impliedDecimals(strings.TrimSpace(v), col.ImpliedDecimals)
+		}
+
+		val, err := csvValue(v, types[idx], row, col.Name)
+		if err != nil {
+			return nil, err
+		}
+		insertVals = append(insertVals, val)
+	}
+
+	return insertVals, nil
+}
+
+// detectFixedWidthColumns detects the fields from the positions that contain a space in every line.
+// When header is set, a field that only contains a heading and is separated from a neighbouring field by a
+// single space is part of that field's heading. eg. "first name".
+func detectFixedWidthColumns(lines []string, header bool) []FixedWidthColumn {
+
+	maxLen := 0
+	runeLines := make([][]rune, 0, len(lines))
+	for _, line := range lines {
+		rl := []rune(line)
+		runeLines = append(runeLines, rl)
+		if len(rl) > maxLen {
+			maxLen = len(rl)
+		}
+	}
+
+	// Determine which positions are used by at least one line
+	used := make([]bool, maxLen)     // by any line
+	usedData := make([]bool, maxLen) // by a line that is not the headings
+	for l, rl := range runeLines {
+		for i, c := range rl {
+			if c != ' ' && c != '\t' {
+				used[i] = true
+				if !header || l > 0 {
+					usedData[i] = true
+				}
+			}
+		}
+	}
+
+	type span struct{ start, end int }
+
+	spans := []span{}
+	for i := 0; i < maxLen; i++ {
+		if !used[i] {
+			continue
+		}
+
+		start := i
+		for i < maxLen && used[i] {
+			i++
+		}
+		spans = append(spans, span{start, i})
+	}
+
+	if header {
+		hasData := func(sp span) bool {
+			for i := sp.start; i < sp.end; i++ {
+				if usedData[i] {
+					return true
+				}
+			}
+			return false
+		}
+
+		// Join the spans that only contain part of a heading with a neighbouring span
+		merged := []span{}
+		for idx := 0; idx < len(spans); idx++ {
+			sp := spans[idx]
+			if !hasData(sp) {
+				if n := len(merged); n > 0 && sp.start-merged[n-1].end == 1 {
+					merged[n-1].end = sp.end
+					continue
+				}
+				if idx+1 < len(spans) && spans[idx+1].start-sp.end == 1 {
+					spans[idx+1].start = sp.start
+					continue
+				}
+			}
+			merged = append(merged, sp)
+		}
+		spans = merged
+	}
+
+	cols := []FixedWidthColumn{}
+	for _, sp := range spans {
+		name := strconv.Itoa(len(cols))
+		if header {
+			name = strings.TrimSpace(substr(string(runeLines[0]), len(runeLines[0]), sp.start, sp.end-sp.start, func(s, e int) string { return string(runeLines[0][s:e]) }))
+			if name == "" {
+				name = strconv.Itoa(len(cols))
+			}
+		}
+
+		cols = append(cols, FixedWidthColumn{Name: name, Start: sp.start, Width: sp.end - sp.start})
+	}
+
+	return cols
+}
+
+// substr returns the characters from start to start+width. n is the number of characters available.
+func substr(line string, n int, start, width int, slice func(s, e int) string) string {
+	if start >= n {
+		return ""
+	}
+
+	end := start + width
+	if end > n {
+		end = n
+	}
+
+	return slice(start, end)
+}
+
+// impliedDecimals inserts a decimal point before the last n digits of v.
+func impliedDecimals(v string, n int) string {
+
+	sign := ""
+	if strings.HasPrefix(v, "-") || strings.HasPrefix(v, "+") {
+		sign, v = v[:1], v[1:]
+	}
+
+	if len(v) <= n {
+		v = strings.Repeat("0", n-len(v)+1) + v
+	}
+
+	return sign + v[:len(v)-n] + "." + v[len(v)-n:]
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,144 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package imports
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadFromFixedWidth(t *testing.T) {
+	ctx := context.Background()
+
+	nilValue := "NA"
+
+	tests := []struct {
+		data     string
+		options  FixedWidthLoadOptions
+		expected expectedDF
+		err      string
+	}{
+		// Detected fields
+		{
+			"1  abc  1.5\n22 d    -2\n\n3       NA\n",
+			FixedWidthLoadOptions{},
+			expectedDF{[]string{"0", "1", "2"}, []string{"string", "string", "string"}, [][]interface{}{{"1", "abc", "1.5"}, {"22", "d", "-2"}, {"3", nil, "NA"}}},
+			"",
+		},
+		{
+			"id name amt\n 1 abc  1.5\n22 d    -2\n 3      NA\n",
+			FixedWidthLoadOptions{Header: true, NilValue: &nilValue, DictateDataType: map[string]interface{}{"id": int64(0), "amt": float64(0)}},
+			expectedDF{[]string{"id", "name", "amt"}, []string{"int64", "string", "float64"}, [][]interface{}{{int64(1), "abc", 1.5}, {int64(22), "d", -2.0}, {int64(3), nil, nil}}},
+			"",
+		},
+		{
+			// Headings containing spaces
+			"first name  age group\nJo          30   a\nAnn         41   b\n",
+			FixedWidthLoadOptions{Header: true},
+			expectedDF{[]string{"first name", "age", "group"}, nil, [][]interface{}{{"Jo", "30", "a"}, {"Ann", "41", "b"}}},
+			"",
+		},
+		{
+			"junk\nfirst name  last name\nJo          Smith\n",
+			FixedWidthLoadOptions{Header: true, SkipRows: 1},
+			expectedDF{[]string{"first name", "last name"}, nil, [][]interface{}{{"Jo", "Smith"}}},
+			"",
+		},
+		{
+			"héllo 1\nwörld 2\n",
+			FixedWidthLoadOptions{},
+			expectedDF{[]string{"0", "1"}, nil, [][]interface{}{{"héllo", "1"}, {"wörld", "2"}}},
+			"",
+		},
+		{
+			"a\nb\nc\n   1\n",
+			FixedWidthLoadOptions{SampleSize: 2},
+			expectedDF{[]string{"0"}, nil, [][]interface{}{{"a"}, {"b"}, {"c"}, {nil}}},
+			"",
+		},
+		{
+			"",
+			FixedWidthLoadOptions{},
+			expectedDF{},
+			"contains no rows",
+		},
+
+		// Explicit fields
+		{
+			"ignored heading\n0012345 ab  2020-01-02T03:04:05Z\n-000050     \n",
+			FixedWidthLoadOptions{
+				Header: true,
+				Columns: []FixedWidthColumn{
+					{Name: "amt", Width: 7, ImpliedDecimals: 2},
+					{Name: "code", Start: 8, Width: 4, DontTrim: true},
+					{Name: "when", Start: 12, Width: 20, Type: time.Time{}},
+					{Name: "missing", Start: 40, Width: 5},
+				},
+			},
+			expectedDF{
+				[]string{"amt", "code", "when", "missing"},
+				[]string{"float64", "string", "time", "string"},
+				[][]interface{}{
+					{123.45, "ab  ", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), nil},
+					{-0.5, nil, nil, nil},
+				},
+			},
+			"",
+		},
+		{
+			"12345\n",
+			FixedWidthLoadOptions{Columns: []FixedWidthColumn{{Name: "a", Width: 5, ImpliedDecimals: 3, Type: ""}}},
+			expectedDF{[]string{"a"}, []string{"string"}, [][]interface{}{{"12.345"}}},
+			"",
+		},
+		{
+			"12345\n",
+			FixedWidthLoadOptions{Columns: []FixedWidthColumn{{Name: "a", Width: 5, ImpliedDecimals: 2}}, DictateDataType: map[string]interface{}{"a": int64(0)}},
+			expectedDF{},
+			"ImpliedDecimals can't be used with a int64 field: a",
+		},
+		{
+			"12345\n",
+			FixedWidthLoadOptions{Columns: []FixedWidthColumn{{Name: "a", Width: 0}}},
+			expectedDF{},
+			"invalid column: a",
+		},
+		{
+			"12345\n",
+			FixedWidthLoadOptions{Columns: []FixedWidthColumn{{Name: "a", Width: 2}, {Name: "a", Start: 2, Width: 3}}},
+			expectedDF{},
+			"duplicate field name: a",
+		},
+		{
+			"id   id\n1    2\n",
+			FixedWidthLoadOptions{Header: true},
+			expectedDF{},
+			"duplicate field name: id",
+		},
+		{
+			"12x45\n",
+			FixedWidthLoadOptions{Columns: []FixedWidthColumn{{Name: "a", Width: 5, Type: int64(0)}}},
+			expectedDF{},
+			"can't force string: 12x45 to int64. row: 0 field: a",
+		},
+	}
+
+	for i, tc := range tests {
+		df, err := LoadFromFixedWidth(ctx, strings.NewReader(tc.data), tc.options)
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("%d: wrong error. expected: %s actual: %v", i, tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %v", i, err)
+			continue
+		}
+
+		checkDataFrame(t, strconv.Itoa(i), df, tc.expected)
+	}
+}
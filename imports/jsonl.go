@@ -3,16 +3,48 @@
 package imports
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	dataframe "github.com/rocketlaunchr/dataframe-go"
 )
 
+// JSONOrient sets the layout of the JSON data.
+type JSONOrient int
+
+const (
+	// OrientLines expects each row to be an object on a separate line (jsonl).
+	// A top-level JSON array of row objects is also accepted.
+	OrientLines JSONOrient = 0
+
+	// OrientRecords expects a JSON array of row objects.
+	// eg. [{"day":1,"sales":50.3},{"day":2,"sales":23.4}]
+	OrientRecords JSONOrient = 1
+
+	// OrientColumns expects an object mapping each series name to an array of values.
+	// eg. {"day":[1,2],"sales":[50.3,23.4]}
+	OrientColumns JSONOrient = 2
+
+	// OrientSplit expects the series names and the row values separately.
+	// eg. {"columns":["day","sales"],"data":[[1,50.3],[2,23.4]]}
+	OrientSplit JSONOrient = 3
+
+	// OrientTable expects a schema containing the type of each Series, followed by the row objects.
+	// The schema is used to restore the Series types.
+	// eg. {"schema":{"fields":[{"name":"day","type":"int64"},{"name":"sales","type":"float64"}]},"data":[{"day":1,"sales":50.3}]}
+	OrientTable JSONOrient = 4
+)
+
 // JSONLoadOptions is likely to change.
 type JSONLoadOptions struct {
 
@@ -29,19 +61,106 @@ type JSONLoadOptions struct {
 	// NOTE: A custom Series must implement NewSerieser interface and be able to interpret strings to work.
 	DictateDataType map[string]interface{}
 
+	// Schema can be used in place of DictateDataType. The data type of each field is determined by its Type.
+	// DictateDataType takes precedence for fields found in both.
+	//
+	// NOTE: Nullable and Constraints are not checked. Use Schema.Validate after loading.
+	Schema *dataframe.Schema
+
 	// ErrorOnUnknownFields will generate an error if an unknown field is encountered after the first row.
 	ErrorOnUnknownFields bool
+
+	// RecordsPath is used to select the array of records inside a wrapper document.
+	// It uses a JSONPath-like syntax. eg. "$.data.items" or "results[0].rows"
+	// When not set, the data is expected to be jsonl or a top-level JSON array of objects.
+	//
+	// NOTE: The entire document is decoded into memory when RecordsPath is set.
+	RecordsPath string
+
+	// FlattenSeparator is used to join the keys of nested objects. The default is ".".
+	FlattenSeparator string
+
+	// FlattenDepth limits how many levels of nested objects are flattened.
+	// Objects nested deeper are stored as a JSON string. A value of 0 means no objects are flattened.
+	// When not set, there is no limit.
+	FlattenDepth *int
+
+	// KeepArrays will store JSON arrays in a SeriesMixed as a []interface{}.
+	// A SeriesMixed is used when the value of the field in the first row is an array.
+	// For other fields, an array is stored as a JSON string. Numbers inside arrays are converted to float64.
+	// When not set, arrays are interpreted as nil.
+	KeepArrays bool
+
+	// Orient sets the layout of the JSON data. The default is OrientLines.
+	// Only OrientTable restores the Series types. For the other layouts, each field whose data type
+	// is not dictated is loaded as a SeriesString (or a SeriesMixed when KeepArrays is set).
+	//
+	// NOTE: RecordsPath, FlattenSeparator, FlattenDepth and KeepArrays only apply to OrientLines and OrientRecords.
+	Orient JSONOrient
 }
 
-// LoadFromJSON will load data from a jsonl file.
+// LoadFromJSON will load data from a jsonl file or a JSON array of objects.
 // The first row determines which fields will be imported for subsequent rows.
-func LoadFromJSON(ctx context.Context, r io.ReadSeeker, options ...JSONLoadOptions) (*dataframe.DataFrame, error) {
+//
+// r does not need to implement io.Seeker. However, if it doesn't and LargeDataSet is set,
+// the entire file is read into memory first.
+func LoadFromJSON(ctx context.Context, r io.Reader, options ...JSONLoadOptions) (*dataframe.DataFrame, error) {
+
+	if len(options) > 0 && options[0].Schema != nil {
+		opts := options[0]
+		dictated, err := schemaDataTypes(opts.Schema, opts.DictateDataType)
+		if err != nil {
+			return nil, err
+		}
+		opts.DictateDataType, opts.Schema = dictated, nil
+		options = []JSONLoadOptions{opts}
+	}
 
-	var init *dataframe.SeriesInit
+	var (
+		init        *dataframe.SeriesInit
+		sep         string = "."
+		maxDepth    int    = -1
+		keepArrays  bool
+		recordsPath string
+		listFields  map[string]struct{} // fields that store arrays
+	)
 
 	if len(options) > 0 {
+		switch options[0].Orient {
+		case OrientLines, OrientRecords:
+		case OrientColumns, OrientSplit, OrientTable:
+			return loadJSONOrient(ctx, r, options[0])
+		default:
+			return nil, errors.New("invalid Orient")
+		}
+
+		if options[0].FlattenSeparator != "" {
+			sep = options[0].FlattenSeparator
+		}
+		if options[0].FlattenDepth != nil {
+			maxDepth = *options[0].FlattenDepth
+			if maxDepth < 0 {
+				return nil, errors.New("invalid FlattenDepth")
+			}
+		}
+		keepArrays = options[0].KeepArrays
+		recordsPath = options[0].RecordsPath
+		if keepArrays {
+			listFields = map[string]struct{}{}
+		}
+
 		// Count how many rows we have in order to preallocate underlying slices
-		if options[0].LargeDataSet {
+		if options[0].LargeDataSet && recordsPath == "" {
+			rs, seekable := r.(io.ReadSeeker)
+			if !seekable {
+				b, err := ioutil.ReadAll(r)
+				if err != nil {
+					return nil, err
+				}
+				rs = bytes.NewReader(b)
+				r = rs
+			}
+
 			init = &dataframe.SeriesInit{}
 			dec := json.NewDecoder(r)
 
@@ -54,7 +173,7 @@ func LoadFromJSON(ctx context.Context, r io.ReadSeeker, options ...JSONLoadOptio
 				t, err := dec.Token()
 				if err != nil {
 					if err == io.EOF {
-						r.Seek(0, io.SeekStart)
+						rs.Seek(0, io.SeekStart)
 						break
 					}
 					return nil, err
@@ -80,15 +199,17 @@ func LoadFromJSON(ctx context.Context, r io.ReadSeeker, options ...JSONLoadOptio
 	var row int
 	var df *dataframe.DataFrame
 
-	dec := json.NewDecoder(r)
-	dec.UseNumber()
+	next, err := jsonRecords(r, recordsPath)
+	if err != nil {
+		return nil, err
+	}
+
 	for {
 		if err := ctx.Err(); err != nil {
 			return nil, err
 		}
 
-		var raw map[string]interface{}
-		err := dec.Decode(&raw)
+		raw, err := next()
 		if err != nil {
 			if err == io.EOF {
 				break
@@ -97,7 +218,7 @@ func LoadFromJSON(ctx context.Context, r io.ReadSeeker, options ...JSONLoadOptio
 		}
 		row++
 
-		vals := parseObject(raw, "")
+		vals := parseObject(raw, "", sep, maxDepth)
 
 		if row == 1 {
 
@@ -107,13 +228,18 @@ func LoadFromJSON(ctx context.Context, r io.ReadSeeker, options ...JSONLoadOptio
 			// Create a series for each field (of the appropriate data type)
 			seriess := []dataframe.Series{}
 
-			for name := range vals {
+			for name, val := range vals {
 
 				// Check if we know what the datatype should be. Otherwise assume string
 				if len(options) > 0 && len(options[0].DictateDataType) > 0 {
 
 					typ, exists := options[0].DictateDataType[name]
 					if !exists {
+						if _, ok := val.([]interface{}); ok && keepArrays {
+							listFields[name] = struct{}{}
+							seriess = append(seriess, dataframe.NewSeriesMixed(name, init))
+							continue
+						}
 						seriess = append(seriess, dataframe.NewSeriesString(name, init))
 						continue
 					}
@@ -140,6 +266,11 @@ func LoadFromJSON(ctx context.Context, r io.ReadSeeker, options ...JSONLoadOptio
 						seriess = append(seriess, dataframe.NewSeriesGeneric(name, typ, init))
 					}
 				} else {
+					if _, ok := val.([]interface{}); ok && keepArrays {
+						listFields[name] = struct{}{}
+						seriess = append(seriess, dataframe.NewSeriesMixed(name, init))
+						continue
+					}
 					seriess = append(seriess, dataframe.NewSeriesString(name, init))
 				}
 
@@ -159,19 +290,7 @@ func LoadFromJSON(ctx context.Context, r io.ReadSeeker, options ...JSONLoadOptio
 					// Check if a datatype is dictated
 					typ, exists := options[0].DictateDataType[name]
 					if !exists {
-						// Store value as a string
-						switch v := val.(type) {
-						case string:
-							insertVals[name] = v
-						case json.Number:
-							insertVals[name] = v.String()
-						case bool:
-							if v == true {
-								insertVals[name] = "1"
-							} else {
-								insertVals[name] = "0"
-							}
-						}
+						storeJSONValue(insertVals, name, val, listFields)
 					} else {
 						err := dictateForce(row, insertVals, name, typ, val)
 						if err != nil {
@@ -179,20 +298,7 @@ func LoadFromJSON(ctx context.Context, r io.ReadSeeker, options ...JSONLoadOptio
 						}
 					}
 				} else {
-					// Store value as a string
-
-					switch v := val.(type) {
-					case string:
-						insertVals[name] = v
-					case json.Number:
-						insertVals[name] = v.String()
-					case bool:
-						if v == true {
-							insertVals[name] = "1"
-						} else {
-							insertVals[name] = "0"
-						}
-					}
+					storeJSONValue(insertVals, name, val, listFields)
 				}
 
 			}
@@ -224,19 +330,7 @@ func LoadFromJSON(ctx context.Context, r io.ReadSeeker, options ...JSONLoadOptio
 					// Check if a datatype is dictated
 					typ, exists := options[0].DictateDataType[name]
 					if !exists {
-						// Store value as a string
-						switch v := val.(type) {
-						case string:
-							insertVals[name] = v
-						case json.Number:
-							insertVals[name] = v.String()
-						case bool:
-							if v == true {
-								insertVals[name] = "1"
-							} else {
-								insertVals[name] = "0"
-							}
-						}
+						storeJSONValue(insertVals, name, val, listFields)
 					} else {
 						err := dictateForce(row, insertVals, name, typ, val)
 						if err != nil {
@@ -244,20 +338,7 @@ func LoadFromJSON(ctx context.Context, r io.ReadSeeker, options ...JSONLoadOptio
 						}
 					}
 				} else {
-					// Store value as a string
-
-					switch v := val.(type) {
-					case string:
-						insertVals[name] = v
-					case json.Number:
-						insertVals[name] = v.String()
-					case bool:
-						if v == true {
-							insertVals[name] = "1"
-						} else {
-							insertVals[name] = "0"
-						}
-					}
+					storeJSONValue(insertVals, name, val, listFields)
 				}
 			}
 
@@ -280,3 +361,377 @@ func LoadFromJSON(ctx context.Context, r io.ReadSeeker, options ...JSONLoadOptio
 
 	return df, nil
 }
+
+// jsonRecords returns a function that returns each record in turn. It returns io.EOF when
+// there are no more records.
+func jsonRecords(r io.Reader, recordsPath string) (func() (map[string]interface{}, error), error) {
+
+	br := bufio.NewReader(r)
+	dec := json.NewDecoder(br)
+	dec.UseNumber()
+
+	if recordsPath != "" {
+		var doc interface{}
+		if err := dec.Decode(&doc); err != nil {
+			return nil, err
+		}
+
+		selected, err := selectJSON(doc, recordsPath)
+		if err != nil {
+			return nil, err
+		}
+
+		records, ok := selected.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("RecordsPath does not select an array: %s", recordsPath)
+		}
+
+		var idx int
+		return func() (map[string]interface{}, error) {
+			if idx >= len(records) {
+				return nil, io.EOF
+			}
+			idx++
+
+			rec, ok := records[idx-1].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("record is not an object. row: %d", idx-1)
+			}
+			return rec, nil
+		}, nil
+	}
+
+	// Determine if data is a JSON array
+	var isArray bool
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		if b == ' ' || b == '\t' || b == '\r' || b == '\n' {
+			continue
+		}
+
+		isArray = b == '['
+		br.UnreadByte()
+		break
+	}
+
+	if !isArray {
+		return func() (map[string]interface{}, error) {
+			var raw map[string]interface{}
+			err := dec.Decode(&raw)
+			if err != nil {
+				return nil, err
+			}
+			return raw, nil
+		}, nil
+	}
+
+	// Consume opening bracket
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	var idx int
+	return func() (map[string]interface{}, error) {
+		if !dec.More() {
+			return nil, io.EOF
+		}
+		idx++
+
+		var raw interface{}
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+
+		rec, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("record is not an object. row: %d", idx-1)
+		}
+		return rec, nil
+	}, nil
+}
+
+// selectJSON returns the value inside doc that is selected by path.
+// path uses a JSONPath-like syntax. eg. "$.data.items" or "results[0].rows"
+func selectJSON(doc interface{}, path string) (interface{}, error) {
+
+	path = strings.TrimPrefix(path, "$")
+	path = strings.TrimPrefix(path, ".")
+
+	if path == "" {
+		return doc, nil
+	}
+
+	for _, segment := range strings.Split(path, ".") {
+
+		// Separate key from array indices
+		key := segment
+		var indices []string
+		if i := strings.Index(segment, "["); i != -1 {
+			key = segment[:i]
+			for _, idx := range strings.Split(segment[i+1:], "[") {
+				if !strings.HasSuffix(idx, "]") {
+					return nil, fmt.Errorf("invalid RecordsPath: %s", path)
+				}
+				indices = append(indices, strings.TrimSuffix(idx, "]"))
+			}
+		}
+
+		if key != "" {
+			obj, ok := doc.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("RecordsPath not found: %s", path)
+			}
+			doc, ok = obj[key]
+			if !ok {
+				return nil, fmt.Errorf("RecordsPath not found: %s", path)
+			}
+		}
+
+		for _, idx := range indices {
+			i, err := strconv.Atoi(idx)
+			if err != nil {
+				return nil, fmt.Errorf("invalid RecordsPath: %s", path)
+			}
+
+			arr, ok := doc.([]interface{})
+			if !ok || i < 0 || i >= len(arr) {
+				return nil, fmt.Errorf("RecordsPath not found: %s", path)
+			}
+			doc = arr[i]
+		}
+	}
+
+	return doc, nil
+}
+
+// storeJSONValue stores val into insertVals for a field whose data type is not dictated.
+// Fields in listFields store arrays. All other fields store strings.
+func storeJSONValue(insertVals map[string]interface{}, name string, val interface{}, listFields map[string]struct{}) {
+
+	if _, exists := listFields[name]; exists {
+		insertVals[name] = jsonList(val)
+		return
+	}
+
+	// Store value as a string
+	switch v := val.(type) {
+	case string:
+		insertVals[name] = v
+	case json.Number:
+		insertVals[name] = v.String()
+	case bool:
+		if v == true {
+			insertVals[name] = "1"
+		} else {
+			insertVals[name] = "0"
+		}
+	case []interface{}:
+		if listFields != nil {
+			// KeepArrays is set
+			b, _ := json.Marshal(v)
+			insertVals[name] = string(b)
+		}
+	}
+}
+
+// jsonList converts json.Number found inside val to float64.
+func jsonList(val interface{}) interface{} {
+	switch v := val.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return v.String()
+		}
+		return f
+	case []interface{}:
+		out := make([]interface{}, 0, len(v))
+		for _, e := range v {
+			out = append(out, jsonList(e))
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			out[k] = jsonList(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// loadJSONOrient loads data in the OrientColumns, OrientSplit or OrientTable layout.
+func loadJSONOrient(ctx context.Context, r io.Reader, opts JSONLoadOptions) (*dataframe.DataFrame, error) {
+
+	var (
+		names []string
+		types = map[string]interface{}{}
+		nRows int
+		value func(row, col int) interface{}
+	)
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	switch opts.Orient {
+	case OrientColumns:
+		// Decode using tokens to maintain the order of the Series
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if delim, ok := t.(json.Delim); !ok || delim != '{' {
+			return nil, errors.New("expected object")
+		}
+
+		columns := [][]interface{}{}
+		for dec.More() {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			t, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			var vals []interface{}
+			if err := dec.Decode(&vals); err != nil {
+				return nil, err
+			}
+
+			if len(columns) > 0 && len(vals) != len(columns[0]) {
+				return nil, fmt.Errorf("different number of rows in field: %s", t.(string))
+			}
+
+			names = append(names, t.(string))
+			columns = append(columns, vals)
+		}
+
+		if len(columns) > 0 {
+			nRows = len(columns[0])
+		}
+		value = func(row, col int) interface{} {
+			return columns[col][row]
+		}
+	case OrientSplit:
+		var doc struct {
+			Columns []string        `json:"columns"`
+			Data    [][]interface{} `json:"data"`
+		}
+		if err := dec.Decode(&doc); err != nil {
+			return nil, err
+		}
+
+		for row, vals := range doc.Data {
+			if len(vals) != len(doc.Columns) {
+				return nil, fmt.Errorf("no. of values not equal to no. of columns. row: %d", row)
+			}
+		}
+
+		names = doc.Columns
+		nRows = len(doc.Data)
+		value = func(row, col int) interface{} {
+			return doc.Data[row][col]
+		}
+	case OrientTable:
+		var doc struct {
+			Schema struct {
+				Fields []struct {
+					Name string `json:"name"`
+					Type string `json:"type"`
+				} `json:"fields"`
+			} `json:"schema"`
+			Data []map[string]interface{} `json:"data"`
+		}
+		if err := dec.Decode(&doc); err != nil {
+			return nil, err
+		}
+
+		for _, field := range doc.Schema.Fields {
+			names = append(names, field.Name)
+
+			switch field.Type {
+			case "float64":
+				types[field.Name] = float64(0)
+			case "int64":
+				types[field.Name] = int64(0)
+			case "time":
+				types[field.Name] = time.Time{}
+			case "string":
+				types[field.Name] = ""
+			}
+		}
+
+		nRows = len(doc.Data)
+		value = func(row, col int) interface{} {
+			return doc.Data[row][names[col]]
+		}
+	}
+
+	if len(names) == 0 || nRows == 0 {
+		return nil, dataframe.ErrNoRows
+	}
+
+	seen := map[string]struct{}{}
+	for _, name := range names {
+		if _, exists := seen[name]; exists {
+			return nil, fmt.Errorf("duplicate field name: %s", name)
+		}
+		seen[name] = struct{}{}
+	}
+
+	// Dictated data types override the schema
+	for name, typ := range opts.DictateDataType {
+		types[name] = typ
+	}
+
+	init := &dataframe.SeriesInit{Capacity: nRows}
+
+	seriess := []dataframe.Series{}
+	for _, name := range names {
+		typ, exists := types[name]
+		if !exists {
+			typ = ""
+		}
+		seriess = append(seriess, newSeries(name, typ, init))
+	}
+
+	// Create the dataframe
+	df := dataframe.NewDataFrame(seriess...)
+
+	for row := 0; row < nRows; row++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		insertVals := map[string]interface{}{}
+
+		for col, name := range names {
+			val := value(row, col)
+
+			typ, exists := types[name]
+			if !exists {
+				storeJSONValue(insertVals, name, val, nil)
+				continue
+			}
+
+			err := dictateForce(row+1, insertVals, name, typ, val)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		df.Append(&dataframe.DontLock, make([]interface{}, len(df.Series))...)
+		df.UpdateRow(row, &dataframe.DontLock, insertVals)
+	}
+
+	return df, nil
+}
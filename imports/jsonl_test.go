@@ -0,0 +1,218 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package imports
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+// expectedDF describes the expected contents of a DataFrame.
+type expectedDF struct {
+	names []string
+	types []string // not checked when nil
+	rows  [][]interface{}
+}
+
+// checkDataFrame compares the names, types and values of df with e.
+func checkDataFrame(t *testing.T, label string, df *dataframe.DataFrame, e expectedDF) {
+	t.Helper()
+
+	if names := df.Names(); !reflect.DeepEqual(names, e.names) {
+		t.Errorf("%s: wrong names. expected: %v actual: %v", label, e.names, names)
+		return
+	}
+
+	if e.types != nil {
+		for col, s := range df.Series {
+			if s.Type() != e.types[col] {
+				t.Errorf("%s: wrong type for %s. expected: %s actual: %s", label, s.Name(), e.types[col], s.Type())
+			}
+		}
+	}
+
+	if df.NRows() != len(e.rows) {
+		t.Errorf("%s: wrong number of rows. expected: %d actual: %d", label, len(e.rows), df.NRows())
+		return
+	}
+
+	for row, vals := range e.rows {
+		for col, s := range df.Series {
+			if v := s.Value(row); !reflect.DeepEqual(v, vals[col]) {
+				t.Errorf("%s: wrong value in %s row %d. expected: %v (%T) actual: %v (%T)", label, s.Name(), row, vals[col], vals[col], v, v)
+			}
+		}
+	}
+}
+
+func TestLoadFromJSON(t *testing.T) {
+	ctx := context.Background()
+
+	zero, one := 0, 1
+
+	tests := []struct {
+		data     string
+		options  JSONLoadOptions
+		expected expectedDF
+		err      string
+	}{
+		// jsonl
+		{
+			`{"a":1,"b":"x"}` + "\n" + `{"a":2.5,"b":true,"c":3}`,
+			JSONLoadOptions{},
+			expectedDF{[]string{"a", "b"}, []string{"string", "string"}, [][]interface{}{{"1", "x"}, {"2.5", "1"}}},
+			"",
+		},
+		{
+			`{"a":1}` + "\n" + `{"a":2,"c":3}`,
+			JSONLoadOptions{ErrorOnUnknownFields: true},
+			expectedDF{},
+			"unknown field encountered. row: 1 field: c",
+		},
+		// Top-level array
+		{
+			` [{"a":1,"b":null},{"a":2,"b":"y"}]`,
+			JSONLoadOptions{DictateDataType: map[string]interface{}{"a": int64(0)}},
+			expectedDF{[]string{"a", "b"}, []string{"int64", "string"}, [][]interface{}{{int64(1), nil}, {int64(2), "y"}}},
+			"",
+		},
+		{
+			`[]`,
+			JSONLoadOptions{},
+			expectedDF{},
+			dataframe.ErrNoRows.Error(),
+		},
+		{
+			`[{"a":1},2]`,
+			JSONLoadOptions{},
+			expectedDF{},
+			"record is not an object. row: 1",
+		},
+		// RecordsPath
+		{
+			`{"meta":{"n":2},"data":{"items":[{"a":1},{"a":2}]}}`,
+			JSONLoadOptions{RecordsPath: "$.data.items"},
+			expectedDF{[]string{"a"}, nil, [][]interface{}{{"1"}, {"2"}}},
+			"",
+		},
+		{
+			`{"results":[{"rows":[{"a":"p"}]},{"rows":[{"a":"q"},{"a":"r"}]}]}`,
+			JSONLoadOptions{RecordsPath: "results[1].rows"},
+			expectedDF{[]string{"a"}, nil, [][]interface{}{{"q"}, {"r"}}},
+			"",
+		},
+		{
+			`{"grid":[[{"a":"p"}],[{"a":"q"}]]}`,
+			JSONLoadOptions{RecordsPath: "grid[1]"},
+			expectedDF{[]string{"a"}, nil, [][]interface{}{{"q"}}},
+			"",
+		},
+		{
+			`{"data":{"items":[]}}`,
+			JSONLoadOptions{RecordsPath: "data.missing"},
+			expectedDF{},
+			"RecordsPath not found: data.missing",
+		},
+		{
+			`{"data":[{"a":1}]}`,
+			JSONLoadOptions{RecordsPath: "data[3]"},
+			expectedDF{},
+			"RecordsPath not found: data[3]",
+		},
+		{
+			`{"data":[{"a":1}]}`,
+			JSONLoadOptions{RecordsPath: "data[x]"},
+			expectedDF{},
+			"invalid RecordsPath: data[x]",
+		},
+		{
+			`{"data":{"a":1}}`,
+			JSONLoadOptions{RecordsPath: "data"},
+			expectedDF{},
+			"RecordsPath does not select an array: data",
+		},
+		// FlattenSeparator and FlattenDepth
+		{
+			`{"a":{"b":{"c":1},"d":2}}`,
+			JSONLoadOptions{},
+			expectedDF{[]string{"a.b.c", "a.d"}, nil, [][]interface{}{{"1", "2"}}},
+			"",
+		},
+		{
+			`{"a":{"b":{"c":1},"d":2}}`,
+			JSONLoadOptions{FlattenSeparator: "_"},
+			expectedDF{[]string{"a_b_c", "a_d"}, nil, [][]interface{}{{"1", "2"}}},
+			"",
+		},
+		{
+			`{"a":{"b":{"c":1},"d":2}}`,
+			JSONLoadOptions{FlattenDepth: &one},
+			expectedDF{[]string{"a.b", "a.d"}, nil, [][]interface{}{{`{"c":1}`, "2"}}},
+			"",
+		},
+		{
+			`{"a":{"b":{"c":1},"d":2}}`,
+			JSONLoadOptions{FlattenDepth: &zero},
+			expectedDF{[]string{"a"}, nil, [][]interface{}{{`{"b":{"c":1},"d":2}`}}},
+			"",
+		},
+		// Duplicate names in the other layouts
+		{
+			`{"a":[1],"a":[2]}`,
+			JSONLoadOptions{Orient: OrientColumns},
+			expectedDF{},
+			"duplicate field name: a",
+		},
+		{
+			`{"columns":["a","b","a"],"data":[[1,2,3]]}`,
+			JSONLoadOptions{Orient: OrientSplit},
+			expectedDF{},
+			"duplicate field name: a",
+		},
+		{
+			`{"schema":{"fields":[{"name":"a","type":"int64"},{"name":"a","type":"string"}]},"data":[{"a":1}]}`,
+			JSONLoadOptions{Orient: OrientTable},
+			expectedDF{},
+			"duplicate field name: a",
+		},
+		// List-valued fields
+		{
+			`{"a":[1,"x"],"b":"y"}` + "\n" + `{"a":[],"b":[2,3]}`,
+			JSONLoadOptions{},
+			expectedDF{[]string{"a", "b"}, []string{"string", "string"}, [][]interface{}{{nil, "y"}, {nil, nil}}},
+			"",
+		},
+		{
+			`{"a":[1,"x",{"c":2}],"b":"y"}` + "\n" + `{"a":null,"b":[2,3]}`,
+			JSONLoadOptions{KeepArrays: true},
+			expectedDF{[]string{"a", "b"}, []string{"mixed", "string"}, [][]interface{}{{[]interface{}{1.0, "x", map[string]interface{}{"c": 2.0}}, "y"}, {nil, "[2,3]"}}},
+			"",
+		},
+	}
+
+	for i, tc := range tests {
+		for _, largeDataSet := range []bool{false, true} {
+			opts := tc.options
+			opts.LargeDataSet = largeDataSet
+
+			df, err := LoadFromJSON(ctx, strings.NewReader(tc.data), opts)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Errorf("%d: wrong error. expected: %s actual: %v", i, tc.err, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%d: unexpected error: %v", i, err)
+				continue
+			}
+
+			checkDataFrame(t, fmt.Sprintf("%d (LargeDataSet: %v)", i, largeDataSet), df, tc.expected)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	dataframe "github.com/rocketlaunchr/dataframe-go"
@@ -23,8 +24,119 @@ const (
 	PostgreSQL Database = 0
 	// MySQL database
 	MySQL Database = 1
+	// SQLite database
+	SQLite Database = 2
+	// MSSQL (Microsoft SQL Server) database
+	MSSQL Database = 3
+	// Oracle database
+	Oracle Database = 4
 )
 
+// databaseTypeName maps the database type name of a column to the
+// equivalent type name recognized by LoadFromSQL.
+func databaseTypeName(database Database, typ string) string {
+	typ = strings.ToUpper(typ)
+
+	switch database {
+	case SQLite:
+		return sqliteAffinity(typ)
+	case MSSQL:
+		switch typ {
+		case "BIT":
+			return "BOOL"
+		case "REAL":
+			return "FLOAT"
+		case "MONEY", "SMALLMONEY":
+			return "DECIMAL"
+		case "CHAR", "NCHAR", "NTEXT", "UNIQUEIDENTIFIER", "XML", "SQL_VARIANT":
+			return "TEXT"
+		case "IMAGE":
+			return "BLOB"
+		case "DATE", "DATETIME2", "SMALLDATETIME", "DATETIMEOFFSET":
+			return "DATETIME"
+		}
+	case Oracle:
+		switch typ {
+		case "NUMBER":
+			return "NUMERIC"
+		case "BINARY_FLOAT", "BINARY_DOUBLE":
+			return "FLOAT"
+		case "VARCHAR2", "NVARCHAR2", "CHAR", "NCHAR", "CLOB", "NCLOB", "LONG", "ROWID":
+			return "TEXT"
+		case "DATE", "TIMESTAMP WITH TIME ZONE", "TIMESTAMP WITH LOCAL TIME ZONE":
+			return "DATETIME"
+		case "RAW", "LONG RAW", "BFILE":
+			return "BLOB"
+		case "INTERVAL YEAR TO MONTH", "INTERVAL DAY TO SECOND":
+			return "INTERVAL"
+		}
+	}
+	return typ
+}
+
+// sqliteAffinity maps the declared type of a SQLite column to the
+// equivalent type name recognized by LoadFromSQL.
+//
+// See: https://www.sqlite.org/datatype3.html#determination_of_column_affinity
+func sqliteAffinity(typ string) string {
+	typ = strings.ToUpper(typ)
+
+	switch {
+	case strings.Contains(typ, "INT"):
+		return "INT"
+	case strings.Contains(typ, "CHAR"), strings.Contains(typ, "CLOB"), strings.Contains(typ, "TEXT"):
+		return "TEXT"
+	case strings.Contains(typ, "BLOB"), typ == "":
+		// Affinity is unknown
+		return ""
+	case strings.Contains(typ, "REAL"), strings.Contains(typ, "FLOA"), strings.Contains(typ, "DOUB"):
+		return "FLOAT"
+	case strings.HasPrefix(typ, "BOOL"):
+		return "BOOL"
+	case strings.Contains(typ, "DATE"), strings.Contains(typ, "TIME"):
+		return "DATETIME"
+	default:
+		// NUMERIC affinity
+		return "NUMERIC"
+	}
+}
+
+// sqlTimeLayouts returns the layouts used to parse time values.
+func sqlTimeLayouts(database Database) []string {
+	switch database {
+	case MySQL:
+		return []string{"2006-01-02 15:04:05", "2006-01-02"}
+	case MSSQL, Oracle:
+		return []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
+	case SQLite:
+		// SQLite has no time storage class. These are the formats
+		// understood by its date and time functions.
+		return []string{"2006-01-02 15:04:05", "2006-01-02 15:04:05.999999999", time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02"}
+	default:
+		// Default for PostgreSQL. Drivers that return text use the ISO output format.
+		return []string{time.RFC3339, "2006-01-02 15:04:05Z07:00", "2006-01-02 15:04:05Z07", "2006-01-02 15:04:05", "2006-01-02"}
+	}
+}
+
+// parseSQLTime parses a time value. If it can't be parsed using the layouts of the database, it
+// is assumed to be a unix timestamp.
+func parseSQLTime(database Database, val string, row int, fieldName string) (time.Time, error) {
+	layouts := sqlTimeLayouts(database)
+	for _, layout := range layouts {
+		t, err := time.Parse(layout, val)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	// Assume unix timestamp
+	sec, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("can't force string: %s to time.Time (%s). row: %d field: %s", val, strings.Join(layouts, ", "), row, fieldName)
+	}
+	return time.Unix(sec, 0), nil
+}
+
 type queryContexter1 interface {
 	QueryContext(ctx context.Context, args ...interface{}) (*sql.Rows, error)
 }
@@ -66,8 +178,30 @@ type SQLLoadOptions struct {
 	// eg. For a string use "". For a int64 use int64(0). What is relevant is the data type and not the value itself.
 	//
 	// NOTE: A custom Series must implement NewSerieser interface and be able to interpret strings to work.
+	// Any other data type loads the raw values as strings into a SeriesGeneric.
 	DictateDataType map[string]interface{}
 
+	// Schema can be used in place of DictateDataType. The data type of each field is determined by its Type.
+	// DictateDataType takes precedence for fields found in both.
+	//
+	// NOTE: Nullable and Constraints are not checked. Use Schema.Validate after loading.
+	Schema *dataframe.Schema
+
+	// TypeMapper is used to determine the data type of columns that are not found in DictateDataType.
+	// It can be used to support vendor-specific types. It must return a value that follows the conventions
+	// of DictateDataType, or nil to use the default mapping for the column's database type.
+	//
+	// Example:
+	//
+	//  TypeMapper: func(ct *sql.ColumnType) interface{} {
+	//     if ct.DatabaseTypeName() == "GEOGRAPHY" {
+	//        return geoSeries // implements dataframe.NewSerieser
+	//     }
+	//     return nil
+	//  },
+	//
+	TypeMapper func(ct *sql.ColumnType) interface{}
+
 	// Database is used to set the Database.
 	Database Database
 
@@ -80,6 +214,18 @@ type SQLLoadOptions struct {
 // LoadFromSQL will load data from a sql database.
 // stmt must be a *sql.Stmt or the equivalent from the mysql-go package.
 //
+// The data type of each column is determined by its database type:
+//
+//  string:        CHAR, VARCHAR, TEXT, ENUM, JSON, UUID, INTERVAL, BYTEA, BLOB etc.
+//  float64:       FLOAT, DOUBLE, DECIMAL, NUMERIC etc.
+//  int64:         INT, BIGINT, YEAR etc. BOOL is stored as 0 or 1.
+//  time.Time:     DATE, DATETIME, TIMESTAMP, TIMESTAMPTZ etc.
+//  time.Duration: TIME (stored in a SeriesGeneric).
+//  []interface{}: PostgreSQL arrays (stored in a SeriesMixed). The elements are converted using the element type.
+//
+// Binary data is stored as a string. An error is returned for unsupported database types unless
+// DictateDataType or TypeMapper is used to set the data type.
+//
 // See: https://godoc.org/github.com/rocketlaunchr/mysql-go#Stmt
 func LoadFromSQL(ctx context.Context, stmt interface{}, options *SQLLoadOptions, args ...interface{}) (*dataframe.DataFrame, error) {
 
@@ -99,11 +245,218 @@ func LoadFromSQL(ctx context.Context, stmt interface{}, options *SQLLoadOptions,
 		}
 
 		database = options.Database
-		if database != PostgreSQL && database != MySQL {
+		if database != PostgreSQL && database != MySQL && database != SQLite && database != MSSQL && database != Oracle {
 			return nil, errors.New("invalid database")
 		}
 	}
 
+	rows, err := querySQL(ctx, stmt, options, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, _ := rows.ColumnTypes()
+	if len(cols) <= 0 {
+		return nil, errors.New("no series found")
+	}
+
+	types, err := sqlDataTypes(database, cols, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the dataframe
+	df = dataframe.NewDataFrame(sqlSeries(cols, types, init)...)
+
+	for rows.Next() {
+		row++
+
+		insertVals, err := sqlRecord(rows, database, cols, types, row)
+		if err != nil {
+			return nil, err
+		}
+
+		if init == nil {
+			df.Append(&dataframe.DontLock, make([]interface{}, len(df.Series))...)
+		}
+		df.UpdateRow(row-1, &dataframe.DontLock, insertVals)
+
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Remove unused preallocated rows from dataframe
+	if init != nil {
+		excess := init.Size - row
+		for {
+			if excess <= 0 {
+				break
+			}
+			df.Remove(df.NRows() - 1) // remove current last row
+			excess--
+		}
+	}
+
+	return df, nil
+}
+
+// LoadFromSQLInChunks will load data from a sql database in chunks of chunkSize rows.
+// It returns an iterator that returns a new DataFrame containing the next chunk of rows each time it is called,
+// along with the index of the result set that the rows belong to (starting at 0).
+// When there are no more rows, the iterator returns nil.
+//
+// Unlike LoadFromSQL, the entire result set is never held in memory, which makes it suitable for very large tables.
+// Every DataFrame of a result set has the same Series (with the same names and data types) which are fixed
+// by the column types and DictateDataType. When the query returns multiple result sets, each result set
+// starts a new DataFrame. Result sets without rows are skipped. The KnownRowCount option is ignored.
+//
+// The query is executed when the iterator is first called. If the iterator is not called until it returns nil or
+// an error, ctx must be canceled to release the database connection.
+//
+// Example:
+//
+//  iterator := imports.LoadFromSQLInChunks(ctx, db, 10000, &imports.SQLLoadOptions{Query: "SELECT * FROM test"})
+//
+//  for {
+//     df, _, err := iterator()
+//     if err != nil {
+//        return err
+//     }
+//     if df == nil {
+//        break
+//     }
+//     exports.ExportToSQL(ctx, tx, df, "test_copy")
+//  }
+//
+func LoadFromSQLInChunks(ctx context.Context, stmt interface{}, chunkSize int, options *SQLLoadOptions, args ...interface{}) func() (*dataframe.DataFrame, int, error) {
+
+	if chunkSize <= 0 {
+		panic("chunkSize must be greater than 0")
+	}
+
+	var (
+		rows      rows
+		cols      []*sql.ColumnType
+		types     []interface{}
+		database  Database
+		resultSet int
+		row       int  // position of the row in the current result set
+		endOfSet  bool // no more rows in the current result set
+		done      bool
+	)
+
+	init := &dataframe.SeriesInit{Capacity: chunkSize}
+
+	fail := func(err error) (*dataframe.DataFrame, int, error) {
+		done = true
+		if rows != nil {
+			rows.Close()
+		}
+		return nil, resultSet, err
+	}
+
+	return func() (*dataframe.DataFrame, int, error) {
+
+		if done {
+			return nil, resultSet, nil
+		}
+
+		if rows == nil {
+			if options != nil {
+				database = options.Database
+				if database != PostgreSQL && database != MySQL && database != SQLite && database != MSSQL && database != Oracle {
+					return fail(errors.New("invalid database"))
+				}
+			}
+
+			var err error
+			rows, err = querySQL(ctx, stmt, options, args...)
+			if err != nil {
+				return fail(err)
+			}
+
+			cols, _ = rows.ColumnTypes()
+			if len(cols) <= 0 {
+				return fail(errors.New("no series found"))
+			}
+
+			types, err = sqlDataTypes(database, cols, options)
+			if err != nil {
+				return fail(err)
+			}
+		}
+
+		var df *dataframe.DataFrame
+
+		for df == nil || df.NRows(dataframe.DontLock) < chunkSize {
+
+			if err := ctx.Err(); err != nil {
+				return fail(err)
+			}
+
+			if !endOfSet && rows.Next() {
+				row++
+
+				insertVals, err := sqlRecord(rows, database, cols, types, row)
+				if err != nil {
+					return fail(err)
+				}
+
+				if df == nil {
+					df = dataframe.NewDataFrame(sqlSeries(cols, types, init)...)
+				}
+				df.Append(&dataframe.DontLock, make([]interface{}, len(df.Series))...)
+				df.UpdateRow(df.NRows(dataframe.DontLock)-1, &dataframe.DontLock, insertVals)
+				continue
+			}
+
+			if !endOfSet {
+				if err := rows.Err(); err != nil {
+					return fail(err)
+				}
+				endOfSet = true
+			}
+
+			if df != nil {
+				// Return the remaining rows of the current result set
+				return df, resultSet, nil
+			}
+
+			// Move to the next result set
+			if !rows.NextResultSet() {
+				if err := rows.Err(); err != nil {
+					return fail(err)
+				}
+				done = true
+				rows.Close()
+				return nil, resultSet, nil
+			}
+
+			resultSet++
+			row = 0
+			endOfSet = false
+
+			var err error
+			cols, err = rows.ColumnTypes()
+			if err != nil {
+				return fail(err)
+			}
+
+			types, err = sqlDataTypes(database, cols, options)
+			if err != nil {
+				return fail(err)
+			}
+		}
+
+		return df, resultSet, nil
+	}
+}
+
+// querySQL executes the query of stmt.
+func querySQL(ctx context.Context, stmt interface{}, options *SQLLoadOptions, args ...interface{}) (rows, error) {
+
 	var (
 		rows rows
 		err  error
@@ -133,229 +486,376 @@ func LoadFromSQL(ctx context.Context, stmt interface{}, options *SQLLoadOptions,
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return rows, nil
+}
 
-	cols, _ := rows.ColumnTypes()
-	totalColumns := len(cols)
+// sqlArray is the data type of a PostgreSQL array column. elem is the data type of the elements.
+// The arrays are stored in a SeriesMixed as []interface{}.
+type sqlArray struct {
+	elem interface{}
+}
 
-	if totalColumns <= 0 {
-		return nil, errors.New("no series found")
+// sqlDataTypes determines the data type of each column of a result set.
+// The data type is dictated by DictateDataType (or Schema), followed by TypeMapper and then the database type name.
+func sqlDataTypes(database Database, cols []*sql.ColumnType, options *SQLLoadOptions) ([]interface{}, error) {
+
+	var dictated map[string]interface{}
+	if options != nil {
+		dictated = options.DictateDataType
+		if options.Schema != nil {
+			var err error
+			dictated, err = schemaDataTypes(options.Schema, options.DictateDataType)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	// Create the dataframe
-	seriess := []dataframe.Series{}
+	types := []interface{}{}
 	for _, ct := range cols { // ct is ColumnType
 		name := ct.Name()
-		typ := ct.DatabaseTypeName()
+
+		var dtyp interface{}
 
 		// Check if data type is dictated and use if available
-		if options != nil && len(options.DictateDataType) > 0 {
-			if dtyp, exists := options.DictateDataType[name]; exists {
-
-				switch T := dtyp.(type) {
-				case float64:
-					seriess = append(seriess, dataframe.NewSeriesFloat64(name, init))
-				case int64, bool:
-					seriess = append(seriess, dataframe.NewSeriesInt64(name, init))
-				case string:
-					seriess = append(seriess, dataframe.NewSeriesString(name, init))
-				case time.Time:
-					seriess = append(seriess, dataframe.NewSeriesTime(name, init))
-				case dataframe.NewSerieser:
-					seriess = append(seriess, T.NewSeries(name, init))
-				case Converter:
-					switch T.ConcreteType.(type) {
-					case time.Time:
-						seriess = append(seriess, dataframe.NewSeriesTime(name, init))
-					default:
-						seriess = append(seriess, dataframe.NewSeriesGeneric(name, T.ConcreteType, init))
-					}
-				default:
-					seriess = append(seriess, dataframe.NewSeriesGeneric(name, typ, init))
-				}
+		if len(dictated) > 0 {
+			dtyp = dictated[name]
+		}
 
-				continue
+		if dtyp == nil && options != nil && options.TypeMapper != nil {
+			dtyp = options.TypeMapper(ct)
+		}
+
+		if dtyp == nil {
+			typ := databaseTypeName(database, ct.DatabaseTypeName())
+			dtyp = sqlDataType(typ)
+			if dtyp == nil {
+				return nil, fmt.Errorf("unsupported database type: %s field: %s (use DictateDataType or TypeMapper)", typ, name)
 			}
 		}
 
-		// Use typ if info is available
-		switch typ {
-		case "VARCHAR", "TEXT", "NVARCHAR", "MEDIUMTEXT", "LONGTEXT":
-			seriess = append(seriess, dataframe.NewSeriesString(name, init))
-		case "FLOAT", "FLOAT4", "FLOAT8", "DOUBLE", "DECIMAL", "NUMERIC":
+		types = append(types, dtyp)
+	}
+
+	return types, nil
+}
+
+// sqlDataType returns the data type for a database type name (as returned by databaseTypeName).
+// nil is returned if the database type is not supported.
+func sqlDataType(typ string) interface{} {
+	switch typ {
+	case "VARCHAR", "TEXT", "NVARCHAR", "TINYTEXT", "MEDIUMTEXT", "LONGTEXT", "CHAR", "BPCHAR", "NAME", "CITEXT",
+		"JSON", "JSONB", "UUID", "ENUM", "SET", "XML", "INET", "CIDR", "MACADDR", "MONEY", "BIT", "VARBIT",
+		"INTERVAL", "TIMETZ", "BYTEA", "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BINARY", "VARBINARY", "GEOMETRY":
+		return ""
+	case "FLOAT", "FLOAT4", "FLOAT8", "REAL", "DOUBLE", "DECIMAL", "NUMERIC":
+		return float64(0)
+	case "INT", "INTEGER", "TINYINT", "INT2", "INT4", "INT8", "MEDIUMINT", "SMALLINT", "BIGINT", "YEAR", "OID",
+		"UNSIGNED TINYINT", "UNSIGNED SMALLINT", "UNSIGNED MEDIUMINT", "UNSIGNED INT", "UNSIGNED BIGINT":
+		return int64(0)
+	case "BOOL":
+		return false
+	case "DATE", "DATETIME", "TIMESTAMP", "TIMESTAMPTZ":
+		return time.Time{}
+	case "TIME":
+		return durationConverter
+	case "":
+		// Assume string if info is not available
+		return ""
+	}
+
+	// PostgreSQL array types are prefixed with an underscore. eg. _INT4
+	if strings.HasPrefix(typ, "_") {
+		elem := sqlDataType(typ[1:])
+		if elem == nil {
+			elem = ""
+		}
+		return sqlArray{elem: elem}
+	}
+
+	return nil
+}
+
+// sqlSeries creates the Series for the columns of a result set.
+func sqlSeries(cols []*sql.ColumnType, types []interface{}, init *dataframe.SeriesInit) []dataframe.Series {
+
+	seriess := []dataframe.Series{}
+	for i, ct := range cols { // ct is ColumnType
+		name := ct.Name()
+
+		switch T := types[i].(type) {
+		case float64:
 			seriess = append(seriess, dataframe.NewSeriesFloat64(name, init))
-		case "BOOL", "INT", "TINYINT", "INT2", "INT4", "INT8", "MEDIUMINT", "SMALLINT", "BIGINT":
+		case int64, bool:
 			seriess = append(seriess, dataframe.NewSeriesInt64(name, init))
-		case "DATETIME", "TIMESTAMP", "TIMESTAMPTZ":
-			seriess = append(seriess, dataframe.NewSeriesTime(name, init))
-		case "":
-			// Assume string
-			seriess = append(seriess, dataframe.NewSeriesString(name, init))
-		default: // assume string if info is not available
+		case string:
 			seriess = append(seriess, dataframe.NewSeriesString(name, init))
+		case time.Time:
+			seriess = append(seriess, dataframe.NewSeriesTime(name, init))
+		case dataframe.NewSerieser:
+			seriess = append(seriess, T.NewSeries(name, init))
+		case Converter:
+			switch T.ConcreteType.(type) {
+			case time.Time:
+				seriess = append(seriess, dataframe.NewSeriesTime(name, init))
+			default:
+				seriess = append(seriess, dataframe.NewSeriesGeneric(name, T.ConcreteType, init))
+			}
+		case sqlArray:
+			seriess = append(seriess, dataframe.NewSeriesMixed(name, init))
+		default:
+			seriess = append(seriess, dataframe.NewSeriesGeneric(name, "", init)) // values are not converted
 		}
+	}
+
+	return seriess
+}
 
+// sqlRecord scans the current row and converts it to the values that are inserted into the DataFrame.
+// row is the position of the row in the result set, starting at 1.
+func sqlRecord(rows rows, database Database, cols []*sql.ColumnType, types []interface{}, row int) (map[string]interface{}, error) {
+
+	rowData := make([]interface{}, len(cols))
+	for i := range rowData {
+		rowData[i] = &[]byte{}
 	}
-	df = dataframe.NewDataFrame(seriess...)
 
-	for rows.Next() {
-		row++
+	if err := rows.Scan(rowData...); err != nil {
+		return nil, err
+	}
+
+	insertVals := map[string]interface{}{}
+	for colID, elem := range rowData {
 
-		rowData := make([]interface{}, totalColumns)
-		for i := range rowData {
-			rowData[i] = &[]byte{}
+		fieldName := cols[colID].Name()
+
+		raw := elem.(*[]byte)
+		if raw == nil || *raw == nil {
+			insertVals[fieldName] = nil
+			continue
 		}
 
-		if err := rows.Scan(rowData...); err != nil {
+		v, err := sqlConvert(database, types[colID], string(*raw), row-1, fieldName)
+		if err != nil {
 			return nil, err
 		}
+		insertVals[fieldName] = v
+	}
 
-		insertVals := map[string]interface{}{}
-		for colID, elem := range rowData {
-
-			colType := cols[colID].DatabaseTypeName()
-			fieldName := cols[colID].Name()
+	return insertVals, nil
+}
 
-			var val *string
+// sqlConvert converts val to the data type typ.
+func sqlConvert(database Database, typ interface{}, val string, row int, fieldName string) (interface{}, error) {
 
-			raw := elem.(*[]byte)
-			if !(raw == nil || *raw == nil) {
-				val = &[]string{string(*raw)}[0]
+	switch T := typ.(type) {
+	case float64:
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("can't force string: %s to float64. row: %d field: %s", val, row, fieldName)
+		}
+		return f, nil
+	case int64:
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("can't force string: %s to Int. row: %d field: %s", val, row, fieldName)
+		}
+		return n, nil
+	case bool:
+		switch val {
+		case "true", "TRUE", "t", "1":
+			return int64(1), nil
+		case "false", "FALSE", "f", "0":
+			return int64(0), nil
+		}
+		return nil, fmt.Errorf("can't force string: %s to bool. row: %d field: %s", val, row, fieldName)
+	case time.Time:
+		return parseSQLTime(database, val, row, fieldName)
+	case Converter:
+		cv, err := T.ConverterFunc(val)
+		if err != nil {
+			return nil, fmt.Errorf("can't force string: %s to generic data type. row: %d field: %s", val, row, fieldName)
+		}
+		return cv, nil
+	case sqlArray:
+		var convErr error
+		arr, err := parsePGArray(val, func(elem string) (interface{}, error) {
+			v, err := sqlConvert(database, T.elem, elem, row, fieldName)
+			convErr = err
+			return v, err
+		})
+		if err != nil {
+			if convErr != nil {
+				return nil, convErr
 			}
+			return nil, fmt.Errorf("can't force string: %s to array (%v). row: %d field: %s", val, err, row, fieldName)
+		}
+		return arr, nil
+	default:
+		// string, dataframe.NewSerieser and other dictated data types
+		return val, nil
+	}
+}
 
-			if val == nil {
-				insertVals[fieldName] = nil
-				continue
-			}
+// parseDuration parses a TIME value. eg. 15:04:05, 15:04:05.999999 or -838:59:59 (MySQL).
+func parseDuration(in interface{}) (interface{}, error) {
 
-			if options != nil && len(options.DictateDataType) > 0 {
-				if dtyp, exists := options.DictateDataType[fieldName]; exists {
-
-					switch T := dtyp.(type) {
-					case float64:
-						f, err := strconv.ParseFloat(*val, 64)
-						if err != nil {
-							return nil, fmt.Errorf("can't force string: %s to float64. row: %d field: %s", *val, row-1, fieldName)
-						}
-						insertVals[fieldName] = f
-					case int64:
-						n, err := strconv.ParseInt(*val, 10, 64)
-						if err != nil {
-							return nil, fmt.Errorf("can't force string: %s to Int. row: %d field: %s", *val, row-1, fieldName)
-						}
-						insertVals[fieldName] = n
-					case string:
-						insertVals[fieldName] = *val
-					case bool:
-						if *val == "true" || *val == "TRUE" || *val == "1" {
-							insertVals[fieldName] = int64(1)
-						} else if *val == "false" || *val == "FALSE" || *val == "0" {
-							insertVals[fieldName] = int64(0)
-						} else {
-							return nil, fmt.Errorf("can't force string: %s to bool. row: %d field: %s", *val, row-1, fieldName)
-						}
-					case time.Time:
-						layout := time.RFC3339 // Default for PostgreSQL
-						if database == MySQL {
-							layout = "2006-01-02 15:04:05"
-						}
-
-						t, err := time.Parse(layout, *val)
-						if err != nil {
-							// Assume unix timestamp
-							sec, err := strconv.ParseInt(*val, 10, 64)
-							if err != nil {
-								return nil, fmt.Errorf("can't force string: %s to time.Time (%s). row: %d field: %s", *val, layout, row-1, fieldName)
-							}
-							t = time.Unix(sec, 0)
-						}
-						insertVals[fieldName] = t
-					case dataframe.NewSerieser:
-						insertVals[fieldName] = *val
-					case Converter:
-						cv, err := T.ConverterFunc(*val)
-						if err != nil {
-							return nil, fmt.Errorf("can't force string: %s to generic data type. row: %d field: %s", *val, row-1, fieldName)
-						}
-						insertVals[fieldName] = cv
-					default:
-						insertVals[fieldName] = *val
-					}
+	val := in.(string)
 
-					continue
-				}
-			}
+	// Some drivers return a time.Time (on the zero date) which is converted to RFC3339
+	if strings.Contains(val, "T") {
+		t, err := time.Parse(time.RFC3339Nano, val)
+		if err != nil {
+			return nil, err
+		}
+		return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+			time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond()), nil
+	}
 
-			switch colType {
-			case "VARCHAR", "TEXT", "NVARCHAR", "MEDIUMTEXT", "LONGTEXT":
-				insertVals[fieldName] = *val
-			case "FLOAT", "DOUBLE", "DECIMAL", "NUMERIC", "FLOAT4", "FLOAT8":
-				f, err := strconv.ParseFloat(*val, 64)
-				if err != nil {
-					return nil, fmt.Errorf("can't force string: %s to float64. row: %d field: %s", *val, row-1, fieldName)
-				}
-				insertVals[fieldName] = f
-			case "INT", "TINYINT", "INT2", "INT4", "INT8", "MEDIUMINT", "SMALLINT", "BIGINT":
-				n, err := strconv.ParseInt(*val, 10, 64)
-				if err != nil {
-					return nil, fmt.Errorf("can't force string: %s to Int. row: %d field: %s", *val, row-1, fieldName)
-				}
-				insertVals[fieldName] = n
-			case "BOOL":
-				if *val == "true" || *val == "TRUE" || *val == "1" {
-					insertVals[fieldName] = int64(1)
-				} else if *val == "false" || *val == "FALSE" || *val == "0" {
-					insertVals[fieldName] = int64(0)
-				} else {
-					return nil, fmt.Errorf("can't force string: %s to bool. row: %d field: %s", *val, row-1, fieldName)
-				}
-			case "DATETIME", "TIMESTAMP", "TIMESTAMPTZ":
-				layout := time.RFC3339 // Default for PostgreSQL
-				if database == MySQL {
-					layout = "2006-01-02 15:04:05"
-				}
+	neg := strings.HasPrefix(val, "-")
+	if neg {
+		val = val[1:]
+	}
 
-				t, err := time.Parse(layout, *val)
-				if err != nil {
-					// Assume unix timestamp
-					sec, err := strconv.ParseInt(*val, 10, 64)
-					if err != nil {
-						return nil, fmt.Errorf("can't force string: %s to time.Time (%s). row: %d field: %s", *val, layout, row-1, fieldName)
-					}
-					t = time.Unix(sec, 0)
-				}
-				insertVals[fieldName] = t
-			default:
-				// Assume string
-				insertVals[fieldName] = *val
-			}
+	parts := strings.Split(val, ":")
+	if len(parts) != 3 {
+		return nil, errors.New("invalid time")
+	}
+
+	var frac string
+	if idx := strings.Index(parts[2], "."); idx != -1 {
+		parts[2], frac = parts[2][:idx], parts[2][idx+1:]
+		if len(frac) > 9 {
+			frac = frac[:9]
 		}
+		frac = frac + strings.Repeat("0", 9-len(frac))
+	} else {
+		frac = "0"
+	}
 
-		if init == nil {
-			df.Append(&dataframe.DontLock, make([]interface{}, len(df.Series))...)
+	var d time.Duration
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second, time.Nanosecond} {
+		var field string
+		if i < 3 {
+			field = parts[i]
+		} else {
+			field = frac
 		}
-		df.UpdateRow(row-1, &dataframe.DontLock, insertVals)
 
+		n, err := strconv.ParseUint(field, 10, 63)
+		if err != nil || (i > 0 && i < 3 && n >= 60) {
+			return nil, errors.New("invalid time")
+		}
+		d = d + time.Duration(n)*unit
+	}
+
+	if neg {
+		d = -d
 	}
-	if err := rows.Err(); err != nil {
+	return d, nil
+}
+
+// parsePGArray parses the text representation of a PostgreSQL array. eg. {1,2,NULL} or {{"a b",c},{d,e}}.
+// Each element is converted using conv. Multi-dimensional arrays are returned as nested slices.
+func parsePGArray(val string, conv func(elem string) (interface{}, error)) ([]interface{}, error) {
+
+	// Remove the optional dimensions. eg. [0:1]={1,2}
+	if strings.HasPrefix(val, "[") {
+		if idx := strings.Index(val, "="); idx != -1 {
+			val = val[idx+1:]
+		}
+	}
+
+	arr, n, err := parsePGArrayAt(val, 0, conv)
+	if err != nil {
 		return nil, err
 	}
+	if n != len(val) {
+		return nil, errors.New("unexpected characters after array")
+	}
+	return arr, nil
+}
+
+// parsePGArrayAt parses the array that starts at position i of val.
+// It returns the position after the array.
+func parsePGArrayAt(val string, i int, conv func(elem string) (interface{}, error)) ([]interface{}, int, error) {
 
-	if df == nil {
-		return nil, dataframe.ErrNoRows
+	errMalformed := errors.New("malformed array")
+
+	if i >= len(val) || val[i] != '{' {
+		return nil, 0, errMalformed
 	}
+	i++
 
-	// Remove unused preallocated rows from dataframe
-	if init != nil {
-		excess := init.Size - df.NRows()
-		for {
-			if excess <= 0 {
+	arr := []interface{}{}
+	if i < len(val) && val[i] == '}' {
+		return arr, i + 1, nil
+	}
+
+	for {
+		if i >= len(val) {
+			return nil, 0, errMalformed
+		}
+
+		switch val[i] {
+		case '{':
+			sub, n, err := parsePGArrayAt(val, i, conv)
+			if err != nil {
+				return nil, 0, err
+			}
+			arr = append(arr, sub)
+			i = n
+		case '"':
+			var sb strings.Builder
+			for i++; i < len(val) && val[i] != '"'; i++ {
+				if val[i] == '\\' {
+					i++
+					if i >= len(val) {
+						break
+					}
+				}
+				sb.WriteByte(val[i])
+			}
+			if i >= len(val) {
+				return nil, 0, errMalformed
+			}
+			i++ // closing quote
+
+			v, err := conv(sb.String())
+			if err != nil {
+				return nil, 0, err
+			}
+			arr = append(arr, v)
+		default:
+			start := i
+			for i < len(val) && val[i] != ',' && val[i] != '}' {
+				i++
+			}
+
+			elem := strings.TrimSpace(val[start:i])
+			if strings.EqualFold(elem, "NULL") {
+				arr = append(arr, nil)
 				break
 			}
-			df.Remove(df.NRows() - 1) // remove current last row
-			excess--
+
+			v, err := conv(elem)
+			if err != nil {
+				return nil, 0, err
+			}
+			arr = append(arr, v)
 		}
-	}
 
-	return df, nil
+		if i >= len(val) {
+			return nil, 0, errMalformed
+		}
+
+		switch val[i] {
+		case ',':
+			i++
+		case '}':
+			return arr, i + 1, nil
+		default:
+			return nil, 0, errMalformed
+		}
+	}
 }
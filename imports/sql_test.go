@@ -0,0 +1,64 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package imports
+
+import (
+	"testing"
+	"time"
+)
+
+// The tests that load from and export to a real SQLite database are in the sqlitetest module.
+
+func TestSQLiteAffinity(t *testing.T) {
+
+	tests := map[string]string{
+		"INTEGER":           "INT",
+		"int":               "INT",
+		"UNSIGNED BIG INT":  "INT",
+		"VARCHAR(255)":      "TEXT",
+		"NATIVE CHARACTER":  "TEXT",
+		"CLOB":              "TEXT",
+		"TEXT":              "TEXT",
+		"BLOB":              "",
+		"":                  "",
+		"REAL":              "FLOAT",
+		"DOUBLE PRECISION":  "FLOAT",
+		"FLOAT":             "FLOAT",
+		"BOOLEAN":           "BOOL",
+		"DATE":              "DATETIME",
+		"DATETIME":          "DATETIME",
+		"TIMESTAMP":         "DATETIME",
+		"NUMERIC":           "NUMERIC",
+		"DECIMAL(10,5)":     "NUMERIC",
+		"CHARINT":           "INT", // INT takes precedence
+		"FLOATING POINT":    "INT",
+		"STRING":            "NUMERIC",
+		"VARYING CHARACTER": "TEXT",
+	}
+
+	for typ, expected := range tests {
+		if actual := sqliteAffinity(typ); actual != expected {
+			t.Errorf("%q: expected %q actual %q", typ, expected, actual)
+		}
+	}
+}
+
+func TestSQLiteTime(t *testing.T) {
+
+	expected := time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	for _, v := range []string{"2020-03-04 05:06:07", "2020-03-04T05:06:07Z", "2020-03-04T05:06:07", "1583298367"} {
+		actual, err := parseSQLTime(SQLite, v, 0, "t")
+		if err != nil {
+			t.Errorf("%s: %v", v, err)
+			continue
+		}
+		if !actual.Equal(expected) {
+			t.Errorf("%s: expected %v actual %v", v, expected, actual)
+		}
+	}
+
+	if _, err := parseSQLTime(SQLite, "yesterday", 0, "t"); err == nil {
+		t.Errorf("expected error")
+	}
+}
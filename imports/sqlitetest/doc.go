@@ -0,0 +1,7 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+// Package sqlitetest tests LoadFromSQL and ExportToSQL against a real SQLite database.
+//
+// It is a separate module so that users of dataframe-go don't inherit the dependencies of the
+// SQLite engine. Run the tests from this directory using go test.
+package sqlitetest
@@ -0,0 +1,10 @@
+module github.com/rocketlaunchr/dataframe-go/imports/sqlitetest
+
+go 1.12
+
+require (
+	github.com/rocketlaunchr/dataframe-go v0.0.0
+	modernc.org/sqlite v1.20.0
+)
+
+replace github.com/rocketlaunchr/dataframe-go => ../../
@@ -0,0 +1,419 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package sqlitetest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+	"github.com/rocketlaunchr/dataframe-go/exports"
+	"github.com/rocketlaunchr/dataframe-go/imports"
+	"modernc.org/sqlite"
+)
+
+// sqliteCounter wraps the modernc.org/sqlite driver and records the number of
+// arguments of each statement executed, so that the batching of ExportToSQL can be checked.
+type sqliteCounter struct {
+	mu    sync.Mutex
+	execs []int
+}
+
+type sqliteCounterConn struct {
+	driver.Conn
+	c *sqliteCounter
+}
+
+type sqliteCounterStmt struct {
+	driver.Stmt
+	c *sqliteCounter
+}
+
+var (
+	sqliteDSN   int
+	sqliteDSNMu sync.Mutex
+)
+
+// openSQLite returns a new empty in-memory database.
+func openSQLite(t *testing.T) (*sql.DB, *sqliteCounter) {
+	sqliteDSNMu.Lock()
+	sqliteDSN++
+	name := fmt.Sprintf("sqlite-test-%d", sqliteDSN)
+	sqliteDSNMu.Unlock()
+
+	c := &sqliteCounter{}
+	sql.Register(name, c)
+
+	db, err := sql.Open(name, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1) // Each connection to :memory: is a separate database
+	return db, c
+}
+
+func (c *sqliteCounter) Open(name string) (driver.Conn, error) {
+	conn, err := (&sqlite.Driver{}).Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteCounterConn{conn, c}, nil
+}
+
+func (c *sqliteCounterConn) Prepare(query string) (driver.Stmt, error) {
+	stmt, err := c.Conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteCounterStmt{stmt, c.c}, nil
+}
+
+func (s *sqliteCounterStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.c.mu.Lock()
+	s.c.execs = append(s.c.execs, len(args))
+	s.c.mu.Unlock()
+	return s.Stmt.Exec(args)
+}
+
+func TestSQLiteRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	db, _ := openSQLite(t)
+	defer db.Close()
+
+	_, err := db.ExecContext(ctx, `CREATE TABLE "test" ("id" INTEGER, "name" VARCHAR(20), "price" REAL, "qty" NUMERIC, "active" BOOLEAN, "created" DATETIME, "note" BLOB)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	df := dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("id", nil, 1, 2, 3),
+		dataframe.NewSeriesString("name", nil, "apple", nil, "it's \"quoted\""),
+		dataframe.NewSeriesFloat64("price", nil, 1.5, -2.25, nil),
+		dataframe.NewSeriesFloat64("qty", nil, 10, 2.5, 0),
+		dataframe.NewSeriesInt64("active", nil, 1, 0, nil),
+		dataframe.NewSeriesTime("created", nil, created, nil, created.Add(time.Hour)),
+		dataframe.NewSeriesString("note", nil, "a", "b", nil),
+	)
+
+	err = exports.ExportToSQL(ctx, db, df, "test", exports.SQLExportOptions{Database: exports.SQLite})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := imports.LoadFromSQL(ctx, db, &imports.SQLLoadOptions{Database: imports.SQLite, Query: `SELECT * FROM "test"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(loaded.Series) != len(df.Series) {
+		t.Fatalf("expected %d series actual %d", len(df.Series), len(loaded.Series))
+	}
+
+	for i := range df.Series {
+		eq, err := df.Series[i].IsEqual(ctx, loaded.Series[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !eq {
+			t.Errorf("series %s not equal:\nexpected: %v\nactual: %v", df.Series[i].Name(), df.Series[i], loaded.Series[i])
+		}
+	}
+
+	// Dictated data types
+	loaded, err = imports.LoadFromSQL(ctx, db, &imports.SQLLoadOptions{
+		Database:        imports.SQLite,
+		Query:           `SELECT * FROM "test"`,
+		DictateDataType: map[string]interface{}{"id": "", "active": true},
+		KnownRowCount:   &[]int{10}[0],
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.NRows() != 3 {
+		t.Errorf("expected 3 rows actual %d", loaded.NRows())
+	}
+	if _, ok := loaded.Series[0].(*dataframe.SeriesString); !ok {
+		t.Errorf("expected id to be a SeriesString actual %T", loaded.Series[0])
+	}
+	if v := loaded.Series[4].Value(0); v != int64(1) {
+		t.Errorf("expected active to be 1 actual %v", v)
+	}
+
+	// Other data types are loaded as strings into a SeriesGeneric
+	loaded, err = imports.LoadFromSQL(ctx, db, &imports.SQLLoadOptions{
+		Database:        imports.SQLite,
+		Query:           `SELECT * FROM "test"`,
+		DictateDataType: map[string]interface{}{"qty": uint8(0)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := loaded.Series[3].(*dataframe.SeriesGeneric); !ok {
+		t.Errorf("expected qty to be a SeriesGeneric actual %T", loaded.Series[3])
+	}
+	if v := loaded.Series[3].Value(1); v != "2.5" {
+		t.Errorf("expected qty to be 2.5 actual %v", v)
+	}
+}
+
+func TestSQLiteMaxVariables(t *testing.T) {
+	ctx := context.Background()
+
+	const nRows = 1000
+
+	ids := make([]interface{}, nRows)
+	for i := range ids {
+		ids[i] = i
+	}
+
+	df := dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("a", nil, ids...),
+		dataframe.NewSeriesInt64("b", nil, ids...),
+		dataframe.NewSeriesInt64("c", nil, ids...),
+	)
+
+	tests := []struct {
+		batchSize *uint
+		execs     []int
+	}{
+		{nil, []int{999, 999, 999, 3}},
+		{&[]uint{500}[0], []int{999, 999, 999, 3}},
+		{&[]uint{400}[0], []int{999, 999, 999, 3}},
+		{&[]uint{300}[0], []int{900, 900, 900, 300}},
+	}
+
+	for i, tc := range tests {
+		db, d := openSQLite(t)
+
+		_, err := db.ExecContext(ctx, `CREATE TABLE "test" ("a" INTEGER, "b" INTEGER, "c" INTEGER)`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.execs = nil
+
+		err = exports.ExportToSQL(ctx, db, df, "test", exports.SQLExportOptions{Database: exports.SQLite, BatchSize: tc.batchSize})
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+
+		if fmt.Sprint(d.execs) != fmt.Sprint(tc.execs) {
+			t.Errorf("%d: expected %v actual %v", i, tc.execs, d.execs)
+		}
+
+		loaded, err := imports.LoadFromSQL(ctx, db, &imports.SQLLoadOptions{Database: imports.SQLite, Query: `SELECT * FROM "test"`})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded.NRows() != nRows {
+			t.Errorf("%d: expected %d rows actual %d", i, nRows, loaded.NRows())
+		}
+
+		db.Close()
+	}
+
+	// Too many columns to insert even a single row
+	seriess := []dataframe.Series{}
+	for i := 0; i < 1000; i++ {
+		seriess = append(seriess, dataframe.NewSeriesInt64(strconv.Itoa(i), nil, 1))
+	}
+
+	db, _ := openSQLite(t)
+	defer db.Close()
+
+	err := exports.ExportToSQL(ctx, db, dataframe.NewDataFrame(seriess...), "test", exports.SQLExportOptions{Database: exports.SQLite})
+	if err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestLoadFromSQLInChunks(t *testing.T) {
+	ctx := context.Background()
+
+	db, _ := openSQLite(t)
+	defer db.Close()
+
+	for _, stmt := range []string{`CREATE TABLE "a" ("id" INTEGER, "name" TEXT)`, `CREATE TABLE "b" ("price" REAL)`, `CREATE TABLE "c" ("id" INTEGER)`} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids := []interface{}{}
+	names := []interface{}{}
+	for i := 0; i < 7; i++ {
+		ids = append(ids, i)
+		names = append(names, strconv.Itoa(i))
+	}
+
+	a := dataframe.NewDataFrame(dataframe.NewSeriesInt64("id", nil, ids...), dataframe.NewSeriesString("name", nil, names...))
+	b := dataframe.NewDataFrame(dataframe.NewSeriesFloat64("price", nil, 1.5, nil, 2.5))
+
+	if err := exports.ExportToSQL(ctx, db, a, "a", exports.SQLExportOptions{Database: exports.SQLite}); err != nil {
+		t.Fatal(err)
+	}
+	if err := exports.ExportToSQL(ctx, db, b, "b", exports.SQLExportOptions{Database: exports.SQLite}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query     string
+		chunkSize int
+		expected  []string // result set and rows of each chunk
+	}{
+		{`SELECT * FROM "a"`, 3, []string{"0: [0 1 2]", "0: [3 4 5]", "0: [6]"}},
+		{`SELECT * FROM "a"`, 7, []string{"0: [0 1 2 3 4 5 6]"}},
+		{`SELECT * FROM "a"`, 10, []string{"0: [0 1 2 3 4 5 6]"}},
+		{`SELECT * FROM "b"`, 2, []string{"0: [1.5 NaN]", "0: [2.5]"}},
+		{`SELECT * FROM "c"`, 5, []string{}},
+	}
+
+	for i, tc := range tests {
+		iterator := imports.LoadFromSQLInChunks(ctx, db, tc.chunkSize, &imports.SQLLoadOptions{Database: imports.SQLite, Query: tc.query})
+
+		actual := []string{}
+		for {
+			df, resultSet, err := iterator()
+			if err != nil {
+				t.Fatalf("%d: %v", i, err)
+			}
+			if df == nil {
+				break
+			}
+			vals := []string{}
+			for row := 0; row < df.NRows(); row++ {
+				vals = append(vals, df.Series[0].ValueString(row))
+			}
+			actual = append(actual, fmt.Sprintf("%d: %v", resultSet, vals))
+		}
+
+		if fmt.Sprint(actual) != fmt.Sprint(tc.expected) {
+			t.Errorf("%d: expected %v actual %v", i, tc.expected, actual)
+		}
+
+		// Exhausted
+		if df, _, err := iterator(); df != nil || err != nil {
+			t.Errorf("%d: expected iterator to be exhausted", i)
+		}
+	}
+
+	// Errors
+	iterator := imports.LoadFromSQLInChunks(ctx, db, 2, &imports.SQLLoadOptions{Database: imports.SQLite, Query: `SELECT * FROM "unknown"`})
+	if _, _, err := iterator(); err == nil {
+		t.Errorf("expected error")
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	iterator = imports.LoadFromSQLInChunks(cctx, db, 2, &imports.SQLLoadOptions{Database: imports.SQLite, Query: `SELECT * FROM "a"`})
+	if _, _, err := iterator(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, _, err := iterator(); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestLoadFromSQLTypes(t *testing.T) {
+	ctx := context.Background()
+
+	db, _ := openSQLite(t)
+	defer db.Close()
+
+	// imports.SQLite accepts any declared type, so the type names reported by imports.PostgreSQL are used
+	exec := func(query string, args ...interface{}) {
+		t.Helper()
+		if _, err := db.ExecContext(ctx, query, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	exec(`CREATE TABLE "pg" ("code" bpchar, "doc" JSONB, "id" UUID, "day" DATE, "at" TIME, "span" INTERVAL, "tags" _INT4, "grid" _TEXT, "flags" _BOOL, "raw" BYTEA)`)
+	exec(`INSERT INTO "pg" VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, "AU", `{"a": 1}`, "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11", "2020-02-29", "13:04:05.5", "1 day", "{1,NULL,3}", `{{"a b","c\"d"},{NULL,e}}`, "{t,f}", []byte{0, 1})
+	exec(`INSERT INTO "pg" VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, nil, nil, nil, nil, "-01:00:00", nil, "{}", nil, nil, nil)
+
+	df, err := imports.LoadFromSQL(ctx, db, &imports.SQLLoadOptions{Database: imports.PostgreSQL, Query: `SELECT * FROM "pg"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedTypes := []string{"string", "string", "string", "time", "time.Duration", "string", "mixed", "mixed", "mixed", "string"}
+	for i, s := range df.Series {
+		if s.Type() != expectedTypes[i] {
+			t.Errorf("%s: expected type %s actual %s", s.Name(), expectedTypes[i], s.Type())
+		}
+	}
+
+	expected := []interface{}{
+		"AU", `{"a": 1}`, "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11",
+		time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC),
+		13*time.Hour + 4*time.Minute + 5500*time.Millisecond,
+		"1 day",
+		[]interface{}{int64(1), nil, int64(3)},
+		[]interface{}{[]interface{}{"a b", `c"d`}, []interface{}{nil, "e"}},
+		[]interface{}{int64(1), int64(0)},
+		"\x00\x01",
+	}
+	for i, s := range df.Series {
+		if actual := s.Value(0); !dataframe.DefaultIsEqualFunc(actual, expected[i]) {
+			t.Errorf("%s: expected %v actual %v", s.Name(), expected[i], actual)
+		}
+	}
+
+	if actual := df.Series[4].Value(1); actual != -time.Hour {
+		t.Errorf("expected %v actual %v", -time.Hour, actual)
+	}
+	if actual := df.Series[6].Value(1); !dataframe.DefaultIsEqualFunc(actual, []interface{}{}) {
+		t.Errorf("expected empty array actual %v", actual)
+	}
+
+	// Unsupported type
+	exec(`CREATE TABLE "geo" ("location" GEOGRAPHY)`)
+
+	_, err = imports.LoadFromSQL(ctx, db, &imports.SQLLoadOptions{Database: imports.PostgreSQL, Query: `SELECT * FROM "geo"`})
+	if err == nil || !strings.Contains(err.Error(), "field: location") {
+		t.Errorf("expected error naming the column actual %v", err)
+	}
+
+	// TypeMapper
+	exec(`INSERT INTO "geo" VALUES (?)`, "POINT(1 2)")
+
+	opts := &imports.SQLLoadOptions{
+		Database: imports.PostgreSQL,
+		Query:    `SELECT * FROM "geo"`,
+		TypeMapper: func(ct *sql.ColumnType) interface{} {
+			if ct.DatabaseTypeName() == "GEOGRAPHY" {
+				return dataframe.NewSeriesMixed("", nil)
+			}
+			return nil
+		},
+	}
+
+	df, err = imports.LoadFromSQL(ctx, db, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if df.Series[0].Type() != "mixed" || df.Series[0].Value(0) != "POINT(1 2)" {
+		t.Errorf("expected TypeMapper to be used actual %s %v", df.Series[0].Type(), df.Series[0].Value(0))
+	}
+
+	// Malformed array
+	exec(`CREATE TABLE "arr" ("tags" _INT8)`)
+	exec(`INSERT INTO "arr" VALUES (?)`, "{1,x}")
+
+	_, err = imports.LoadFromSQL(ctx, db, &imports.SQLLoadOptions{Database: imports.PostgreSQL, Query: `SELECT * FROM "arr"`})
+	if err == nil || !strings.Contains(err.Error(), "field: tags") {
+		t.Errorf("expected error naming the column actual %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package dataframe
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// ErrNoIndex signifies that the DataFrame has no index.
+var ErrNoIndex = errors.New("no index")
+
+// SetIndex designates Series of the DataFrame as the index. The index labels the rows and
+// is preserved by Sort, Filter, Apply and Copy. It is displayed by Table instead of the row numbers.
+// When more than one Series is provided, the index is hierarchical (in the order provided).
+//
+// The index Series remain part of the DataFrame. Calling SetIndex without any names removes the index.
+//
+// Example:
+//
+//  df.SetIndex([]string{"region", "day"})
+//  rows, _ := df.Loc([]interface{}{"AU", 3})
+//
+func (df *DataFrame) SetIndex(names []string, opts ...Options) error {
+	if len(opts) == 0 || !opts[0].DontLock {
+		df.lock.Lock()
+		defer df.lock.Unlock()
+	}
+
+	index := []Series{}
+	seen := map[string]struct{}{}
+
+	for _, name := range names {
+		if _, exists := seen[name]; exists {
+			return fmt.Errorf("duplicate index series: %s", name)
+		}
+		seen[name] = struct{}{}
+
+		col, err := df.NameToColumn(name, dontLock)
+		if err != nil {
+			return fmt.Errorf("series not found: %s", name)
+		}
+		index = append(index, df.Series[col])
+	}
+
+	if len(index) == 0 {
+		index = nil
+	}
+	df.index = index
+
+	return nil
+}
+
+// AddRowNumberIndex inserts a SeriesInt64 containing the current row numbers as the first Series
+// and designates it as the index. It can be used to keep track of the original rows after a Sort or Filter.
+func (df *DataFrame) AddRowNumberIndex(name string, opts ...Options) error {
+	if len(opts) == 0 || !opts[0].DontLock {
+		df.lock.Lock()
+		defer df.lock.Unlock()
+	}
+
+	if _, err := df.NameToColumn(name, dontLock); err == nil {
+		return fmt.Errorf("series already exists: %s", name)
+	}
+
+	vals := make([]interface{}, 0, df.n)
+	for row := 0; row < df.n; row++ {
+		vals = append(vals, row)
+	}
+	s := NewSeriesInt64(name, &SeriesInit{Capacity: df.n}, vals...)
+
+	df.Series = append([]Series{s}, df.Series...)
+	df.index = []Series{s}
+
+	return nil
+}
+
+// Index returns the names of the index Series. It returns nil if the DataFrame has no index.
+func (df *DataFrame) Index(opts ...Options) []string {
+	if len(opts) == 0 || !opts[0].DontLock {
+		df.lock.RLock()
+		defer df.lock.RUnlock()
+	}
+
+	var names []string
+	for _, col := range df.indexColumns() {
+		names = append(names, df.Series[col].Name(dontLock))
+	}
+	return names
+}
+
+// Loc returns the rows whose index labels match key. For a hierarchical index, key must
+// be a []interface{} containing a value for each level. A partial key containing values for only
+// the leading levels matches all rows with those labels. Integers are treated as int64 and float32 as float64.
+// Numeric values are converted to the data type of the index Series (eg. Loc(2) matches 2.0 in a SeriesFloat64).
+// An empty slice is returned if no rows match.
+func (df *DataFrame) Loc(key interface{}, opts ...Options) ([]int, error) {
+	if len(opts) == 0 || !opts[0].DontLock {
+		df.lock.RLock()
+		defer df.lock.RUnlock()
+	}
+
+	cols := df.indexColumns()
+	if len(cols) == 0 {
+		return nil, ErrNoIndex
+	}
+
+	vals, ok := key.([]interface{})
+	if !ok {
+		vals = []interface{}{key}
+	}
+
+	if len(vals) == 0 || len(vals) > len(cols) {
+		return nil, fmt.Errorf("key must contain between 1 and %d values", len(cols))
+	}
+
+	keys := make([]interface{}, 0, len(vals))
+	for _, v := range vals {
+		keys = append(keys, normalizeValue(v))
+	}
+
+	rows := []int{}
+
+	for row := 0; row < df.n; row++ {
+		if df.matchesIndex(cols, row, keys) {
+			rows = append(rows, row)
+		}
+	}
+
+	return rows, nil
+}
+
+// matchesIndex returns true if the index labels of row match keys.
+func (df *DataFrame) matchesIndex(cols []int, row int, keys []interface{}) bool {
+	for i, k := range keys {
+		s := df.Series[cols[i]]
+		val := s.Value(row, dontLock)
+
+		if val == nil || k == nil {
+			if val != nil || k != nil {
+				return false
+			}
+			continue
+		}
+
+		// Numeric keys are converted to the data type of the index Series
+		switch v := k.(type) {
+		case int64:
+			if _, ok := val.(float64); ok {
+				k = float64(v)
+			}
+		case float64:
+			if _, ok := val.(int64); ok && v == math.Trunc(v) {
+				k = int64(v)
+			}
+		}
+
+		if reflect.TypeOf(val) != reflect.TypeOf(k) || !s.IsEqualFunc(val, k) {
+			return false
+		}
+	}
+	return true
+}
+
+// isIndexUnique returns true if the DataFrame has an index and each combination of
+// index labels (as returned by ValueString) appears only once.
+func (df *DataFrame) isIndexUnique() bool {
+	cols := df.indexColumns()
+	if len(cols) == 0 {
+		return false
+	}
+
+	seen := map[string]struct{}{}
+	for row := 0; row < df.n; row++ {
+		labels := make([]string, 0, len(cols))
+		for _, col := range cols {
+			labels = append(labels, df.Series[col].ValueString(row, dontLock))
+		}
+
+		key := strings.Join(labels, "\x00")
+		if _, exists := seen[key]; exists {
+			return false
+		}
+		seen[key] = struct{}{}
+	}
+	return true
+}
+
+// indexColumns returns the positions of the index Series.
+// Index Series that are no longer part of the DataFrame are ignored.
+func (df *DataFrame) indexColumns() []int {
+	cols := []int{}
+	for _, s := range df.index {
+		for col := range df.Series {
+			if df.Series[col] == s {
+				cols = append(cols, col)
+				break
+			}
+		}
+	}
+	return cols
+}
+
+// inheritIndex designates the Series of ndf at the positions of df's index Series as ndf's index.
+// The column levels are also inherited. ndf must have the same Series layout as df.
+func (df *DataFrame) inheritIndex(ndf *DataFrame) {
+	for _, col := range df.indexColumns() {
+		ndf.index = append(ndf.index, ndf.Series[col])
+	}
+	ndf.levelSep = df.levelSep
+}
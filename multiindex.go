@@ -0,0 +1,445 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package dataframe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultLevelSeparator is used by Unstack when the DataFrame has no column levels.
+const DefaultLevelSeparator = "|"
+
+// SetColumnLevels sets the separator used to split the names of the Series into hierarchical levels.
+// eg. With a separator of "|", the Series named "AU|sales" has the levels "AU" and "sales".
+// The levels are displayed by Table as separate header lines. An empty separator removes the levels.
+//
+// The index Series are not split into levels.
+func (df *DataFrame) SetColumnLevels(sep string, opts ...Options) {
+	if len(opts) == 0 || !opts[0].DontLock {
+		df.lock.Lock()
+		defer df.lock.Unlock()
+	}
+
+	df.levelSep = sep
+}
+
+// ColumnLevels returns the levels of the name of each Series (excluding the index Series).
+// If the DataFrame has no column levels, each name has 1 level.
+func (df *DataFrame) ColumnLevels(opts ...Options) [][]string {
+	if len(opts) == 0 || !opts[0].DontLock {
+		df.lock.RLock()
+		defer df.lock.RUnlock()
+	}
+
+	_, _, _, isIndex := df.rowLabels()
+
+	out := [][]string{}
+	for idx, s := range df.Series {
+		if isIndex[idx] {
+			continue
+		}
+		out = append(out, df.levels(s.Name(dontLock)))
+	}
+	return out
+}
+
+// SelectLevels returns a new DataFrame containing copies of the Series whose leading levels match key,
+// along with copies of the index Series. The matching levels are removed from the names of the returned Series.
+//
+// Example:
+//
+//  // Series: region, AU|sales, AU|cost, NZ|sales, NZ|cost
+//  au, err := df.SelectLevels([]string{"AU"}) // Series: region, sales, cost
+//
+func (df *DataFrame) SelectLevels(key []string, opts ...Options) (*DataFrame, error) {
+	if len(opts) == 0 || !opts[0].DontLock {
+		df.lock.RLock()
+		defer df.lock.RUnlock()
+	}
+
+	if len(key) == 0 {
+		return nil, errors.New("key must contain at least 1 value")
+	}
+
+	_, _, _, isIndex := df.rowLabels()
+
+	ndf := &DataFrame{Series: []Series{}, n: df.n, levelSep: df.levelSep}
+	found := false
+
+	for idx, s := range df.Series {
+		if isIndex[idx] {
+			ns := s.Copy()
+			ndf.Series = append(ndf.Series, ns)
+			ndf.index = append(ndf.index, ns)
+			continue
+		}
+
+		levels := df.levels(s.Name(dontLock))
+		if len(levels) <= len(key) || !equalStrings(levels[:len(key)], key) {
+			continue
+		}
+
+		ns := s.Copy()
+		ns.Rename(strings.Join(levels[len(key):], df.levelSep))
+		ndf.Series = append(ndf.Series, ns)
+		found = true
+	}
+
+	if !found {
+		return nil, fmt.Errorf("key not found: %v", key)
+	}
+
+	// A renamed Series can collide with an index Series
+	names := map[string]struct{}{}
+	for _, s := range ndf.Series {
+		name := s.Name(dontLock)
+		if _, exists := names[name]; exists {
+			return nil, fmt.Errorf("series already exists: %s", name)
+		}
+		names[name] = struct{}{}
+	}
+
+	return ndf, nil
+}
+
+// Stack returns a new DataFrame where the last level of the Series names is moved into the row index.
+// A SeriesString called name is added to the index containing the level. Each row of df becomes
+// one row for each distinct last level. Values that don't exist are set to nil.
+// If the Series that are combined have different types, a SeriesMixed is created.
+//
+// Example:
+//
+//  // Index: day  Series: AU|sales, AU|cost, NZ|sales, NZ|cost
+//  sdf, err := df.Stack(ctx, "metric")
+//  // Index: day, metric  Series: AU, NZ
+//
+// See: Unstack
+func (df *DataFrame) Stack(ctx context.Context, name string, opts ...Options) (*DataFrame, error) {
+	if len(opts) == 0 || !opts[0].DontLock {
+		df.lock.RLock()
+		defer df.lock.RUnlock()
+	}
+
+	if df.levelSep == "" {
+		return nil, errors.New("dataframe has no column levels")
+	}
+
+	_, _, _, isIndex := df.rowLabels()
+
+	var (
+		prefixes = []string{}
+		lasts    = []string{}
+		grid     = map[string]map[string]Series{} // prefix -> last level -> Series
+	)
+
+	for idx, s := range df.Series {
+		if isIndex[idx] {
+			if s.Name(dontLock) == name {
+				return nil, fmt.Errorf("series already exists: %s", name)
+			}
+			continue
+		}
+
+		levels := df.levels(s.Name(dontLock))
+		if len(levels) < 2 {
+			return nil, fmt.Errorf("series %s: expected at least 2 levels", s.Name(dontLock))
+		}
+
+		prefix := strings.Join(levels[:len(levels)-1], df.levelSep)
+		last := levels[len(levels)-1]
+
+		if _, exists := grid[prefix]; !exists {
+			grid[prefix] = map[string]Series{}
+			prefixes = append(prefixes, prefix)
+		}
+		if !containsString(lasts, last) {
+			lasts = append(lasts, last)
+		}
+		grid[prefix][last] = s
+	}
+
+	if len(prefixes) == 0 {
+		return nil, errors.New("dataframe has no series to stack")
+	}
+
+	// The stacked Series must not collide with the index Series
+	names := map[string]struct{}{name: {}}
+	for _, col := range df.indexColumns() {
+		names[df.Series[col].Name(dontLock)] = struct{}{}
+	}
+	for _, prefix := range prefixes {
+		if _, exists := names[prefix]; exists {
+			return nil, fmt.Errorf("series already exists: %s", prefix)
+		}
+	}
+
+	nRows := df.n * len(lasts)
+	ndf := &DataFrame{Series: []Series{}, n: nRows, levelSep: df.levelSep}
+
+	// Create the index Series
+	indexCols := df.indexColumns()
+	for _, col := range indexCols {
+		ns, err := newSeriesLike(df.Series[col], df.Series[col].Name(dontLock), nRows)
+		if err != nil {
+			return nil, err
+		}
+		ndf.Series = append(ndf.Series, ns)
+		ndf.index = append(ndf.index, ns)
+	}
+
+	level := NewSeriesString(name, &SeriesInit{Size: nRows})
+	ndf.Series = append(ndf.Series, level)
+	ndf.index = append(ndf.index, level)
+
+	// Create the stacked Series
+	for _, prefix := range prefixes {
+		var typ Series
+		for _, s := range grid[prefix] {
+			if typ == nil {
+				typ = s
+			} else if typ.Type() != s.Type() {
+				typ = &SeriesMixed{}
+				break
+			}
+		}
+
+		ns, err := newSeriesLike(typ, prefix, nRows)
+		if err != nil {
+			return nil, err
+		}
+		ndf.Series = append(ndf.Series, ns)
+	}
+
+	nrow := 0
+	for row := 0; row < df.n; row++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		for _, last := range lasts {
+			for i, col := range indexCols {
+				ndf.Series[i].Update(nrow, df.Series[col].Value(row, dontLock), dontLock)
+			}
+			level.Update(nrow, last, dontLock)
+
+			for i, prefix := range prefixes {
+				if s, exists := grid[prefix][last]; exists {
+					ndf.Series[len(indexCols)+1+i].Update(nrow, s.Value(row, dontLock), dontLock)
+				}
+			}
+			nrow++
+		}
+	}
+
+	return ndf, nil
+}
+
+// Unstack returns a new DataFrame where the index Series called name is moved into the Series names
+// as their last level. The rows are grouped by the remaining index Series. Values that don't exist are set to nil.
+// If the DataFrame has no column levels, DefaultLevelSeparator is used.
+//
+// Example:
+//
+//  // Index: day, region  Series: sales, cost
+//  udf, err := df.Unstack(ctx, "region")
+//  // Index: day  Series: sales|AU, sales|NZ, cost|AU, cost|NZ
+//
+// See: Stack
+func (df *DataFrame) Unstack(ctx context.Context, name string, opts ...Options) (*DataFrame, error) {
+	if len(opts) == 0 || !opts[0].DontLock {
+		df.lock.RLock()
+		defer df.lock.RUnlock()
+	}
+
+	sep := df.levelSep
+	if sep == "" {
+		sep = DefaultLevelSeparator
+	}
+
+	_, _, _, isIndex := df.rowLabels()
+
+	levelCol := -1
+	groupCols := []int{}
+	for _, col := range df.indexColumns() {
+		if df.Series[col].Name(dontLock) == name {
+			levelCol = col
+		} else {
+			groupCols = append(groupCols, col)
+		}
+	}
+
+	if levelCol == -1 {
+		return nil, fmt.Errorf("index series not found: %s", name)
+	}
+
+	// Determine the groups of rows and the distinct levels
+	var (
+		groups     = map[string]int{} // group key -> row of new DataFrame
+		groupRows  = []int{}          // first row of each group
+		levels     = []string{}
+		levelIdx   = map[string]int{}
+		rowGroup   = make([]int, df.n)
+		rowLevel   = make([]int, df.n)
+		seenGroups = map[[2]int]struct{}{}
+	)
+
+	for row := 0; row < df.n; row++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		keys := []string{}
+		for _, col := range groupCols {
+			keys = append(keys, df.Series[col].ValueString(row, dontLock))
+		}
+		key := strings.Join(keys, "\x00")
+
+		g, exists := groups[key]
+		if !exists {
+			g = len(groupRows)
+			groups[key] = g
+			groupRows = append(groupRows, row)
+		}
+
+		lvl := df.Series[levelCol].ValueString(row, dontLock)
+		l, exists := levelIdx[lvl]
+		if !exists {
+			l = len(levels)
+			levelIdx[lvl] = l
+			levels = append(levels, lvl)
+		}
+
+		if _, exists := seenGroups[[2]int{g, l}]; exists {
+			return nil, fmt.Errorf("duplicate index entry: %s", df.indexString(row))
+		}
+		seenGroups[[2]int{g, l}] = struct{}{}
+
+		rowGroup[row], rowLevel[row] = g, l
+	}
+
+	nRows := len(groupRows)
+	ndf := &DataFrame{Series: []Series{}, n: nRows, levelSep: sep}
+
+	// Create the index Series
+	for _, col := range groupCols {
+		s := df.Series[col]
+		ns, err := newSeriesLike(s, s.Name(dontLock), nRows)
+		if err != nil {
+			return nil, err
+		}
+		for g, row := range groupRows {
+			ns.Update(g, s.Value(row, dontLock), dontLock)
+		}
+		ndf.Series = append(ndf.Series, ns)
+		ndf.index = append(ndf.index, ns)
+	}
+
+	// Create the unstacked Series
+	for idx, s := range df.Series {
+		if isIndex[idx] {
+			continue
+		}
+
+		nss := []Series{}
+		for _, lvl := range levels {
+			ns, err := newSeriesLike(s, s.Name(dontLock)+sep+lvl, nRows)
+			if err != nil {
+				return nil, err
+			}
+			nss = append(nss, ns)
+		}
+
+		for row := 0; row < df.n; row++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			nss[rowLevel[row]].Update(rowGroup[row], s.Value(row, dontLock), dontLock)
+		}
+
+		ndf.Series = append(ndf.Series, nss...)
+	}
+
+	return ndf, nil
+}
+
+// levels splits a Series name into its levels.
+func (df *DataFrame) levels(name string) []string {
+	if df.levelSep == "" {
+		return []string{name}
+	}
+	return strings.Split(name, df.levelSep)
+}
+
+// levelHeaders returns the Table headers for the Series names. Each level is displayed on a separate line.
+// A level is left blank if it is the same as the previous Series' (i.e. the header cells are merged).
+func (df *DataFrame) levelHeaders(names []string) []string {
+
+	if df.levelSep == "" {
+		return names
+	}
+
+	headers := []string{}
+
+	var prev []string
+	for _, name := range names {
+		levels := df.levels(name)
+
+		lines := make([]string, len(levels))
+		copy(lines, levels)
+
+		// Blank the leading levels that are the same as the previous Series
+		for i := 0; i < len(levels)-1 && i < len(prev)-1; i++ {
+			if levels[i] != prev[i] {
+				break
+			}
+			lines[i] = ""
+		}
+
+		headers = append(headers, strings.Join(lines, "\n"))
+		prev = levels
+	}
+
+	return headers
+}
+
+// indexString returns the index labels of row.
+func (df *DataFrame) indexString(row int) string {
+	vals := []string{}
+	for _, col := range df.indexColumns() {
+		vals = append(vals, df.Series[col].ValueString(row, dontLock))
+	}
+	return strings.Join(vals, ", ")
+}
+
+// newSeriesLike creates a new Series of the same type as s containing nRows nil values.
+func newSeriesLike(s Series, name string, nRows int) (Series, error) {
+	ns, ok := s.(NewSerieser)
+	if !ok {
+		return nil, fmt.Errorf("series %s: must implement NewSerieser interface", s.Name(dontLock))
+	}
+	return ns.NewSeries(name, &SeriesInit{Size: nRows}), nil
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,278 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package dataframe
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Schema describes the structure of a DataFrame. It can be serialized to JSON so that
+// producers and consumers of data can agree on the structure.
+//
+// Example:
+//
+//  {
+//     "fields": [
+//        {"name": "id", "type": "int64", "constraints": {"unique": true}},
+//        {"name": "price", "type": "float64", "nullable": true, "constraints": {"min": 0}}
+//     ]
+//  }
+//
+type Schema struct {
+	Fields []SchemaField `json:"fields"`
+}
+
+// SchemaField describes a Series.
+type SchemaField struct {
+
+	// Name is the name of the Series.
+	Name string `json:"name"`
+
+	// Type is the type of the Series as returned by its Type method. The built-in types are
+	// "float64", "int64", "string", "time", "mixed" and "time.Duration" (SeriesGeneric).
+	// Other types must be registered using RegisterSeriesType.
+	Type string `json:"type"`
+
+	// Nullable is set if the Series can contain nil values.
+	Nullable bool `json:"nullable,omitempty"`
+
+	// Constraints optionally restricts the values of the Series.
+	Constraints *SchemaConstraints `json:"constraints,omitempty"`
+}
+
+// SchemaConstraints restricts the non-nil values of a Series.
+type SchemaConstraints struct {
+
+	// Unique requires every value to be different.
+	Unique bool `json:"unique,omitempty"`
+
+	// Min is the minimum value of a SeriesFloat64 or SeriesInt64.
+	Min *float64 `json:"min,omitempty"`
+
+	// Max is the maximum value of a SeriesFloat64 or SeriesInt64.
+	Max *float64 `json:"max,omitempty"`
+
+	// MinLength is the minimum number of characters of the values (as returned by ValueString).
+	MinLength *int `json:"minLength,omitempty"`
+
+	// MaxLength is the maximum number of characters of the values (as returned by ValueString).
+	MaxLength *int `json:"maxLength,omitempty"`
+
+	// Pattern is a regular expression that the values (as returned by ValueString) must match.
+	Pattern string `json:"pattern,omitempty"`
+
+	// Enum is the list of allowed values (as returned by ValueString).
+	Enum []string `json:"enum,omitempty"`
+}
+
+var (
+	seriesTypesMu sync.RWMutex
+	seriesTypes   = map[string]NewSerieser{
+		"float64":       &SeriesFloat64{},
+		"int64":         &SeriesInt64{},
+		"string":        &SeriesString{},
+		"time":          &SeriesTime{},
+		"mixed":         &SeriesMixed{},
+		"time.Duration": NewSeriesGeneric("", time.Duration(0), nil),
+	}
+)
+
+// RegisterSeriesType registers a custom Series so that it can be used by a Schema.
+// typ must be the value returned by the Series' Type method.
+//
+// Example:
+//
+//  dataframe.RegisterSeriesType("uuid.UUID", dataframe.NewSeriesGeneric("", uuid.UUID{}, nil))
+//
+func RegisterSeriesType(typ string, ns NewSerieser) {
+	seriesTypesMu.Lock()
+	defer seriesTypesMu.Unlock()
+	seriesTypes[typ] = ns
+}
+
+// NewSeries creates a new initialized Series of the field's type.
+func (f SchemaField) NewSeries(init *SeriesInit) (Series, error) {
+	seriesTypesMu.RLock()
+	ns, exists := seriesTypes[f.Type]
+	seriesTypesMu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("unknown series type: %s field: %s", f.Type, f.Name)
+	}
+	return ns.NewSeries(f.Name, init), nil
+}
+
+// Schema returns the Schema of the DataFrame.
+// A field is Nullable if the Series contains nil values.
+func (df *DataFrame) Schema(opts ...Options) Schema {
+	if len(opts) == 0 || !opts[0].DontLock {
+		df.lock.RLock()
+		defer df.lock.RUnlock()
+	}
+
+	s := Schema{Fields: []SchemaField{}}
+	for _, series := range df.Series {
+		s.Fields = append(s.Fields, SchemaField{
+			Name:     series.Name(dontLock),
+			Type:     series.Type(),
+			Nullable: series.ContainsNil(dontLock),
+		})
+	}
+
+	return s
+}
+
+// NewDataFrame creates a new DataFrame with a Series for each field.
+func (s Schema) NewDataFrame(init *SeriesInit) (*DataFrame, error) {
+
+	names := map[string]struct{}{}
+
+	seriess := []Series{}
+	for _, f := range s.Fields {
+		if _, exists := names[f.Name]; exists {
+			return nil, fmt.Errorf("duplicate field: %s", f.Name)
+		}
+		names[f.Name] = struct{}{}
+
+		series, err := f.NewSeries(init)
+		if err != nil {
+			return nil, err
+		}
+		seriess = append(seriess, series)
+	}
+
+	return NewDataFrame(seriess...), nil
+}
+
+// Validate checks that the DataFrame conforms to the Schema.
+// The Series must be in the same order as the fields.
+//
+// If the DataFrame does not conform, an *ErrorCollection is returned.
+// Errors relating to a particular value are of type *RowError.
+func (s Schema) Validate(ctx context.Context, df *DataFrame, opts ...Options) error {
+	if len(opts) == 0 || !opts[0].DontLock {
+		df.lock.RLock()
+		defer df.lock.RUnlock()
+	}
+
+	ec := NewErrorCollection()
+
+	fields := map[string]int{}
+	for idx, f := range s.Fields {
+		if _, exists := fields[f.Name]; exists {
+			ec.AddError(fmt.Errorf("duplicate field: %s", f.Name), false)
+			continue
+		}
+		fields[f.Name] = idx
+	}
+
+	for _, series := range df.Series {
+		name := series.Name(dontLock)
+		if _, exists := fields[name]; !exists {
+			ec.AddError(fmt.Errorf("unexpected series: %s", name), false)
+		}
+	}
+
+	for idx, f := range s.Fields {
+
+		col, err := df.NameToColumn(f.Name, dontLock)
+		if err != nil {
+			ec.AddError(fmt.Errorf("series not found: %s", f.Name), false)
+			continue
+		}
+
+		if col != idx {
+			ec.AddError(fmt.Errorf("series %s: expected position %d actual %d", f.Name, idx, col), false)
+		}
+
+		series := df.Series[col]
+		if series.Type() != f.Type {
+			ec.AddError(fmt.Errorf("series %s: expected type %s actual %s", f.Name, f.Type, series.Type()), false)
+			continue
+		}
+
+		if err := f.validateValues(ctx, df, ec); err != nil {
+			return err
+		}
+	}
+
+	if ec.IsNil(false) {
+		return nil
+	}
+	return ec
+}
+
+// validateValues checks the values of a Series against the field's Nullable and Constraints settings.
+func (f SchemaField) validateValues(ctx context.Context, df *DataFrame, ec *ErrorCollection) error {
+
+	rules := f.Constraints.Rules()
+	if !f.Nullable {
+		rules = append([]Rule{NotNil}, rules...)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	report, err := Validate(ctx, df, map[string][]Rule{f.Name: rules}, ValidateOptions{DontLock: true})
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		ec.AddError(err, false)
+		return nil
+	}
+
+	for _, re := range report.Errors {
+		ec.AddError(re, false)
+	}
+	return nil
+}
+
+// Rules returns the Rules that check the constraints. They can be used with Validate.
+func (c *SchemaConstraints) Rules() []Rule {
+
+	rules := []Rule{}
+	if c == nil {
+		return rules
+	}
+
+	if c.Min != nil || c.Max != nil {
+		min, max := math.Inf(-1), math.Inf(1)
+		if c.Min != nil {
+			min = *c.Min
+		}
+		if c.Max != nil {
+			max = *c.Max
+		}
+		rules = append(rules, Between(min, max))
+	}
+
+	if c.MinLength != nil || c.MaxLength != nil {
+		min, max := 0, -1
+		if c.MinLength != nil {
+			min = *c.MinLength
+		}
+		if c.MaxLength != nil {
+			max = *c.MaxLength
+		}
+		rules = append(rules, Length(min, max))
+	}
+
+	if c.Pattern != "" {
+		rules = append(rules, Matches(c.Pattern))
+	}
+
+	if len(c.Enum) > 0 {
+		rules = append(rules, OneOf(c.Enum...))
+	}
+
+	if c.Unique {
+		rules = append(rules, Unique)
+	}
+
+	return rules
+}
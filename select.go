@@ -0,0 +1,229 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package dataframe
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+)
+
+// Glob selects the Series whose names match a shell pattern (eg. "sales_*").
+//
+// See: https://golang.org/pkg/path/#Match for the pattern syntax.
+type Glob string
+
+// OfType selects the Series whose Type matches (eg. "float64").
+type OfType string
+
+// SelectOptions modifies the behaviour of the Select and Drop functions.
+// It can be provided as one of the cols.
+type SelectOptions struct {
+
+	// Copy will create deep copies of the selected Series.
+	// By default, the returned DataFrame shares the Series with the original DataFrame.
+	Copy bool
+
+	// DontLock can be set to true if the DataFrame should not be locked.
+	DontLock bool
+}
+
+// Select returns a new DataFrame containing the selected Series. A Series can be selected by:
+// its column index (int), its name (string), a regular expression (*regexp.Regexp), a Glob or an OfType.
+// The Series are returned in the order of cols. A Series that is selected more than once is only included once.
+// The index and column levels are preserved.
+//
+// Example:
+//
+//  sdf, err := df.Select("id", regexp.MustCompile(`^sales_\d+$`), dataframe.OfType("float64"), dataframe.SelectOptions{Copy: true})
+//
+func (df *DataFrame) Select(cols ...interface{}) (*DataFrame, error) {
+
+	cols, opts := selectOptions(cols)
+
+	if !opts.DontLock {
+		df.lock.RLock()
+		defer df.lock.RUnlock()
+	}
+
+	selected, err := df.selectColumns(cols)
+	if err != nil {
+		return nil, err
+	}
+
+	return df.project(selected, opts.Copy), nil
+}
+
+// Drop returns a new DataFrame without the selected Series. The Series are selected in the same way as Select.
+// The index and column levels are preserved.
+func (df *DataFrame) Drop(cols ...interface{}) (*DataFrame, error) {
+
+	cols, opts := selectOptions(cols)
+
+	if !opts.DontLock {
+		df.lock.RLock()
+		defer df.lock.RUnlock()
+	}
+
+	selected, err := df.selectColumns(cols)
+	if err != nil {
+		return nil, err
+	}
+
+	drop := map[int]struct{}{}
+	for _, col := range selected {
+		drop[col] = struct{}{}
+	}
+
+	remaining := []int{}
+	for col := range df.Series {
+		if _, exists := drop[col]; !exists {
+			remaining = append(remaining, col)
+		}
+	}
+
+	return df.project(remaining, opts.Copy), nil
+}
+
+// RenameColumns renames the Series. The keys of names are the existing names and the values are the new names.
+// The DataFrame is not modified if an existing name is not found or if the new names are not unique.
+//
+// NOTE: The Series are renamed in place, so DataFrames that share the Series are also affected.
+func (df *DataFrame) RenameColumns(names map[string]string, opts ...Options) error {
+	if len(opts) == 0 || !opts[0].DontLock {
+		df.lock.Lock()
+		defer df.lock.Unlock()
+	}
+
+	newNames := make([]string, len(df.Series))
+	for col, s := range df.Series {
+		newNames[col] = s.Name(dontLock)
+	}
+
+	for from, to := range names {
+		col, err := df.NameToColumn(from, dontLock)
+		if err != nil {
+			return fmt.Errorf("series not found: %s", from)
+		}
+		newNames[col] = to
+	}
+
+	seen := map[string]struct{}{}
+	for _, name := range newNames {
+		if _, exists := seen[name]; exists {
+			return fmt.Errorf("duplicate series name: %s", name)
+		}
+		seen[name] = struct{}{}
+	}
+
+	for col, s := range df.Series {
+		if s.Name(dontLock) != newNames[col] {
+			s.Rename(newNames[col])
+		}
+	}
+
+	return nil
+}
+
+// selectOptions separates the SelectOptions from cols.
+func selectOptions(cols []interface{}) ([]interface{}, SelectOptions) {
+
+	var opts SelectOptions
+
+	out := make([]interface{}, 0, len(cols))
+	for _, c := range cols {
+		switch o := c.(type) {
+		case SelectOptions:
+			opts = o
+		case *SelectOptions:
+			opts = *o
+		default:
+			out = append(out, c)
+		}
+	}
+
+	return out, opts
+}
+
+// selectColumns returns the column indexes of the Series selected by cols.
+func (df *DataFrame) selectColumns(cols []interface{}) ([]int, error) {
+
+	selected := []int{}
+	seen := map[int]struct{}{}
+
+	add := func(col int) {
+		if _, exists := seen[col]; !exists {
+			seen[col] = struct{}{}
+			selected = append(selected, col)
+		}
+	}
+
+	match := func(fn func(s Series) bool) {
+		for col, s := range df.Series {
+			if fn(s) {
+				add(col)
+			}
+		}
+	}
+
+	for _, c := range cols {
+		switch v := c.(type) {
+		case int:
+			if v < 0 || v >= len(df.Series) {
+				return nil, fmt.Errorf("column out of range: %d", v)
+			}
+			add(v)
+		case string:
+			col, err := df.NameToColumn(v, dontLock)
+			if err != nil {
+				return nil, fmt.Errorf("series not found: %s", v)
+			}
+			add(col)
+		case *regexp.Regexp:
+			match(func(s Series) bool {
+				return v.MatchString(s.Name(dontLock))
+			})
+		case Glob:
+			if _, err := path.Match(string(v), ""); err != nil {
+				return nil, fmt.Errorf("invalid glob: %s: %v", v, err)
+			}
+			match(func(s Series) bool {
+				matched, _ := path.Match(string(v), s.Name(dontLock))
+				return matched
+			})
+		case OfType:
+			match(func(s Series) bool {
+				return s.Type() == string(v)
+			})
+		default:
+			return nil, fmt.Errorf("invalid column selector: %T", c)
+		}
+	}
+
+	return selected, nil
+}
+
+// project returns a new DataFrame containing the Series at cols.
+func (df *DataFrame) project(cols []int, deepCopy bool) *DataFrame {
+
+	ndf := &DataFrame{Series: []Series{}, n: df.n, levelSep: df.levelSep}
+
+	projected := map[int]Series{} // column of df -> Series of ndf
+	for _, col := range cols {
+		ns := df.Series[col]
+		if deepCopy {
+			ns = ns.Copy()
+		}
+		ndf.Series = append(ndf.Series, ns)
+		projected[col] = ns
+	}
+
+	// Preserve the order of the index
+	for _, col := range df.indexColumns() {
+		if ns, exists := projected[col]; exists {
+			ndf.index = append(ndf.index, ns)
+		}
+	}
+
+	return ndf
+}
@@ -23,6 +23,34 @@ type IsEqualOptions struct {
 
 	// Check if name is the same.
 	CheckName bool
+
+	// Tolerance is the maximum absolute difference between two numeric values (float64, int64 and complex128)
+	// for them to be considered equal.
+	Tolerance float64
+
+	// RelativeTolerance is the maximum difference between two numeric values, relative to the larger
+	// magnitude of the two values, for them to be considered equal (eg. 1e-9).
+	RelativeTolerance float64
+
+	// NaNNotEqual will treat nil (NaN) values as not equal to each other.
+	// By default, two nil values are considered equal.
+	NaNNotEqual bool
+
+	// IgnoreSeriesOrder will match the Series of two DataFrames by name instead of by position.
+	//
+	// NOTE: This option only applies to DataFrames.
+	IgnoreSeriesOrder bool
+
+	// IgnoreSeriesType allows Series of different types to be compared. Numeric values (float64, int64 and complex128)
+	// are compared by value. Other values are compared using ValueString.
+	IgnoreSeriesType bool
+
+	// IgnoreRowOrder will consider the values equal irrespective of the order of the rows.
+	// For DataFrames, the rows (not each Series independently) must match.
+	//
+	// NOTE: When used with a tolerance, the rows are matched after sorting. Values within the tolerance
+	// that sort in a different order may not be matched.
+	IgnoreRowOrder bool
 }
 
 // NilCountOptions sets various options for the NilCount function.
@@ -871,6 +871,12 @@ func (s *SeriesFloat64) IsEqual(ctx context.Context, s2 Series, opts ...IsEqualO
 		defer s.lock.RUnlock()
 	}
 
+	if len(opts) > 0 && opts[0].Relaxed() {
+		o := opts[0]
+		o.DontLock = true
+		return IsEqualSeries(ctx, s, s2, o)
+	}
+
 	// Check type
 	fs, ok := s2.(*SeriesFloat64)
 	if !ok {
@@ -84,6 +84,11 @@ func NewSeriesGeneric(name string, concreteType interface{}, init *SeriesInit, v
 	return s
 }
 
+// NewSeries creates a new initialized SeriesGeneric with the same concrete type.
+func (s *SeriesGeneric) NewSeries(name string, init *SeriesInit) Series {
+	return NewSeriesGeneric(name, s.concreteType, init)
+}
+
 // Name returns the series name.
 func (s *SeriesGeneric) Name(opts ...Options) string {
 	if len(opts) == 0 || !opts[0].DontLock {
@@ -688,6 +693,12 @@ func (s *SeriesGeneric) IsEqual(ctx context.Context, s2 Series, opts ...IsEqualO
 		defer s.lock.RUnlock()
 	}
 
+	if len(opts) > 0 && opts[0].Relaxed() {
+		o := opts[0]
+		o.DontLock = true
+		return IsEqualSeries(ctx, s, s2, o)
+	}
+
 	// Check type
 	gs, ok := s2.(*SeriesGeneric)
 	if !ok {
@@ -879,6 +879,12 @@ func (s *SeriesInt64) IsEqual(ctx context.Context, s2 Series, opts ...IsEqualOpt
 		defer s.lock.RUnlock()
 	}
 
+	if len(opts) > 0 && opts[0].Relaxed() {
+		o := opts[0]
+		o.DontLock = true
+		return IsEqualSeries(ctx, s, s2, o)
+	}
+
 	// Check type
 	is, ok := s2.(*SeriesInt64)
 	if !ok {
@@ -912,7 +918,7 @@ func (s *SeriesInt64) IsEqual(ctx context.Context, s2 Series, opts ...IsEqualOpt
 			}
 		}
 
-		if *v != *is.values[i] {
+		if is.values[i] == nil || *v != *is.values[i] {
 			return false, nil
 		}
 	}
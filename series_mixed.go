@@ -835,6 +835,12 @@ func (s *SeriesMixed) IsEqual(ctx context.Context, s2 Series, opts ...IsEqualOpt
 		defer s.lock.RUnlock()
 	}
 
+	if len(opts) > 0 && opts[0].Relaxed() {
+		o := opts[0]
+		o.DontLock = true
+		return IsEqualSeries(ctx, s, s2, o)
+	}
+
 	// Check type
 	ms, ok := s2.(*SeriesMixed)
 	if !ok {
@@ -882,6 +882,12 @@ func (s *SeriesString) IsEqual(ctx context.Context, s2 Series, opts ...IsEqualOp
 		defer s.lock.RUnlock()
 	}
 
+	if len(opts) > 0 && opts[0].Relaxed() {
+		o := opts[0]
+		o.DontLock = true
+		return IsEqualSeries(ctx, s, s2, o)
+	}
+
 	// Check type
 	ss, ok := s2.(*SeriesString)
 	if !ok {
@@ -889,7 +895,7 @@ func (s *SeriesString) IsEqual(ctx context.Context, s2 Series, opts ...IsEqualOp
 	}
 
 	// Check number of values
-	if len(s.values) != len(s.values) {
+	if len(s.values) != len(ss.values) {
 		return false, nil
 	}
 
@@ -915,7 +921,7 @@ func (s *SeriesString) IsEqual(ctx context.Context, s2 Series, opts ...IsEqualOp
 			}
 		}
 
-		if *v != *ss.values[i] {
+		if ss.values[i] == nil || *v != *ss.values[i] {
 			return false, nil
 		}
 	}
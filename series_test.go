@@ -535,6 +535,47 @@ func TestSeriesIsEqual(t *testing.T) {
 
 }
 
+func TestSeriesIsEqualOptions(t *testing.T) {
+	ctx := context.Background()
+
+	x := 0.1 // 0.1 + 0.2 != 0.3 when not evaluated as a constant
+
+	tests := []struct {
+		s1       Series
+		s2       Series
+		opts     IsEqualOptions
+		expected bool
+	}{
+		{NewSeriesFloat64("x", nil, x+0.2, 1.0), NewSeriesFloat64("x", nil, 0.3, 1.0), IsEqualOptions{}, false},
+		{NewSeriesFloat64("x", nil, x+0.2, 1.0), NewSeriesFloat64("x", nil, 0.3, 1.0), IsEqualOptions{Tolerance: 1e-9}, true},
+		{NewSeriesFloat64("x", nil, 1000.0), NewSeriesFloat64("x", nil, 1001.0), IsEqualOptions{RelativeTolerance: 1e-2}, true},
+		{NewSeriesFloat64("x", nil, 1000.0), NewSeriesFloat64("x", nil, 1001.0), IsEqualOptions{RelativeTolerance: 1e-4}, false},
+		{NewSeriesInt64("x", nil, 10, nil), NewSeriesInt64("x", nil, 11, nil), IsEqualOptions{Tolerance: 1}, true},
+		{NewSeriesFloat64("x", nil, 1.0, nil), NewSeriesFloat64("x", nil, 1.0, nil), IsEqualOptions{}, true},
+		{NewSeriesFloat64("x", nil, 1.0, nil), NewSeriesFloat64("x", nil, 1.0, nil), IsEqualOptions{NaNNotEqual: true}, false},
+		{NewSeriesInt64("x", nil, 1, 2), NewSeriesFloat64("x", nil, 1.0, 2.0), IsEqualOptions{}, false},
+		{NewSeriesInt64("x", nil, 1, 2), NewSeriesFloat64("x", nil, 1.0, 2.0), IsEqualOptions{IgnoreSeriesType: true}, true},
+		{NewSeriesInt64("x", nil, 1, 2), NewSeriesFloat64("x", nil, 1.0, 2.5), IsEqualOptions{IgnoreSeriesType: true}, false},
+		{NewSeriesInt64("x", nil, 1, 2), NewSeriesString("x", nil, "1", "2"), IsEqualOptions{IgnoreSeriesType: true}, true},
+		{NewSeriesString("x", nil, "a", nil, "b"), NewSeriesString("x", nil, "b", "a", nil), IsEqualOptions{IgnoreRowOrder: true}, true},
+		{NewSeriesString("x", nil, "a", "a", "b"), NewSeriesString("x", nil, "b", "a", "b"), IsEqualOptions{IgnoreRowOrder: true}, false},
+		{NewSeriesString("x", nil, "a", "b"), NewSeriesString("x", nil, "a", "b", "c"), IsEqualOptions{}, false},
+		{NewSeriesString("x", nil, "a", "b"), NewSeriesString("x", nil, "a", nil), IsEqualOptions{}, false},
+		{NewSeriesFloat64("x", nil, 3.0, 1.0), NewSeriesFloat64("y", nil, 1.0, 3.0), IsEqualOptions{IgnoreRowOrder: true, CheckName: true}, false},
+	}
+
+	for i, tc := range tests {
+		eq, err := tc.s1.IsEqual(ctx, tc.s2, tc.opts)
+		if err != nil {
+			t.Errorf("%d: error encountered: %s\n", i, err)
+		}
+
+		if eq != tc.expected {
+			t.Errorf("%d: expected: %v actual: %v\n", i, tc.expected, eq)
+		}
+	}
+}
+
 func TestStopAtOneNil(t *testing.T) {
 
 	tRef := time.Date(2017, 1, 1, 5, 30, 12, 0, time.UTC)
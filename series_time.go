@@ -860,6 +860,12 @@ func (s *SeriesTime) IsEqual(ctx context.Context, s2 Series, opts ...IsEqualOpti
 		defer s.lock.RUnlock()
 	}
 
+	if len(opts) > 0 && opts[0].Relaxed() {
+		o := opts[0]
+		o.DontLock = true
+		return IsEqualSeries(ctx, s, s2, o)
+	}
+
 	// Check type
 	ts, ok := s2.(*SeriesTime)
 	if !ok {
@@ -893,7 +899,7 @@ func (s *SeriesTime) IsEqual(ctx context.Context, s2 Series, opts ...IsEqualOpti
 			}
 		}
 
-		if !(*v).Equal(*ts.Values[i]) {
+		if ts.Values[i] == nil || !(*v).Equal(*ts.Values[i]) {
 			return false, nil
 		}
 	}
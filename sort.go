@@ -4,6 +4,7 @@ package dataframe
 
 import (
 	"context"
+	"fmt"
 	"sort"
 )
 
@@ -19,10 +20,16 @@ type SortKey struct {
 	// Key can be an int (position of series) or string (name of series).
 	Key interface{}
 
+	// Expr is an expression to sort by (eg. "price * qty"). It is used instead of Key.
+	//
+	// See: Expr for the expression language.
+	Expr string
+
 	// Desc can be set to sort in descending order.
 	Desc bool
 
 	seriesIndex int
+	series      Series // result of Expr
 }
 
 type sorter struct {
@@ -42,7 +49,10 @@ func (s *sorter) Less(i, j int) bool {
 	}
 
 	for _, key := range s.keys {
-		series := s.df.Series[key.seriesIndex]
+		series := key.series
+		if series == nil {
+			series = s.df.Series[key.seriesIndex]
+		}
 
 		left := series.Value(i)
 		right := series.Value(j)
@@ -64,6 +74,12 @@ func (s *sorter) Less(i, j int) bool {
 
 func (s *sorter) Swap(i, j int) {
 	s.df.Swap(i, j, DontLock)
+
+	for _, key := range s.keys {
+		if key.series != nil {
+			key.series.Swap(i, j, dontLock)
+		}
+	}
 }
 
 // SortOptions is used to configure the sort algorithm for a Dataframe or Series
@@ -84,9 +100,22 @@ type SortOptions struct {
 
 // Sort is used to sort the Dataframe according to different keys.
 // It will return true if sorting was completed or false when the context is canceled.
+//
+// It panics if a key refers to a Series that doesn't exist or if an Expr can't be compiled.
+// Use TrySort to receive an error instead.
 func (df *DataFrame) Sort(ctx context.Context, keys []SortKey, opts ...SortOptions) (completed bool) {
+	completed, err := df.TrySort(ctx, keys, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return completed
+}
+
+// TrySort is the same as Sort except that an error is returned if a key refers to a Series that
+// doesn't exist or if an Expr can't be compiled. The DataFrame is not modified when an error is returned.
+func (df *DataFrame) TrySort(ctx context.Context, keys []SortKey, opts ...SortOptions) (completed bool, err error) {
 	if len(keys) == 0 {
-		return true
+		return true, nil
 	}
 
 	defer func() {
@@ -110,6 +139,7 @@ func (df *DataFrame) Sort(ctx context.Context, keys []SortKey, opts ...SortOptio
 		for i := range keys {
 			key := &keys[i]
 			key.seriesIndex = 0
+			key.series = nil
 		}
 	}()
 
@@ -117,15 +147,35 @@ func (df *DataFrame) Sort(ctx context.Context, keys []SortKey, opts ...SortOptio
 	for i := range keys {
 		key := &keys[i]
 
-		name, ok := key.Key.(string)
-		if ok {
-			col, err := df.NameToColumn(name, dontLock)
+		if key.Expr != "" {
+			e, err := CompileExpr(df, key.Expr, dontLock)
+			if err != nil {
+				return false, err
+			}
+			key.series, err = e.evaluateSeries(ctx, df, "")
+			if err != nil {
+				if err == context.Canceled || err == context.DeadlineExceeded {
+					return false, nil
+				}
+				return false, err
+			}
+			continue
+		}
+
+		switch k := key.Key.(type) {
+		case string:
+			col, err := df.NameToColumn(k, dontLock)
 			if err != nil {
-				panic(err)
+				return false, err
 			}
 			key.seriesIndex = col
-		} else {
-			key.seriesIndex = key.Key.(int)
+		case int:
+			if k < 0 || k >= len(df.Series) {
+				return false, fmt.Errorf("series not found: %d", k)
+			}
+			key.seriesIndex = k
+		default:
+			return false, fmt.Errorf("invalid key: %v", key.Key)
 		}
 	}
 
@@ -142,5 +192,5 @@ func (df *DataFrame) Sort(ctx context.Context, keys []SortKey, opts ...SortOptio
 		sort.Stable(s)
 	}
 
-	return true
+	return true, nil
 }
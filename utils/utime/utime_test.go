@@ -2,8 +2,11 @@ package utime
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
 )
 
 func TestUtime(t *testing.T) {
@@ -60,3 +63,25 @@ func TestGuessTimeFreq(t *testing.T) {
 		}
 	}
 }
+
+func TestFreqRule(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2020, 2, 13, 0, 0, 0, 0, time.UTC)
+
+	ts, err := NewSeriesTime(ctx, "day", "1D", now, false, NewSeriesTimeOptions{Size: &[]int{5}[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.Update(3, now)
+
+	df := dataframe.NewDataFrame(ts)
+
+	report, err := dataframe.Validate(ctx, df, map[string][]dataframe.Rule{"day": {FreqRule("1D", ValidateSeriesTimeOptions{})}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Errors) != 1 || report.Errors[0].Row != 3 || !errors.Is(report.Errors[0], ErrValidationFailed) {
+		t.Errorf("wrong report: %v", report.Errors)
+	}
+}
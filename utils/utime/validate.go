@@ -147,3 +147,30 @@ func ValidateSeriesTime(ctx context.Context, ts *dataframe.SeriesTime, timeFreq
 
 	return nil
 }
+
+// FreqRule returns a dataframe.Rule that validates a SeriesTime using ValidateSeriesTime.
+// The Replace MissingValueOption is not supported since dataframe.Validate does not modify the values.
+func FreqRule(timeFreq string, opts ValidateSeriesTimeOptions) dataframe.Rule {
+	return dataframe.RuleFunc(func(ctx context.Context, s dataframe.Series) ([]*dataframe.RowError, error) {
+
+		ts, ok := s.(*dataframe.SeriesTime)
+		if !ok {
+			return nil, errors.New("FreqRule requires a SeriesTime")
+		}
+
+		if opts.MissingValue == Replace {
+			return nil, errors.New("Replace is not supported by FreqRule")
+		}
+
+		opts.DontLock = true // Already locked by dataframe.Validate
+
+		err := ValidateSeriesTime(ctx, ts, timeFreq, opts)
+		if err != nil {
+			if re, ok := err.(*dataframe.RowError); ok {
+				return []*dataframe.RowError{re}, nil
+			}
+			return nil, err
+		}
+		return nil, nil
+	})
+}
@@ -0,0 +1,391 @@
+// Copyright 2018-20 PJ Engineering and Business Solutions Pty. Ltd. All rights reserved.
+
+package dataframe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+)
+
+// Rule is used to validate the values of a Series.
+//
+// See: Validate
+type Rule interface {
+
+	// Check returns a RowError for each row of s that violates the rule.
+	// s is already locked by Validate, so it must be accessed using the DontLock option.
+	Check(ctx context.Context, s Series) ([]*RowError, error)
+}
+
+// RuleFunc is used to create a custom Rule.
+type RuleFunc func(ctx context.Context, s Series) ([]*RowError, error)
+
+// Check implements the Rule interface.
+func (f RuleFunc) Check(ctx context.Context, s Series) ([]*RowError, error) {
+	return f(ctx, s)
+}
+
+// ValidateOptions modifies the behaviour of the Validate function.
+type ValidateOptions struct {
+
+	// DropInvalid will remove the rows that violate a rule from the DataFrame.
+	DropInvalid bool
+
+	// DontLock can be set to true if the DataFrame should not be locked.
+	DontLock bool
+}
+
+// ValidationReport contains the violations found by Validate.
+type ValidationReport struct {
+
+	// Errors contains a RowError for each violation, ordered by row.
+	// The error of each RowError wraps the error of the Rule and contains the name of the Series.
+	Errors []*RowError
+}
+
+// IsValid returns true if no violations were found.
+func (r *ValidationReport) IsValid() bool {
+	return len(r.Errors) == 0
+}
+
+// Rows returns the rows that contain a violation.
+func (r *ValidationReport) Rows() []int {
+	rows := []int{}
+	for _, re := range r.Errors {
+		if len(rows) == 0 || rows[len(rows)-1] != re.Row {
+			rows = append(rows, re.Row)
+		}
+	}
+	return rows
+}
+
+// Validate checks the Series of a DataFrame against rules. The key of rules is the name of the Series.
+// The rows are reported using the row numbers prior to the DropInvalid option being applied.
+//
+// Example:
+//
+//  rules := map[string][]dataframe.Rule{
+//     "id":    {dataframe.NotNil, dataframe.Unique},
+//     "score": {dataframe.Between(0, 100)},
+//     "code":  {dataframe.Matches(`^[A-Z]{2}$`), dataframe.In("AU", "NZ")},
+//     "day":   {dataframe.Monotonic, utime.FreqRule("1D", utime.ValidateSeriesTimeOptions{})},
+//  }
+//
+//  report, err := dataframe.Validate(ctx, df, rules)
+//
+func Validate(ctx context.Context, df *DataFrame, rules map[string][]Rule, opts ...ValidateOptions) (*ValidationReport, error) {
+
+	if len(opts) == 0 {
+		opts = append(opts, ValidateOptions{})
+	}
+
+	if !opts[0].DontLock {
+		if opts[0].DropInvalid {
+			df.lock.Lock()
+			defer df.lock.Unlock()
+		} else {
+			df.lock.RLock()
+			defer df.lock.RUnlock()
+		}
+	}
+
+	for name := range rules {
+		if _, err := df.NameToColumn(name, dontLock); err != nil {
+			return nil, fmt.Errorf("series not found: %s", name)
+		}
+	}
+
+	report := &ValidationReport{Errors: []*RowError{}}
+
+	// The Series are checked in order so that the report is deterministic
+	for _, s := range df.Series {
+		name := s.Name(dontLock)
+
+		for _, rule := range rules[name] {
+			errs, err := rule.Check(ctx, s)
+			if err != nil {
+				return nil, fmt.Errorf("series %s: %w", name, err)
+			}
+
+			for _, re := range errs {
+				report.Errors = append(report.Errors, &RowError{Row: re.Row, Err: fmt.Errorf("series %s: %w", name, re.Err)})
+			}
+		}
+	}
+
+	sort.SliceStable(report.Errors, func(i, j int) bool {
+		return report.Errors[i].Row < report.Errors[j].Row
+	})
+
+	if opts[0].DropInvalid {
+		rows := report.Rows()
+		for idx := len(rows) - 1; idx >= 0; idx-- {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			df.Remove(rows[idx], dontLock)
+		}
+	}
+
+	return report, nil
+}
+
+// eachValue calls fn for each non-nil value of s.
+func eachValue(ctx context.Context, s Series, fn func(row int, val interface{}) error) ([]*RowError, error) {
+
+	errs := []*RowError{}
+
+	nRows := s.NRows(dontLock)
+	for row := 0; row < nRows; row++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		val := s.Value(row, dontLock)
+		if val == nil {
+			continue
+		}
+
+		if err := fn(row, val); err != nil {
+			errs = append(errs, &RowError{Row: row, Err: err})
+		}
+	}
+
+	return errs, nil
+}
+
+// ErrNilValue is reported by the NotNil Rule.
+var ErrNilValue = errors.New("nil value")
+
+// NotNil is a Rule that reports nil values.
+var NotNil Rule = RuleFunc(func(ctx context.Context, s Series) ([]*RowError, error) {
+
+	errs := []*RowError{}
+
+	nRows := s.NRows(dontLock)
+	for row := 0; row < nRows; row++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if s.Value(row, dontLock) == nil {
+			errs = append(errs, &RowError{Row: row, Err: ErrNilValue})
+		}
+	}
+
+	return errs, nil
+})
+
+// Unique is a Rule that reports values (as returned by ValueString) that have already appeared in a previous row.
+// nil values are ignored.
+var Unique Rule = RuleFunc(func(ctx context.Context, s Series) ([]*RowError, error) {
+
+	seen := map[string]int{}
+
+	return eachValue(ctx, s, func(row int, val interface{}) error {
+		str := s.ValueString(row, dontLock)
+		if prev, exists := seen[str]; exists {
+			return fmt.Errorf("value %s is not unique (see row %d)", str, prev)
+		}
+		seen[str] = row
+		return nil
+	})
+})
+
+// Monotonic is a Rule that reports values that are less than the previous value (i.e. the values must
+// be non-decreasing). The Series' IsLessThanFunc is used to compare values. nil values are ignored.
+var Monotonic Rule = RuleFunc(func(ctx context.Context, s Series) ([]*RowError, error) {
+
+	var (
+		prev    interface{}
+		prevRow int
+	)
+
+	return eachValue(ctx, s, func(row int, val interface{}) error {
+		defer func() { prev, prevRow = val, row }()
+
+		if prev != nil && s.IsLessThanFunc(val, prev) {
+			return fmt.Errorf("value %s is less than the previous value %s (see row %d)", s.ValueString(row, dontLock), s.ValueString(prevRow, dontLock), prevRow)
+		}
+		return nil
+	})
+})
+
+// Between returns a Rule that reports values that are not between min and max (inclusive).
+// It can only be used with a SeriesFloat64 or SeriesInt64. nil values are ignored.
+// Use math.Inf for a range that is only bounded on one side.
+func Between(min, max float64) Rule {
+	return RuleFunc(func(ctx context.Context, s Series) ([]*RowError, error) {
+
+		switch s.(type) {
+		case *SeriesFloat64, *SeriesInt64:
+		default:
+			return nil, errors.New("Between requires a SeriesFloat64 or SeriesInt64")
+		}
+
+		return eachValue(ctx, s, func(row int, val interface{}) error {
+			var v float64
+			switch n := val.(type) {
+			case float64:
+				v = n
+			case int64:
+				v = float64(n)
+			}
+
+			if v >= min && v <= max {
+				return nil
+			}
+
+			str := s.ValueString(row, dontLock)
+			switch {
+			case math.IsInf(max, 1):
+				return fmt.Errorf("value %s is less than %v", str, min)
+			case math.IsInf(min, -1):
+				return fmt.Errorf("value %s is greater than %v", str, max)
+			}
+			return fmt.Errorf("value %s is not between %v and %v", str, min, max)
+		})
+	})
+}
+
+// Matches returns a Rule that reports values (as returned by ValueString) that don't match the
+// regular expression pattern. nil values are ignored.
+//
+// An error is returned when the Rule is checked if pattern can't be compiled.
+func Matches(pattern string) Rule {
+
+	re, err := regexp.Compile(pattern)
+
+	return RuleFunc(func(ctx context.Context, s Series) ([]*RowError, error) {
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %v", err)
+		}
+
+		return eachValue(ctx, s, func(row int, val interface{}) error {
+			str := s.ValueString(row, dontLock)
+			if !re.MatchString(str) {
+				return fmt.Errorf("value %s does not match pattern %s", str, pattern)
+			}
+			return nil
+		})
+	})
+}
+
+// Length returns a Rule that reports values (as returned by ValueString) that contain fewer than min
+// or more than max characters. A negative max means there is no maximum. nil values are ignored.
+func Length(min, max int) Rule {
+	return RuleFunc(func(ctx context.Context, s Series) ([]*RowError, error) {
+		return eachValue(ctx, s, func(row int, val interface{}) error {
+			str := s.ValueString(row, dontLock)
+			n := utf8.RuneCountInString(str)
+			if n < min {
+				return fmt.Errorf("value %s is shorter than %d characters", str, min)
+			}
+			if max >= 0 && n > max {
+				return fmt.Errorf("value %s is longer than %d characters", str, max)
+			}
+			return nil
+		})
+	})
+}
+
+// OneOf returns a Rule that reports values (as returned by ValueString) that are not found in vals.
+// Unlike In, the values are compared as strings. nil values are ignored.
+func OneOf(vals ...string) Rule {
+
+	set := map[string]struct{}{}
+	for _, v := range vals {
+		set[v] = struct{}{}
+	}
+
+	return RuleFunc(func(ctx context.Context, s Series) ([]*RowError, error) {
+		return eachValue(ctx, s, func(row int, val interface{}) error {
+			str := s.ValueString(row, dontLock)
+			if _, exists := set[str]; !exists {
+				return fmt.Errorf("value %s is not allowed", str)
+			}
+			return nil
+		})
+	})
+}
+
+// In returns a Rule that reports values that are not found in vals. It can be used to check
+// referential integrity. Integers are treated as int64 and float32 as float64. nil values are ignored.
+//
+// Example:
+//
+//  // Every customer_id must exist in the customers DataFrame
+//  ids := []interface{}{}
+//  iterator := customers.Series[0].ValuesIterator()
+//  for {
+//     row, val, _ := iterator()
+//     if row == nil {
+//        break
+//     }
+//     ids = append(ids, val)
+//  }
+//
+//  rules := map[string][]dataframe.Rule{"customer_id": {dataframe.In(ids...)}}
+//
+func In(vals ...interface{}) Rule {
+
+	set := map[interface{}]struct{}{}
+	others := []interface{}{} // values that can't be used as a map key
+
+	for _, v := range vals {
+		v = normalizeValue(v)
+		if v != nil && !reflect.TypeOf(v).Comparable() {
+			others = append(others, v)
+			continue
+		}
+		set[v] = struct{}{}
+	}
+
+	return RuleFunc(func(ctx context.Context, s Series) ([]*RowError, error) {
+		return eachValue(ctx, s, func(row int, val interface{}) error {
+			if reflect.TypeOf(val).Comparable() {
+				if _, exists := set[val]; exists {
+					return nil
+				}
+			} else {
+				for _, v := range others {
+					if DefaultIsEqualFunc(val, v) {
+						return nil
+					}
+				}
+			}
+			return fmt.Errorf("value %s is not allowed", s.ValueString(row, dontLock))
+		})
+	})
+}
+
+// normalizeValue converts integers to int64 and float32 to float64 to match the values of the built-in Series.
+func normalizeValue(v interface{}) interface{} {
+	switch n := v.(type) {
+	case int:
+		return int64(n)
+	case int8:
+		return int64(n)
+	case int16:
+		return int64(n)
+	case int32:
+		return int64(n)
+	case uint:
+		return int64(n)
+	case uint8:
+		return int64(n)
+	case uint16:
+		return int64(n)
+	case uint32:
+		return int64(n)
+	case float32:
+		return float64(n)
+	}
+	return v
+}
@@ -18,6 +18,11 @@ import (
 	dataframe "github.com/rocketlaunchr/dataframe-go"
 )
 
+func init() {
+	// Allow SeriesComplex128 to be used by a dataframe.Schema
+	dataframe.RegisterSeriesType("complex128", &SeriesComplex128{})
+}
+
 // SeriesComplex128 is used for series containing complex128 data.
 type SeriesComplex128 struct {
 	valFormatter dataframe.ValueToStringFormatter
@@ -1036,6 +1041,12 @@ func (s *SeriesComplex128) IsEqual(ctx context.Context, s2 dataframe.Series, opt
 		defer s.lock.RUnlock()
 	}
 
+	if len(opts) > 0 && opts[0].Relaxed() {
+		o := opts[0]
+		o.DontLock = true
+		return dataframe.IsEqualSeries(ctx, s, s2, o)
+	}
+
 	// Check type
 	cs, ok := s2.(*SeriesComplex128)
 	if !ok {